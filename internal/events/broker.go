@@ -0,0 +1,201 @@
+package events
+
+import (
+	"strconv"
+	"sync"
+)
+
+const (
+	TypePaymentAccepted     = "payment.accepted"
+	TypePaymentProcessed    = "payment.processed"
+	TypePaymentFailed       = "payment.failed"
+	TypePaymentDeadLettered = "payment.dead-lettered"
+	TypeSummaryTick         = "summary.tick"
+)
+
+// Event é um evento já serializado; Data é o JSON enviado no campo data do
+// SSE. Processor, CorrelationId e TenantId só servem para os filtros.
+type Event struct {
+	Id            uint64
+	Type          string
+	Processor     string
+	CorrelationId string
+	TenantId      string
+	Data          []byte
+}
+
+// AppendSSE codifica o evento no formato text/event-stream. Eventos sem id
+// (summary.tick) não mudam o Last-Event-ID do cliente.
+func (e *Event) AppendSSE(dst []byte) []byte {
+	if e.Id != 0 {
+		dst = append(dst, "id: "...)
+		dst = strconv.AppendUint(dst, e.Id, 10)
+		dst = append(dst, '\n')
+	}
+	dst = append(dst, "event: "...)
+	dst = append(dst, e.Type...)
+	dst = append(dst, "\ndata: "...)
+	dst = append(dst, e.Data...)
+	return append(dst, "\n\n"...)
+}
+
+// Filter seleciona os eventos de uma assinatura; campos vazios não filtram.
+// Eventos sem tenant (summary.tick) somam todos os tenants e por isso só
+// chegam a assinaturas sem TenantId.
+type Filter struct {
+	Types         map[string]bool
+	Processor     string
+	CorrelationId string
+	TenantId      string
+}
+
+func (f *Filter) Match(e *Event) bool {
+	if len(f.Types) > 0 && !f.Types[e.Type] {
+		return false
+	}
+	if f.Processor != "" && e.Processor != f.Processor {
+		return false
+	}
+	if f.CorrelationId != "" && e.CorrelationId != f.CorrelationId {
+		return false
+	}
+	return f.TenantId == "" || e.TenantId == f.TenantId
+}
+
+// Subscription recebe os eventos em C. Se o assinante não acompanhar e C
+// encher, o Broker o descarta e fecha C; o cliente volta com Last-Event-ID.
+type Subscription struct {
+	C      <-chan Event
+	c      chan Event
+	filter Filter
+}
+
+// Broker distribui os eventos para as assinaturas e guarda os últimos em um
+// buffer circular para retomada. Os ids vêm de payment_events, os mesmos em
+// todas as instâncias, então um Last-Event-ID vale em qualquer uma delas.
+type Broker struct {
+	mu     sync.Mutex
+	log    []Event
+	start  int
+	size   int
+	lastId uint64
+	subs   map[*Subscription]struct{}
+	buffer int
+}
+
+// NewBroker devolve nil com size zero; Publish e Subscribers aceitam um
+// Broker nil.
+func NewBroker(size, subscriberBuffer int) *Broker {
+	if size <= 0 {
+		return nil
+	}
+
+	return &Broker{
+		log:    make([]Event, size),
+		subs:   map[*Subscription]struct{}{},
+		buffer: max(subscriberBuffer, 1),
+	}
+}
+
+// Publish entrega e guarda no log um evento já gravado, em ordem de id; um id
+// já publicado é ignorado. Assume a posse de Data.
+func (b *Broker) Publish(e Event) {
+	if b == nil || e.Type == "" {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if e.Id <= b.lastId {
+		return
+	}
+	b.lastId = e.Id
+
+	if b.size < len(b.log) {
+		b.log[(b.start+b.size)%len(b.log)] = e
+		b.size++
+	} else {
+		b.log[b.start] = e
+		b.start = (b.start + 1) % len(b.log)
+	}
+
+	b.deliver(&e)
+}
+
+// Broadcast entrega um evento sem id e sem guardá-lo no log, para os que só
+// valem na hora, como o summary.tick de cada instância.
+func (b *Broker) Broadcast(e Event) {
+	if b == nil || e.Type == "" {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	e.Id = 0
+	b.deliver(&e)
+}
+
+func (b *Broker) deliver(e *Event) {
+	for sub := range b.subs {
+		if !sub.filter.Match(e) {
+			continue
+		}
+		select {
+		case sub.c <- *e:
+		default:
+			b.drop(sub)
+		}
+	}
+}
+
+// Subscribe registra a assinatura e devolve, do log, os eventos depois de
+// lastId que passam pelo filtro (nenhum sem resume). Quando parte dos eventos
+// depois de lastId já saiu do log, gap devolve o
+// id do primeiro evento do log, antes do qual eles estão.
+func (b *Broker) Subscribe(filter Filter, lastId uint64, resume bool) (sub *Subscription, backlog []Event, gap uint64) {
+	c := make(chan Event, b.buffer)
+	sub = &Subscription{C: c, c: c, filter: filter}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if resume && b.size > 0 {
+		if oldest := b.log[b.start].Id; lastId+1 < oldest {
+			gap = oldest
+		}
+		for i := 0; i < b.size; i++ {
+			e := &b.log[(b.start+i)%len(b.log)]
+			if e.Id > lastId && filter.Match(e) {
+				backlog = append(backlog, *e)
+			}
+		}
+	}
+
+	b.subs[sub] = struct{}{}
+	return sub, backlog, gap
+}
+
+func (b *Broker) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.drop(sub)
+}
+
+func (b *Broker) drop(sub *Subscription) {
+	if _, ok := b.subs[sub]; ok {
+		delete(b.subs, sub)
+		close(sub.c)
+	}
+}
+
+func (b *Broker) Subscribers() int {
+	if b == nil {
+		return 0
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subs)
+}
@@ -0,0 +1,251 @@
+package migrations
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/storage"
+	"github.com/jackc/pgx/v5"
+)
+
+//go:embed sql/*.sql
+var files embed.FS
+
+var ErrInvalidMigration = errors.New("migração inválida")
+
+// Migration é o par sql/NNNN_nome.up.sql e sql/NNNN_nome.down.sql embutido no
+// binário.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status é uma migração conhecida pelo binário; AppliedAt é nil se ela ainda
+// não foi aplicada.
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+const createTable = `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INT PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TIMESTAMP NOT NULL DEFAULT NOW()
+	)
+`
+
+// Load lê as migrações embutidas, ordenadas pela versão.
+func Load() ([]Migration, error) {
+	return load(files)
+}
+
+func load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, "sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		name := entry.Name()
+		base, direction, ok := strings.Cut(strings.TrimSuffix(name, ".sql"), ".")
+		if !ok || (direction != "up" && direction != "down") {
+			return nil, fmt.Errorf("%w: %s não segue NNNN_nome.up.sql/NNNN_nome.down.sql", ErrInvalidMigration, name)
+		}
+
+		rawVersion, label, _ := strings.Cut(base, "_")
+		version, err := strconv.Atoi(rawVersion)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("%w: versão inválida em %s", ErrInvalidMigration, name)
+		}
+
+		content, err := fs.ReadFile(fsys, path.Join("sql", name))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: label}
+			byVersion[version] = m
+		} else if m.Name != label {
+			return nil, fmt.Errorf("%w: versão %d usada por %s e %s", ErrInvalidMigration, version, m.Name, label)
+		}
+
+		if direction == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("%w: %04d_%s precisa de up e down", ErrInvalidMigration, m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// Up aplica, em ordem, as migrações pendentes. Tudo roda numa transação que
+// segura um advisory lock: quando as duas instâncias sobem juntas, a segunda
+// espera a primeira terminar e encontra o schema já atualizado. Com erro,
+// nenhuma migração fica aplicada.
+func Up(ctx context.Context, pg storage.PostgresClient) ([]Migration, error) {
+	migrations, err := Load()
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	err = locked(ctx, pg, func(tx pgx.Tx, applied map[int]time.Time) error {
+		for _, m := range migrations {
+			if _, ok := applied[m.Version]; ok {
+				continue
+			}
+			if _, err := tx.Exec(ctx, m.Up); err != nil {
+				return fmt.Errorf("migração %04d_%s: %w", m.Version, m.Name, err)
+			}
+			if _, err := tx.Exec(ctx, `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`, m.Version, m.Name); err != nil {
+				return err
+			}
+			done = append(done, m)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range done {
+		log.Printf("[Migrations] aplicada %04d_%s", m.Version, m.Name)
+	}
+	return done, nil
+}
+
+// Down desfaz as últimas steps migrações aplicadas, da mais nova para a mais
+// velha, na mesma transação com lock de Up.
+func Down(ctx context.Context, pg storage.PostgresClient, steps int) ([]Migration, error) {
+	migrations, err := Load()
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[int]Migration, len(migrations))
+	for _, m := range migrations {
+		known[m.Version] = m
+	}
+
+	var done []Migration
+	err = locked(ctx, pg, func(tx pgx.Tx, applied map[int]time.Time) error {
+		versions := make([]int, 0, len(applied))
+		for version := range applied {
+			versions = append(versions, version)
+		}
+		sort.Sort(sort.Reverse(sort.IntSlice(versions)))
+
+		for _, version := range versions[:min(steps, len(versions))] {
+			m, ok := known[version]
+			if !ok {
+				return fmt.Errorf("%w: versão %d aplicada não existe neste binário", ErrInvalidMigration, version)
+			}
+			if _, err := tx.Exec(ctx, m.Down); err != nil {
+				return fmt.Errorf("migração %04d_%s: %w", m.Version, m.Name, err)
+			}
+			if _, err := tx.Exec(ctx, `DELETE FROM schema_migrations WHERE version = $1`, m.Version); err != nil {
+				return err
+			}
+			done = append(done, m)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range done {
+		log.Printf("[Migrations] desfeita %04d_%s", m.Version, m.Name)
+	}
+	return done, nil
+}
+
+// List devolve as migrações do binário com o instante em que foram aplicadas.
+func List(ctx context.Context, pg storage.PostgresClient) ([]Status, error) {
+	migrations, err := Load()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(migrations))
+	err = locked(ctx, pg, func(tx pgx.Tx, applied map[int]time.Time) error {
+		for _, m := range migrations {
+			status := Status{Migration: m}
+			if appliedAt, ok := applied[m.Version]; ok {
+				status.AppliedAt = &appliedAt
+			}
+			statuses = append(statuses, status)
+		}
+		return nil
+	})
+
+	return statuses, err
+}
+
+// locked roda fn numa transação com pg_advisory_xact_lock, que é liberado no
+// commit ou no rollback, mesmo se a conexão cair.
+func locked(ctx context.Context, pg storage.PostgresClient, fn func(tx pgx.Tx, applied map[int]time.Time) error) error {
+	tx, err := pg.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("erro ao abrir transação de migração: %w", err)
+	}
+	defer tx.Rollback(context.WithoutCancel(ctx))
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('schema_migrations'))`); err != nil {
+		return fmt.Errorf("erro ao obter lock de migração: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, createTable); err != nil {
+		return err
+	}
+
+	rows, err := tx.Query(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return err
+	}
+	applied := map[int]time.Time{}
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			rows.Close()
+			return err
+		}
+		applied[version] = appliedAt
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if err := fn(tx, applied); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
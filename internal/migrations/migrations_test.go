@@ -0,0 +1,99 @@
+package migrations
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadEmbedded(t *testing.T) {
+	migrations, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("nenhuma migração embutida")
+	}
+
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			t.Fatalf("migração %d tem versão %d; as versões devem ser contínuas a partir de 1", i, m.Version)
+		}
+		if strings.TrimSpace(m.Up) == "" || strings.TrimSpace(m.Down) == "" {
+			t.Fatalf("%04d_%s com up ou down vazio", m.Version, m.Name)
+		}
+	}
+
+	if migrations[0].Name != "initial" {
+		t.Fatalf("primeira migração = %q, esperado initial", migrations[0].Name)
+	}
+}
+
+func TestLoadOrdersByVersion(t *testing.T) {
+	fsys := fstest.MapFS{
+		"sql/10_dez.up.sql":      {Data: []byte("SELECT 10;")},
+		"sql/10_dez.down.sql":    {Data: []byte("SELECT -10;")},
+		"sql/2_dois.up.sql":      {Data: []byte("SELECT 2;")},
+		"sql/2_dois.down.sql":    {Data: []byte("SELECT -2;")},
+		"sql/0001_um.down.sql":   {Data: []byte("SELECT -1;")},
+		"sql/0001_um.up.sql":     {Data: []byte("SELECT 1;")},
+		"sql/0003_tres.up.sql":   {Data: []byte("SELECT 3;")},
+		"sql/0003_tres.down.sql": {Data: []byte("SELECT -3;")},
+	}
+
+	migrations, err := load(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Migration{
+		{Version: 1, Name: "um", Up: "SELECT 1;", Down: "SELECT -1;"},
+		{Version: 2, Name: "dois", Up: "SELECT 2;", Down: "SELECT -2;"},
+		{Version: 3, Name: "tres", Up: "SELECT 3;", Down: "SELECT -3;"},
+		{Version: 10, Name: "dez", Up: "SELECT 10;", Down: "SELECT -10;"},
+	}
+	if len(migrations) != len(want) {
+		t.Fatalf("%d migrações, esperado %d", len(migrations), len(want))
+	}
+	for i := range want {
+		if migrations[i] != want[i] {
+			t.Fatalf("migração %d = %+v, esperado %+v", i, migrations[i], want[i])
+		}
+	}
+}
+
+func TestLoadRejectsInvalidFiles(t *testing.T) {
+	for name, fsys := range map[string]fstest.MapFS{
+		"sem down": {
+			"sql/0001_um.up.sql": {Data: []byte("SELECT 1;")},
+		},
+		"sem up": {
+			"sql/0001_um.down.sql": {Data: []byte("SELECT 1;")},
+		},
+		"direção desconhecida": {
+			"sql/0001_um.sideways.sql": {Data: []byte("SELECT 1;")},
+		},
+		"sem direção": {
+			"sql/0001_um.sql": {Data: []byte("SELECT 1;")},
+		},
+		"versão não numérica": {
+			"sql/um_um.up.sql":   {Data: []byte("SELECT 1;")},
+			"sql/um_um.down.sql": {Data: []byte("SELECT 1;")},
+		},
+		"versão zero": {
+			"sql/0000_zero.up.sql":   {Data: []byte("SELECT 1;")},
+			"sql/0000_zero.down.sql": {Data: []byte("SELECT 1;")},
+		},
+		"versão repetida": {
+			"sql/0001_um.up.sql":      {Data: []byte("SELECT 1;")},
+			"sql/0001_um.down.sql":    {Data: []byte("SELECT 1;")},
+			"sql/0001_outro.up.sql":   {Data: []byte("SELECT 1;")},
+			"sql/0001_outro.down.sql": {Data: []byte("SELECT 1;")},
+		},
+	} {
+		if _, err := load(fsys); !errors.Is(err, ErrInvalidMigration) {
+			t.Errorf("%s: err = %v, esperado ErrInvalidMigration", name, err)
+		}
+	}
+}
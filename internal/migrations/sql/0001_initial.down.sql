@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS
+	currency_rates,
+	scheduled_payments,
+	payment_batches,
+	webhook_deliveries,
+	refund_history,
+	api_keys,
+	dead_letter,
+	reconciliation_report,
+	entry_history;
@@ -0,0 +1,158 @@
+-- Estado do antigo payment.sql. Tabelas e colunas são IF NOT EXISTS e o
+-- fallback BOOLEAN da primeira versão é convertido, para que um banco criado
+-- por qualquer versão dele seja adotado sem perder dados.
+
+CREATE UNLOGGED TABLE IF NOT EXISTS entry_history (
+	correlationId UUID PRIMARY KEY,
+	amount DECIMAL NOT NULL,
+	processor TEXT NOT NULL,
+	routing_strategy TEXT NOT NULL DEFAULT 'priority',
+	expected_cost DECIMAL,
+	tenant_id TEXT NOT NULL DEFAULT '',
+	refunded_amount DECIMAL NOT NULL DEFAULT 0,
+	created_at TIMESTAMP NOT NULL,
+	currency TEXT NOT NULL DEFAULT '',
+	processor_currency TEXT NOT NULL DEFAULT '',
+	rate DECIMAL NOT NULL DEFAULT 1,
+	base_rate DECIMAL NOT NULL DEFAULT 1
+);
+
+-- O payment.sql original guardava o processador em fallback BOOLEAN NOT NULL;
+-- ao trocá-lo por processor, o histórico é convertido e a coluna antiga sai
+-- (com ela, qualquer índice que a usasse).
+ALTER TABLE entry_history ADD COLUMN IF NOT EXISTS processor TEXT;
+
+DO $$
+BEGIN
+	IF EXISTS (
+		SELECT 1 FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = 'entry_history' AND column_name = 'fallback'
+	) THEN
+		UPDATE entry_history
+		SET processor = CASE WHEN fallback THEN 'fallback' ELSE 'default' END
+		WHERE processor IS NULL;
+		ALTER TABLE entry_history DROP COLUMN fallback;
+	END IF;
+END $$;
+
+ALTER TABLE entry_history ALTER COLUMN processor SET NOT NULL;
+
+-- Colunas que o payment.sql ganhou depois da criação da tabela.
+ALTER TABLE entry_history
+	ADD COLUMN IF NOT EXISTS routing_strategy TEXT NOT NULL DEFAULT 'priority',
+	ADD COLUMN IF NOT EXISTS expected_cost DECIMAL,
+	ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT '',
+	ADD COLUMN IF NOT EXISTS refunded_amount DECIMAL NOT NULL DEFAULT 0,
+	ADD COLUMN IF NOT EXISTS currency TEXT NOT NULL DEFAULT '',
+	ADD COLUMN IF NOT EXISTS processor_currency TEXT NOT NULL DEFAULT '',
+	ADD COLUMN IF NOT EXISTS rate DECIMAL NOT NULL DEFAULT 1,
+	ADD COLUMN IF NOT EXISTS base_rate DECIMAL NOT NULL DEFAULT 1;
+
+-- A primeira versão criava _created_at_ só com created_at; o IF NOT EXISTS
+-- manteria o índice antigo, que não serve à paginação por cursor.
+DROP INDEX IF EXISTS _created_at_;
+CREATE INDEX _created_at_ ON entry_history (created_at, correlationId);
+CREATE INDEX IF NOT EXISTS _tenant_created_at_ ON entry_history (tenant_id, created_at);
+
+CREATE TABLE IF NOT EXISTS reconciliation_report (
+	id BIGSERIAL PRIMARY KEY,
+	checked_at TIMESTAMP NOT NULL,
+	processor TEXT NOT NULL,
+	kind TEXT NOT NULL,
+	window_from TIMESTAMP NOT NULL,
+	window_to TIMESTAMP NOT NULL,
+	correlationId UUID,
+	local_requests INT,
+	remote_requests INT,
+	local_amount DECIMAL,
+	remote_amount DECIMAL,
+	detail TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS _reconciliation_checked_at_ ON reconciliation_report (checked_at);
+
+CREATE TABLE IF NOT EXISTS dead_letter (
+	id BIGSERIAL PRIMARY KEY,
+	correlationId TEXT,
+	amount DECIMAL,
+	processor TEXT,
+	reason TEXT NOT NULL,
+	payload TEXT NOT NULL,
+	tenant_id TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMP NOT NULL
+);
+
+ALTER TABLE dead_letter ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS _dead_letter_correlation_ ON dead_letter (correlationId);
+
+CREATE TABLE IF NOT EXISTS api_keys (
+	key_hash TEXT PRIMARY KEY,
+	tenant_id TEXT NOT NULL,
+	rate_per_second DOUBLE PRECISION NOT NULL DEFAULT 0,
+	burst INT NOT NULL DEFAULT 1,
+	daily_quota BIGINT NOT NULL DEFAULT 0,
+	active BOOLEAN NOT NULL DEFAULT TRUE,
+	created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS refund_history (
+	refund_id UUID PRIMARY KEY,
+	correlationId UUID NOT NULL,
+	amount DECIMAL NOT NULL,
+	processor TEXT NOT NULL DEFAULT '',
+	status TEXT NOT NULL,
+	reason TEXT NOT NULL DEFAULT '',
+	tenant_id TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMP NOT NULL,
+	processed_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS _refund_correlation_ ON refund_history (correlationId);
+CREATE INDEX IF NOT EXISTS _refund_processed_at_ ON refund_history (processed_at);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+	id BIGSERIAL PRIMARY KEY,
+	event_id UUID NOT NULL,
+	event TEXT NOT NULL,
+	correlationId UUID NOT NULL,
+	url TEXT NOT NULL,
+	payload TEXT NOT NULL,
+	status TEXT NOT NULL,
+	attempts INT NOT NULL DEFAULT 0,
+	last_status_code INT NOT NULL DEFAULT 0,
+	last_error TEXT NOT NULL DEFAULT '',
+	tenant_id TEXT NOT NULL DEFAULT '',
+	next_attempt_at TIMESTAMP NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	delivered_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS _webhook_due_ ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS _webhook_correlation_ ON webhook_deliveries (correlationId);
+
+CREATE TABLE IF NOT EXISTS payment_batches (
+	batch_id UUID PRIMARY KEY,
+	tenant_id TEXT NOT NULL DEFAULT '',
+	correlation_ids UUID[] NOT NULL,
+	created_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS scheduled_payments (
+	correlationId UUID PRIMARY KEY,
+	amount DECIMAL NOT NULL,
+	tenant_id TEXT NOT NULL DEFAULT '',
+	scheduled_at TIMESTAMP NOT NULL,
+	payload BYTEA NOT NULL,
+	status TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS _scheduled_due_ ON scheduled_payments (scheduled_at) WHERE status = 'scheduled';
+
+CREATE TABLE IF NOT EXISTS currency_rates (
+	currency TEXT PRIMARY KEY,
+	rate DECIMAL NOT NULL,
+	updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
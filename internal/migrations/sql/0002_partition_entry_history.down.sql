@@ -0,0 +1,14 @@
+-- Junta todas as partições numa tabela comum. Partições já desanexadas pela
+-- retenção não voltam.
+CREATE UNLOGGED TABLE entry_history_flat (LIKE entry_history INCLUDING DEFAULTS);
+
+INSERT INTO entry_history_flat SELECT * FROM entry_history;
+
+DROP TABLE entry_history;
+DROP TABLE entry_history_ids;
+
+ALTER TABLE entry_history_flat RENAME TO entry_history;
+ALTER TABLE entry_history ADD PRIMARY KEY (correlationId);
+
+CREATE INDEX _created_at_ ON entry_history (created_at, correlationId);
+CREATE INDEX _tenant_created_at_ ON entry_history (tenant_id, created_at);
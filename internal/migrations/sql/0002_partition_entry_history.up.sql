@@ -0,0 +1,46 @@
+-- entry_history passa a ser particionada por created_at. A tabela antiga vira
+-- a partição entry_history_legacy, que cobre tudo até o fim do dia (UTC) da
+-- migração; as partições seguintes são criadas pelo serviço
+-- (PARTITION_INTERVAL) e o que cair fora delas vai para entry_history_default.
+ALTER TABLE entry_history RENAME TO entry_history_legacy;
+ALTER INDEX _created_at_ RENAME TO _legacy_created_at_;
+ALTER INDEX _tenant_created_at_ RENAME TO _legacy_tenant_created_at_;
+
+-- A chave de uma tabela particionada precisa conter created_at, então a
+-- unicidade do correlationId passa para entry_history_ids.
+ALTER TABLE entry_history_legacy DROP CONSTRAINT IF EXISTS entry_history_pkey;
+ALTER TABLE entry_history_legacy ADD CONSTRAINT entry_history_legacy_pkey PRIMARY KEY (correlationId, created_at);
+
+CREATE UNLOGGED TABLE entry_history_ids (
+	correlationId UUID PRIMARY KEY,
+	created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX _entry_ids_created_at_ ON entry_history_ids (created_at);
+
+INSERT INTO entry_history_ids (correlationId, created_at)
+SELECT correlationId, created_at FROM entry_history_legacy;
+
+CREATE TABLE entry_history (
+	correlationId UUID NOT NULL,
+	amount DECIMAL NOT NULL,
+	processor TEXT NOT NULL,
+	routing_strategy TEXT NOT NULL DEFAULT 'priority',
+	expected_cost DECIMAL,
+	tenant_id TEXT NOT NULL DEFAULT '',
+	refunded_amount DECIMAL NOT NULL DEFAULT 0,
+	created_at TIMESTAMP NOT NULL,
+	currency TEXT NOT NULL DEFAULT '',
+	processor_currency TEXT NOT NULL DEFAULT '',
+	rate DECIMAL NOT NULL DEFAULT 1,
+	base_rate DECIMAL NOT NULL DEFAULT 1,
+	PRIMARY KEY (correlationId, created_at)
+) PARTITION BY RANGE (created_at);
+
+CREATE INDEX _created_at_ ON entry_history (created_at, correlationId);
+CREATE INDEX _tenant_created_at_ ON entry_history (tenant_id, created_at);
+
+ALTER TABLE entry_history ATTACH PARTITION entry_history_legacy
+	FOR VALUES FROM (MINVALUE) TO (date_trunc('day', now() AT TIME ZONE 'UTC') + interval '1 day');
+
+CREATE UNLOGGED TABLE entry_history_default PARTITION OF entry_history DEFAULT;
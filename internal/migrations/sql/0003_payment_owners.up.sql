@@ -0,0 +1,14 @@
+-- O processador deduplica pelo correlationId sem saber de tenants, então um
+-- correlationId pertence ao primeiro tenant que o usar. Os já gravados ficam
+-- com o tenant da linha em entry_history.
+CREATE UNLOGGED TABLE payment_owners (
+	correlationId UUID PRIMARY KEY,
+	tenant_id TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX _payment_owners_created_at_ ON payment_owners (created_at);
+
+INSERT INTO payment_owners (correlationId, tenant_id, created_at)
+SELECT correlationId, tenant_id, created_at FROM entry_history
+ON CONFLICT (correlationId) DO NOTHING;
@@ -0,0 +1,3 @@
+DROP TRIGGER payment_events_notify ON payment_events;
+DROP FUNCTION notify_payment_events();
+DROP TABLE payment_events;
@@ -0,0 +1,24 @@
+-- Log dos eventos do GET /events, compartilhado pelas instâncias: o id é o
+-- Last-Event-ID, o mesmo em qualquer instância, e cada INSERT avisa as
+-- instâncias por NOTIFY para que leiam os eventos novos.
+CREATE UNLOGGED TABLE payment_events (
+	id BIGSERIAL PRIMARY KEY,
+	type TEXT NOT NULL,
+	processor TEXT NOT NULL DEFAULT '',
+	correlationId TEXT NOT NULL DEFAULT '',
+	tenant_id TEXT NOT NULL DEFAULT '',
+	data TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX _payment_events_created_at_ ON payment_events (created_at);
+
+CREATE FUNCTION notify_payment_events() RETURNS trigger AS $$
+BEGIN
+	PERFORM pg_notify('payment_events', '');
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER payment_events_notify AFTER INSERT ON payment_events
+FOR EACH STATEMENT EXECUTE FUNCTION notify_payment_events();
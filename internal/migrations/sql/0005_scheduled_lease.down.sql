@@ -0,0 +1,2 @@
+UPDATE scheduled_payments SET status = 'released' WHERE status = 'completed';
+DROP INDEX _scheduled_released_;
@@ -0,0 +1,3 @@
+-- O ClaimDue relibera os agendamentos liberados há mais de SCHEDULER_LEASE
+-- sem resultado; os que já têm resultado passam a completed e saem do índice.
+CREATE INDEX _scheduled_released_ ON scheduled_payments (updated_at) WHERE status = 'released';
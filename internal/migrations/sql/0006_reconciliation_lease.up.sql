@@ -0,0 +1,8 @@
+-- Só uma instância reconcilia por vez. A reconciliação chama os processadores,
+-- então a vez é um lease com prazo em vez de um lock preso a uma transação
+-- aberta durante as chamadas; um lease vencido pode ser tomado por outra.
+CREATE TABLE reconciliation_lease (
+	name TEXT PRIMARY KEY,
+	owner TEXT NOT NULL,
+	expires_at TIMESTAMP NOT NULL
+);
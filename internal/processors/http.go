@@ -0,0 +1,282 @@
+package processors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/config"
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/models"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fastjson"
+)
+
+type HTTPProcessor struct {
+	cfg             config.Processor
+	client          *fasthttp.HostClient
+	failing         atomic.Bool
+	minResponseTime atomic.Int64
+}
+
+func NewHTTPProcessor(cfg config.Processor) *HTTPProcessor {
+	return &HTTPProcessor{
+		cfg: cfg,
+		client: &fasthttp.HostClient{
+			Addr:     cfg.Addr,
+			MaxConns: 2048,
+		},
+	}
+}
+
+func (h *HTTPProcessor) Name() string {
+	return h.cfg.Name
+}
+
+func (h *HTTPProcessor) Fee() float64 {
+	return h.cfg.Fee
+}
+
+func (h *HTTPProcessor) Priority() int {
+	return h.cfg.Priority
+}
+
+func (h *HTTPProcessor) Weight() int {
+	return max(h.cfg.Weight, 1)
+}
+
+func (h *HTTPProcessor) Timeout() time.Duration {
+	return h.cfg.Timeout
+}
+
+func (h *HTTPProcessor) Currency() string {
+	return h.cfg.Currency
+}
+
+func (h *HTTPProcessor) Healthy() bool {
+	return !h.failing.Load()
+}
+
+func (h *HTTPProcessor) MinResponseTime() time.Duration {
+	return time.Duration(h.minResponseTime.Load()) * time.Millisecond
+}
+
+func (h *HTTPProcessor) Pay(ctx context.Context, payment models.PaymentRequest) (int, error) {
+	body, err := payment.MarshalJSON()
+	if err != nil {
+		return 0, err
+	}
+
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+
+	req.SetRequestURI("/payments")
+	req.Header.SetMethod(fasthttp.MethodPost)
+	req.Header.SetContentType("application/json")
+	req.Header.Set("Host", h.cfg.Addr)
+	req.SetBodyRaw(body)
+
+	err = h.doContext(ctx, req, resp)
+	return resp.StatusCode(), err
+}
+
+func (h *HTTPProcessor) CheckHealth(ctx context.Context) error {
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+
+	req.SetRequestURI("/payments/service-health")
+	req.Header.SetMethod(fasthttp.MethodGet)
+	req.Header.Set("Host", h.cfg.Addr)
+
+	if err := h.doContext(ctx, req, resp); err != nil {
+		h.failing.Store(true)
+		return fmt.Errorf("health %s: %w", h.cfg.Name, err)
+	}
+
+	// 429: o processador limita as consultas de health; mantém o último estado.
+	if resp.StatusCode() == fasthttp.StatusTooManyRequests {
+		return nil
+	}
+
+	if resp.StatusCode() != fasthttp.StatusOK {
+		h.failing.Store(true)
+		return fmt.Errorf("health %s: HTTP %d", h.cfg.Name, resp.StatusCode())
+	}
+
+	var parser fastjson.Parser
+	v, err := parser.ParseBytes(resp.Body())
+	if err != nil {
+		return fmt.Errorf("health %s: %w", h.cfg.Name, err)
+	}
+
+	health := models.Health{
+		Failing:         v.GetBool("failing"),
+		MinResponseTime: v.GetInt("minResponseTime"),
+	}
+
+	h.failing.Store(health.Failing)
+	h.minResponseTime.Store(int64(health.MinResponseTime))
+	return nil
+}
+
+func (h *HTTPProcessor) Summary(ctx context.Context, from, to time.Time) (models.PaymentSummary, error) {
+	if h.cfg.Token == "" {
+		return models.PaymentSummary{}, ErrSummaryUnsupported
+	}
+
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+
+	req.SetRequestURI(fmt.Sprintf("/admin/payments-summary?from=%s&to=%s",
+		from.UTC().Format(time.RFC3339Nano), to.UTC().Format(time.RFC3339Nano)))
+	req.Header.SetMethod(fasthttp.MethodGet)
+	req.Header.Set("Host", h.cfg.Addr)
+	req.Header.Set("X-Rinha-Token", h.cfg.Token)
+
+	if err := h.doContext(ctx, req, resp); err != nil {
+		return models.PaymentSummary{}, fmt.Errorf("summary %s: %w", h.cfg.Name, err)
+	}
+
+	if resp.StatusCode() != fasthttp.StatusOK {
+		return models.PaymentSummary{}, fmt.Errorf("summary %s: HTTP %d", h.cfg.Name, resp.StatusCode())
+	}
+
+	var parser fastjson.Parser
+	v, err := parser.ParseBytes(resp.Body())
+	if err != nil {
+		return models.PaymentSummary{}, fmt.Errorf("summary %s: %w", h.cfg.Name, err)
+	}
+
+	return models.PaymentSummary{
+		TotalRequests: v.GetInt("totalRequests"),
+		TotalAmount:   v.GetFloat64("totalAmount"),
+	}, nil
+}
+
+func (h *HTTPProcessor) CanLookup() bool {
+	return h.cfg.Lookup
+}
+
+func (h *HTTPProcessor) Lookup(ctx context.Context, correlationId string) (models.ProcessorPayment, bool, error) {
+	if !h.cfg.Lookup {
+		return models.ProcessorPayment{}, false, ErrLookupUnsupported
+	}
+
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+
+	req.SetRequestURI("/payments/" + correlationId)
+	req.Header.SetMethod(fasthttp.MethodGet)
+	req.Header.Set("Host", h.cfg.Addr)
+
+	if err := h.doContext(ctx, req, resp); err != nil {
+		return models.ProcessorPayment{}, false, fmt.Errorf("lookup %s: %w", h.cfg.Name, err)
+	}
+
+	switch resp.StatusCode() {
+	case fasthttp.StatusOK:
+	case fasthttp.StatusNotFound:
+		return models.ProcessorPayment{}, false, nil
+	default:
+		return models.ProcessorPayment{}, false, fmt.Errorf("lookup %s: HTTP %d", h.cfg.Name, resp.StatusCode())
+	}
+
+	var parser fastjson.Parser
+	v, err := parser.ParseBytes(resp.Body())
+	if err != nil {
+		return models.ProcessorPayment{}, false, fmt.Errorf("lookup %s: %w", h.cfg.Name, err)
+	}
+
+	payment := models.ProcessorPayment{
+		CorrelationId: string(v.GetStringBytes("correlationId")),
+		Amount:        v.GetFloat64("amount"),
+	}
+	if requestedAt := v.GetStringBytes("requestedAt"); len(requestedAt) > 0 {
+		payment.RequestedAt, _ = time.Parse(time.RFC3339Nano, string(requestedAt))
+	}
+
+	return payment, true, nil
+}
+
+// Refund pede o estorno de parte ou de todo um pagamento; refundId permite ao
+// processador descartar pedidos repetidos.
+func (h *HTTPProcessor) Refund(ctx context.Context, refund models.RefundRequest) (int, error) {
+	if !h.cfg.Refund {
+		return 0, ErrRefundUnsupported
+	}
+
+	body, err := refund.MarshalJSON()
+	if err != nil {
+		return 0, err
+	}
+
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+
+	req.SetRequestURI("/payments/" + refund.CorrelationId + "/refund")
+	req.Header.SetMethod(fasthttp.MethodPost)
+	req.Header.SetContentType("application/json")
+	req.Header.Set("Host", h.cfg.Addr)
+	req.SetBodyRaw(body)
+
+	err = h.doContext(ctx, req, resp)
+	return resp.StatusCode(), err
+}
+
+// doContext limita a requisição pelo menor entre o timeout do processador e o
+// deadline do contexto.
+func (h *HTTPProcessor) doContext(ctx context.Context, req *fasthttp.Request, resp *fasthttp.Response) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	deadline, ok := ctx.Deadline()
+	if h.cfg.Timeout > 0 {
+		if timeout := time.Now().Add(h.cfg.Timeout); !ok || timeout.Before(deadline) {
+			deadline, ok = timeout, true
+		}
+	}
+
+	if ok {
+		return h.client.DoDeadline(req, resp, deadline)
+	}
+	return h.client.Do(req, resp)
+}
+
+// IsAmbiguous diz se err pode ter acontecido depois de o processador receber
+// a requisição. Falhas de conexão antes do envio não são ambíguas.
+func IsAmbiguous(err error) bool {
+	switch {
+	case err == nil,
+		errors.Is(err, context.Canceled),
+		errors.Is(err, context.DeadlineExceeded),
+		errors.Is(err, fasthttp.ErrDialTimeout),
+		errors.Is(err, fasthttp.ErrNoFreeConns),
+		errors.Is(err, syscall.ECONNREFUSED):
+		return false
+	case errors.Is(err, fasthttp.ErrTimeout),
+		errors.Is(err, fasthttp.ErrConnectionClosed),
+		errors.Is(err, io.EOF),
+		errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, syscall.ECONNRESET),
+		errors.Is(err, syscall.EPIPE):
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,37 @@
+package processors
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/models"
+)
+
+type Processor interface {
+	Name() string
+	Fee() float64
+	Priority() int
+	Weight() int
+	Timeout() time.Duration
+	// Currency é a moeda aceita pelo processador; vazia aceita qualquer uma.
+	Currency() string
+	Healthy() bool
+	MinResponseTime() time.Duration
+	Pay(ctx context.Context, payment models.PaymentRequest) (int, error)
+	CheckHealth(ctx context.Context) error
+	Summary(ctx context.Context, from, to time.Time) (models.PaymentSummary, error)
+	Lookup(ctx context.Context, correlationId string) (models.ProcessorPayment, bool, error)
+	// CanLookup diz se Lookup consulta o processador em vez de devolver
+	// ErrLookupUnsupported.
+	CanLookup() bool
+	Refund(ctx context.Context, refund models.RefundRequest) (int, error)
+}
+
+var (
+	ErrLookupUnsupported = errors.New("processador não suporta consulta de pagamento")
+	ErrRefundUnsupported = errors.New("processador não suporta estorno")
+	// ErrSummaryUnsupported: sem PROCESSOR_ADMIN_TOKEN não há como consultar
+	// o /admin/payments-summary do processador.
+	ErrSummaryUnsupported = errors.New("processador sem token de admin")
+)
@@ -0,0 +1,89 @@
+package processors
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+)
+
+type Registry struct {
+	processors []Processor
+	byName     map[string]Processor
+	windows    map[string]*Window
+}
+
+func NewRegistry(window WindowConfig, processors ...Processor) *Registry {
+	sorted := make([]Processor, len(processors))
+	copy(sorted, processors)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Priority() < sorted[j].Priority()
+	})
+
+	byName := make(map[string]Processor, len(sorted))
+	windows := make(map[string]*Window, len(sorted))
+	for _, p := range sorted {
+		byName[p.Name()] = p
+		windows[p.Name()] = NewWindow(window.Size, window.MaxAge)
+	}
+
+	return &Registry{processors: sorted, byName: byName, windows: windows}
+}
+
+type WindowConfig struct {
+	Size   int
+	MaxAge time.Duration
+}
+
+func (r *Registry) Window(name string) *Window {
+	return r.windows[name]
+}
+
+func (r *Registry) Record(name string, latency time.Duration, failed bool) {
+	if w, ok := r.windows[name]; ok {
+		w.Record(latency, failed)
+	}
+}
+
+func (r *Registry) Get(name string) (Processor, bool) {
+	p, ok := r.byName[name]
+	return p, ok
+}
+
+func (r *Registry) All() []Processor {
+	return r.processors
+}
+
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.processors))
+	for _, p := range r.processors {
+		names = append(names, p.Name())
+	}
+	return names
+}
+
+// Ordered devolve os processadores por prioridade, com os saudáveis antes.
+func (r *Registry) Ordered() []Processor {
+	ordered := make([]Processor, 0, len(r.processors))
+	for _, p := range r.processors {
+		if p.Healthy() {
+			ordered = append(ordered, p)
+		}
+	}
+	for _, p := range r.processors {
+		if !p.Healthy() {
+			ordered = append(ordered, p)
+		}
+	}
+	return ordered
+}
+
+func (r *Registry) CheckHealth(ctx context.Context) error {
+	var errs []error
+	for _, p := range r.processors {
+		if err := p.CheckHealth(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,214 @@
+package processors
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/models"
+)
+
+const (
+	StrategyPriority   = "priority"
+	StrategyCost       = "cost"
+	StrategyLatency    = "latency"
+	StrategyRoundRobin = "wrr"
+)
+
+type Decision struct {
+	Processor    Processor
+	Strategy     string
+	ExpectedCost float64
+}
+
+// Strategy ordena os processadores para um pagamento; o primeiro da lista é
+// a escolha e os demais são usados em caso de falha.
+type Strategy interface {
+	Name() string
+	Plan(amount float64) []Decision
+	Stats() models.RoutingStats
+}
+
+type CostModel struct {
+	LatencyPenalty float64
+	FailurePenalty float64
+}
+
+func NewStrategy(name string, registry *Registry, model CostModel) (Strategy, error) {
+	b := base{registry: registry, model: model}
+
+	switch name {
+	case "", StrategyPriority:
+		return &priorityStrategy{base: b}, nil
+	case StrategyCost:
+		return &costStrategy{base: b}, nil
+	case StrategyLatency:
+		return &latencyStrategy{base: b}, nil
+	case StrategyRoundRobin:
+		return &roundRobinStrategy{base: b, current: make(map[string]int)}, nil
+	}
+	return nil, fmt.Errorf("estratégia de roteamento desconhecida: %s", name)
+}
+
+// ExpectedCost estima o custo de mandar amount para p: a taxa do processador
+// mais penalidades pela latência e pela chance de falha observadas.
+func (m CostModel) ExpectedCost(registry *Registry, p Processor, amount float64) float64 {
+	latency, failureRate, _ := registry.Window(p.Name()).Snapshot()
+	if latency == 0 {
+		latency = p.MinResponseTime()
+	}
+	if !p.Healthy() {
+		failureRate = 1
+	}
+
+	return amount*p.Fee() + amount*failureRate*m.FailurePenalty + latency.Seconds()*m.LatencyPenalty
+}
+
+type base struct {
+	registry *Registry
+	model    CostModel
+}
+
+func (b base) decisions(strategy string, ordered []Processor, amount float64) []Decision {
+	plan := make([]Decision, 0, len(ordered))
+	for _, p := range ordered {
+		plan = append(plan, Decision{Processor: p, Strategy: strategy, ExpectedCost: b.model.ExpectedCost(b.registry, p, amount)})
+	}
+	return plan
+}
+
+func (b base) stats(strategy string) models.RoutingStats {
+	stats := models.RoutingStats{Strategy: strategy}
+	for _, p := range b.registry.All() {
+		latency, failureRate, samples := b.registry.Window(p.Name()).Snapshot()
+		stats.Processors = append(stats.Processors, models.ProcessorStats{
+			Name:         p.Name(),
+			Healthy:      p.Healthy(),
+			Fee:          p.Fee(),
+			Priority:     p.Priority(),
+			Weight:       p.Weight(),
+			AvgLatencyMs: float64(latency.Microseconds()) / 1000,
+			FailureRate:  failureRate,
+			Samples:      samples,
+			ExpectedCost: b.model.ExpectedCost(b.registry, p, 1),
+		})
+	}
+	return stats
+}
+
+type priorityStrategy struct {
+	base
+}
+
+func (s *priorityStrategy) Name() string {
+	return StrategyPriority
+}
+
+func (s *priorityStrategy) Stats() models.RoutingStats {
+	return s.stats(StrategyPriority)
+}
+
+func (s *priorityStrategy) Plan(amount float64) []Decision {
+	return s.decisions(StrategyPriority, s.registry.Ordered(), amount)
+}
+
+type costStrategy struct {
+	base
+}
+
+func (s *costStrategy) Name() string {
+	return StrategyCost
+}
+
+func (s *costStrategy) Stats() models.RoutingStats {
+	return s.stats(StrategyCost)
+}
+
+func (s *costStrategy) Plan(amount float64) []Decision {
+	plan := s.decisions(StrategyCost, s.registry.All(), amount)
+	sort.SliceStable(plan, func(i, j int) bool {
+		return plan[i].ExpectedCost < plan[j].ExpectedCost
+	})
+	return plan
+}
+
+type latencyStrategy struct {
+	base
+}
+
+func (s *latencyStrategy) Name() string {
+	return StrategyLatency
+}
+
+func (s *latencyStrategy) Stats() models.RoutingStats {
+	return s.stats(StrategyLatency)
+}
+
+func (s *latencyStrategy) Plan(amount float64) []Decision {
+	ordered := s.registry.Ordered()
+	healthy := 0
+	for _, p := range ordered {
+		if p.Healthy() {
+			healthy++
+		}
+	}
+
+	latencies := make(map[string]float64, len(ordered))
+	for _, p := range ordered {
+		latency, _, samples := s.registry.Window(p.Name()).Snapshot()
+		if samples == 0 {
+			latency = p.MinResponseTime()
+		}
+		latencies[p.Name()] = latency.Seconds()
+	}
+
+	sort.SliceStable(ordered[:healthy], func(i, j int) bool {
+		return latencies[ordered[i].Name()] < latencies[ordered[j].Name()]
+	})
+
+	return s.decisions(StrategyLatency, ordered, amount)
+}
+
+// roundRobinStrategy usa smooth weighted round-robin entre os processadores
+// saudáveis, com o peso de cada um; os demais ficam no fim, por prioridade.
+type roundRobinStrategy struct {
+	base
+
+	mu      sync.Mutex
+	current map[string]int
+}
+
+func (s *roundRobinStrategy) Name() string {
+	return StrategyRoundRobin
+}
+
+func (s *roundRobinStrategy) Stats() models.RoutingStats {
+	return s.stats(StrategyRoundRobin)
+}
+
+func (s *roundRobinStrategy) Plan(amount float64) []Decision {
+	ordered := s.registry.Ordered()
+
+	s.mu.Lock()
+	total := 0
+	best := -1
+	for i, p := range ordered {
+		if !p.Healthy() {
+			continue
+		}
+		s.current[p.Name()] += p.Weight()
+		total += p.Weight()
+		if best < 0 || s.current[p.Name()] > s.current[ordered[best].Name()] {
+			best = i
+		}
+	}
+	if best >= 0 {
+		s.current[ordered[best].Name()] -= total
+		chosen := ordered[best]
+		copy(ordered[1:best+1], ordered[:best])
+		ordered[0] = chosen
+	}
+	s.mu.Unlock()
+
+	return s.decisions(StrategyRoundRobin, ordered, amount)
+}
@@ -0,0 +1,70 @@
+package processors
+
+import (
+	"sync"
+	"time"
+)
+
+type outcome struct {
+	at      time.Time
+	latency time.Duration
+	failed  bool
+}
+
+// Window guarda os últimos resultados de um processador, limitados por
+// quantidade e por idade, para estimar latência e probabilidade de falha.
+type Window struct {
+	mu      sync.Mutex
+	maxAge  time.Duration
+	entries []outcome
+	next    int
+	full    bool
+}
+
+func NewWindow(size int, maxAge time.Duration) *Window {
+	return &Window{maxAge: maxAge, entries: make([]outcome, max(size, 1))}
+}
+
+func (w *Window) Record(latency time.Duration, failed bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.entries[w.next] = outcome{at: time.Now(), latency: latency, failed: failed}
+	w.next = (w.next + 1) % len(w.entries)
+	if w.next == 0 {
+		w.full = true
+	}
+}
+
+// Snapshot devolve a latência média, a taxa de falha e a quantidade de
+// amostras consideradas.
+func (w *Window) Snapshot() (time.Duration, float64, int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	size := w.next
+	if w.full {
+		size = len(w.entries)
+	}
+
+	cutoff := time.Now().Add(-w.maxAge)
+	var total time.Duration
+	var failures, samples int
+
+	for _, o := range w.entries[:size] {
+		if w.maxAge > 0 && o.at.Before(cutoff) {
+			continue
+		}
+		samples++
+		total += o.latency
+		if o.failed {
+			failures++
+		}
+	}
+
+	if samples == 0 {
+		return 0, 0, 0
+	}
+
+	return total / time.Duration(samples), float64(failures) / float64(samples), samples
+}
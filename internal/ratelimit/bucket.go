@@ -0,0 +1,94 @@
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// LimitError é devolvido quando um limite é atingido; RetryAfter é o tempo
+// até haver capacidade de novo, usado no header Retry-After.
+type LimitError struct {
+	Reason     string
+	RetryAfter time.Duration
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("limite atingido: %s", e.Reason)
+}
+
+// Bucket é um token bucket: acumula rate tokens por segundo até burst e cada
+// requisição consome um. rate <= 0 desliga o limite.
+type Bucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func NewBucket(rate float64, burst int) *Bucket {
+	b := &Bucket{}
+	b.SetLimit(rate, burst)
+	b.tokens = b.burst
+	return b
+}
+
+// SetLimit troca a taxa sem zerar os tokens já acumulados.
+func (b *Bucket) SetLimit(rate float64, burst int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.rate = rate
+	b.burst = math.Max(float64(burst), 1)
+	b.tokens = math.Min(b.tokens, b.burst)
+}
+
+// Allow consome um token; sem token, devolve quanto falta para o próximo.
+func (b *Bucket) Allow(now time.Time) (bool, time.Duration) {
+	return b.AllowN(now, 1)
+}
+
+// AllowN consome n tokens de uma vez, ou nenhum. Acima do burst, n passa com
+// o bucket cheio e deixa os tokens negativos, pagos pela taxa nos próximos
+// segundos.
+func (b *Bucket) AllowN(now time.Time, n int) (bool, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.rate <= 0 {
+		return true, 0
+	}
+
+	if !b.last.IsZero() {
+		b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	}
+	b.last = now
+
+	need := math.Min(float64(n), b.burst)
+	if b.tokens >= need {
+		b.tokens -= float64(n)
+		return true, 0
+	}
+
+	return false, time.Duration((need - b.tokens) / b.rate * float64(time.Second))
+}
+
+// Return devolve n tokens consumidos por AllowN, quando a requisição é
+// recusada por outro limite.
+func (b *Bucket) Return(n int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.rate > 0 {
+		b.tokens = math.Min(b.burst, b.tokens+float64(n))
+	}
+}
+
+// Idle diz há quanto tempo o bucket não é usado.
+func (b *Bucket) Idle(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return now.Sub(b.last)
+}
@@ -0,0 +1,153 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limit é uma taxa em requisições por segundo com rajada máxima. Rate <= 0
+// desliga o limite; Burst <= 0 usa um segundo de taxa.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+func (l Limit) enabled() bool {
+	return l.Rate > 0
+}
+
+// split divide o limite entre instâncias que recebem o tráfego em partes
+// iguais (round-robin do load balancer), para que a soma fique próxima do
+// limite configurado sem coordenação entre elas.
+func (l Limit) split(instances int) Limit {
+	burst := l.Burst
+	if burst <= 0 {
+		burst = int(math.Ceil(l.Rate))
+	}
+	instances = max(instances, 1)
+	return Limit{Rate: l.Rate / float64(instances), Burst: max(burst/instances, 1)}
+}
+
+func (l Limit) bucket() *Bucket {
+	return NewBucket(l.Rate, l.Burst)
+}
+
+// keyed guarda um bucket por chave, criado no primeiro uso.
+type keyed[K comparable] struct {
+	limit   Limit
+	mu      sync.RWMutex
+	buckets map[K]*Bucket
+}
+
+func newKeyed[K comparable](limit Limit) *keyed[K] {
+	return &keyed[K]{limit: limit, buckets: map[K]*Bucket{}}
+}
+
+func (k *keyed[K]) get(key K, clone func(K) K) *Bucket {
+	k.mu.RLock()
+	b, ok := k.buckets[key]
+	k.mu.RUnlock()
+	if ok {
+		return b
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if b, ok = k.buckets[key]; !ok {
+		b = k.limit.bucket()
+		k.buckets[clone(key)] = b
+	}
+	return b
+}
+
+func (k *keyed[K]) sweep(now time.Time, ttl time.Duration) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	for key, b := range k.buckets {
+		if b.Idle(now) > ttl {
+			delete(k.buckets, key)
+		}
+	}
+}
+
+// Limiter aplica, nessa ordem, os limites por tenant, por IP e global.
+type Limiter struct {
+	global  *Bucket
+	byIP    *keyed[netip.Addr]
+	byKey   *keyed[string]
+	idleTTL time.Duration
+}
+
+// NewLimiter devolve nil quando nenhum limite está ligado. Os limites são
+// totais do serviço e cada instância fica com 1/instances deles.
+func NewLimiter(global, perIP, perKey Limit, instances int, idleTTL time.Duration) *Limiter {
+	if !global.enabled() && !perIP.enabled() && !perKey.enabled() {
+		return nil
+	}
+
+	l := &Limiter{idleTTL: idleTTL}
+	if global.enabled() {
+		l.global = global.split(instances).bucket()
+	}
+	if perIP.enabled() {
+		l.byIP = newKeyed[netip.Addr](perIP.split(instances))
+	}
+	if perKey.enabled() {
+		l.byKey = newKeyed[string](perKey.split(instances))
+	}
+	return l
+}
+
+// Allow consome n tokens de cada limite aplicável; key é o tenant já
+// autenticado. ip inválido ou key vazia pulam o respectivo limite. Recusada
+// por um limite, a requisição devolve o que consumiu dos anteriores.
+func (l *Limiter) Allow(ip netip.Addr, key string, n int, now time.Time) error {
+	var taken []*Bucket
+	allow := func(b *Bucket, reason string) error {
+		if ok, wait := b.AllowN(now, n); !ok {
+			for _, b := range taken {
+				b.Return(n)
+			}
+			return &LimitError{Reason: reason, RetryAfter: wait}
+		}
+		taken = append(taken, b)
+		return nil
+	}
+
+	if l.byKey != nil && key != "" {
+		if err := allow(l.byKey.get(key, strings.Clone), "taxa por tenant"); err != nil {
+			return err
+		}
+	}
+
+	if l.byIP != nil && ip.IsValid() {
+		if err := allow(l.byIP.get(ip, func(a netip.Addr) netip.Addr { return a }), "taxa por IP"); err != nil {
+			return err
+		}
+	}
+
+	if l.global != nil {
+		if err := allow(l.global, "taxa global"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Sweep remove os buckets sem uso há mais de idleTTL; deve rodar via
+// StartWorker para que clientes de passagem não acumulem memória.
+func (l *Limiter) Sweep(ctx context.Context) error {
+	now := time.Now()
+	if l.byIP != nil {
+		l.byIP.sweep(now, l.idleTTL)
+	}
+	if l.byKey != nil {
+		l.byKey.sweep(now, l.idleTTL)
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package ratelimit
+
+import (
+	"errors"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+var epoch = time.Unix(1_700_000_000, 0)
+
+func at(d time.Duration) time.Time {
+	return epoch.Add(d)
+}
+
+func TestBucketBurstAndRefill(t *testing.T) {
+	b := NewBucket(10, 5)
+
+	for i := range 5 {
+		if ok, _ := b.Allow(at(0)); !ok {
+			t.Fatalf("requisição %d da rajada recusada", i)
+		}
+	}
+
+	ok, wait := b.Allow(at(0))
+	if ok {
+		t.Fatal("requisição acima da rajada aceita")
+	}
+	if wait != 100*time.Millisecond {
+		t.Fatalf("RetryAfter = %v, esperado 100ms", wait)
+	}
+
+	// A 10/s, 250ms repõem dois tokens e meio.
+	for i := range 2 {
+		if ok, _ := b.Allow(at(250 * time.Millisecond)); !ok {
+			t.Fatalf("requisição %d depois da reposição recusada", i)
+		}
+	}
+	if ok, _ := b.Allow(at(250 * time.Millisecond)); ok {
+		t.Fatal("o meio token não deveria bastar")
+	}
+
+	// A reposição para no burst, por mais tempo que passe.
+	for i := range 5 {
+		if ok, _ := b.Allow(at(time.Hour)); !ok {
+			t.Fatalf("requisição %d com o bucket cheio recusada", i)
+		}
+	}
+	if ok, _ := b.Allow(at(time.Hour)); ok {
+		t.Fatal("o bucket passou do burst")
+	}
+}
+
+func TestBucketAllowNAboveBurst(t *testing.T) {
+	b := NewBucket(10, 5)
+
+	// Um lote maior que o burst passa com o bucket cheio e fica devendo.
+	if ok, _ := b.AllowN(at(0), 8); !ok {
+		t.Fatal("lote acima do burst recusado com o bucket cheio")
+	}
+	ok, wait := b.AllowN(at(0), 1)
+	if ok || wait != 400*time.Millisecond {
+		t.Fatalf("Allow depois do lote = %v, %v; esperado recusa por 400ms", ok, wait)
+	}
+	if ok, _ := b.AllowN(at(400*time.Millisecond), 1); !ok {
+		t.Fatal("Allow recusado depois de pagar a dívida")
+	}
+}
+
+func TestBucketReturn(t *testing.T) {
+	b := NewBucket(1, 2)
+	b.AllowN(at(0), 2)
+	b.Return(5)
+
+	for range 2 {
+		if ok, _ := b.Allow(at(0)); !ok {
+			t.Fatal("tokens devolvidos não voltaram")
+		}
+	}
+	if ok, _ := b.Allow(at(0)); ok {
+		t.Fatal("Return passou do burst")
+	}
+}
+
+func TestBucketDisabled(t *testing.T) {
+	b := NewBucket(0, 1)
+	for range 100 {
+		if ok, _ := b.Allow(at(0)); !ok {
+			t.Fatal("bucket desligado recusou")
+		}
+	}
+}
+
+func TestLimiterPerKeyIsolation(t *testing.T) {
+	l := NewLimiter(Limit{}, Limit{}, Limit{Rate: 1, Burst: 2}, 1, time.Minute)
+	ip := netip.MustParseAddr("10.0.0.1")
+
+	for range 2 {
+		if err := l.Allow(ip, "acme", 1, at(0)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var limitErr *LimitError
+	if err := l.Allow(ip, "acme", 1, at(0)); !errors.As(err, &limitErr) || limitErr.Reason != "taxa por tenant" {
+		t.Fatalf("terceira requisição de acme: %v", err)
+	}
+
+	// Outro tenant tem o próprio bucket, e sem tenant o limite não se aplica.
+	for range 2 {
+		if err := l.Allow(ip, "globex", 1, at(0)); err != nil {
+			t.Fatalf("globex limitado pelo consumo de acme: %v", err)
+		}
+	}
+	if err := l.Allow(ip, "", 1, at(0)); err != nil {
+		t.Fatalf("requisição sem tenant limitada: %v", err)
+	}
+
+	if err := l.Allow(ip, "acme", 1, at(time.Second)); err != nil {
+		t.Fatalf("acme depois da reposição: %v", err)
+	}
+}
+
+func TestLimiterPerIPIsolation(t *testing.T) {
+	l := NewLimiter(Limit{}, Limit{Rate: 1, Burst: 1}, Limit{}, 1, time.Minute)
+	a, b := netip.MustParseAddr("10.0.0.1"), netip.MustParseAddr("10.0.0.2")
+
+	if err := l.Allow(a, "", 1, at(0)); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Allow(a, "", 1, at(0)); err == nil {
+		t.Fatal("segunda requisição do mesmo IP aceita")
+	}
+	if err := l.Allow(b, "", 1, at(0)); err != nil {
+		t.Fatalf("outro IP limitado: %v", err)
+	}
+	if err := l.Allow(netip.Addr{}, "", 1, at(0)); err != nil {
+		t.Fatalf("IP inválido limitado: %v", err)
+	}
+}
+
+// Uma recusa pelo limite global devolve o que os limites anteriores
+// consumiram, para não cobrar o tenant por uma requisição recusada.
+func TestLimiterReturnsTokensOnLaterRejection(t *testing.T) {
+	l := NewLimiter(Limit{Rate: 1, Burst: 1}, Limit{}, Limit{Rate: 1, Burst: 2}, 1, time.Minute)
+
+	if err := l.Allow(netip.Addr{}, "acme", 1, at(0)); err != nil {
+		t.Fatal(err)
+	}
+	var limitErr *LimitError
+	if err := l.Allow(netip.Addr{}, "acme", 1, at(0)); !errors.As(err, &limitErr) || limitErr.Reason != "taxa global" {
+		t.Fatalf("segunda requisição: %v, esperado taxa global", err)
+	}
+
+	// O bucket do tenant ainda tem um token: o da recusa voltou.
+	if ok, _ := l.byKey.get("acme", func(k string) string { return k }).Allow(at(0)); !ok {
+		t.Fatal("o token consumido na recusa não foi devolvido ao tenant")
+	}
+}
+
+func TestLimiterSplitsAcrossInstances(t *testing.T) {
+	l := NewLimiter(Limit{Rate: 10, Burst: 4}, Limit{}, Limit{}, 2, time.Minute)
+
+	for range 2 {
+		if err := l.Allow(netip.Addr{}, "", 1, at(0)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var limitErr *LimitError
+	if err := l.Allow(netip.Addr{}, "", 1, at(0)); !errors.As(err, &limitErr) {
+		t.Fatalf("terceira requisição com metade do burst: %v", err)
+	}
+	if limitErr.RetryAfter != 200*time.Millisecond {
+		t.Fatalf("RetryAfter = %v, esperado 200ms com metade da taxa", limitErr.RetryAfter)
+	}
+}
+
+func TestNewLimiterDisabled(t *testing.T) {
+	if l := NewLimiter(Limit{}, Limit{}, Limit{}, 1, time.Minute); l != nil {
+		t.Fatal("NewLimiter sem limites deveria devolver nil")
+	}
+}
+
+func TestKeyedSweep(t *testing.T) {
+	k := newKeyed[string](Limit{Rate: 1, Burst: 1})
+	k.get("idle", func(s string) string { return s }).Allow(at(0))
+	k.get("active", func(s string) string { return s }).Allow(at(time.Minute))
+
+	k.sweep(at(time.Minute+time.Second), 30*time.Second)
+
+	if _, ok := k.buckets["idle"]; ok {
+		t.Fatal("bucket ocioso não foi removido")
+	}
+	if _, ok := k.buckets["active"]; !ok {
+		t.Fatal("bucket em uso foi removido")
+	}
+}
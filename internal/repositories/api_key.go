@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/models"
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/storage"
+)
+
+type APIKeyRepository struct {
+	pg storage.PostgresClient
+}
+
+func NewAPIKeyRepository(pg storage.PostgresClient) *APIKeyRepository {
+	return &APIKeyRepository{
+		pg: pg,
+	}
+}
+
+func (a *APIKeyRepository) ListActive(ctx context.Context) ([]models.APIKey, error) {
+	query := `
+		SELECT key_hash, tenant_id, rate_per_second, burst, daily_quota
+		FROM api_keys
+		WHERE active
+	`
+
+	rows, err := a.pg.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []models.APIKey
+	for rows.Next() {
+		var key models.APIKey
+		if err := rows.Scan(&key.KeyHash, &key.TenantId, &key.RatePerSecond, &key.Burst, &key.DailyQuota); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// CountByTenant conta os pagamentos gravados por tenant desde since; usado
+// para alinhar as cotas diárias entre instâncias.
+func (a *APIKeyRepository) CountByTenant(ctx context.Context, since time.Time) (map[string]int64, error) {
+	query := `
+		SELECT tenant_id, COUNT(*)
+		FROM entry_history
+		WHERE created_at >= $1 AND tenant_id <> ''
+		GROUP BY tenant_id
+	`
+
+	rows, err := a.pg.Query(ctx, query, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := map[string]int64{}
+	for rows.Next() {
+		var tenantId string
+		var count int64
+		if err := rows.Scan(&tenantId, &count); err != nil {
+			return nil, err
+		}
+		counts[tenantId] = count
+	}
+
+	return counts, rows.Err()
+}
@@ -0,0 +1,96 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/models"
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/storage"
+	"github.com/jackc/pgx/v5"
+)
+
+type BatchRepository struct {
+	pg storage.PostgresClient
+}
+
+func NewBatchRepository(pg storage.PostgresClient) *BatchRepository {
+	return &BatchRepository{
+		pg: pg,
+	}
+}
+
+func (b *BatchRepository) Insert(ctx context.Context, batch models.BatchDb) error {
+	sql := `
+		INSERT INTO payment_batches (batch_id, tenant_id, correlation_ids, created_at)
+		VALUES ($1, $2, $3::uuid[], $4)
+	`
+	_, err := b.pg.Exec(ctx, sql, batch.BatchId, batch.TenantId, batch.CorrelationIds, batch.CreatedAt)
+	return err
+}
+
+func (b *BatchRepository) Delete(ctx context.Context, batchId string) error {
+	_, err := b.pg.Exec(ctx, `DELETE FROM payment_batches WHERE batch_id = $1`, batchId)
+	return err
+}
+
+// Get devolve o lote com o estado de cada item no banco, na ordem do lote:
+// processed quando está em entry_history, dead-lettered quando está só no
+// dead-letter e Status vazio quando ainda não chegou a nenhum dos dois.
+func (b *BatchRepository) Get(ctx context.Context, batchId, tenantId string) (models.BatchStatus, bool, error) {
+	status := models.BatchStatus{BatchId: batchId}
+
+	err := b.pg.QueryRow(ctx, `
+		SELECT tenant_id, created_at FROM payment_batches
+		WHERE batch_id = $1 AND ($2 = '' OR tenant_id = $2)
+	`, batchId, tenantId).Scan(&status.TenantId, &status.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return status, false, nil
+	}
+	if err != nil {
+		return status, false, err
+	}
+
+	query := `
+		SELECT item.id::text, e.processor, e.created_at, d.processor, d.reason
+		FROM payment_batches pb
+		CROSS JOIN LATERAL unnest(pb.correlation_ids) WITH ORDINALITY AS item(id, n)
+		LEFT JOIN entry_history e ON e.correlationId = item.id
+		LEFT JOIN LATERAL (
+			SELECT processor, reason FROM dead_letter
+			WHERE correlationId = item.id::text
+			ORDER BY created_at DESC
+			LIMIT 1
+		) d ON true
+		WHERE pb.batch_id = $1
+		ORDER BY item.n
+	`
+
+	rows, err := b.pg.Query(ctx, query, batchId)
+	if err != nil {
+		return status, false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item models.BatchItemStatus
+		var processor, deadProcessor, reason *string
+		if err := rows.Scan(&item.CorrelationId, &processor, &item.ProcessedAt, &deadProcessor, &reason); err != nil {
+			return status, false, err
+		}
+
+		switch {
+		case processor != nil:
+			item.Status = models.PaymentProcessed
+			item.Processor = *processor
+		case reason != nil:
+			item.Status = models.PaymentDeadLettered
+			item.Reason = *reason
+			if deadProcessor != nil {
+				item.Processor = *deadProcessor
+			}
+		}
+		status.Items = append(status.Items, item)
+	}
+
+	return status, true, rows.Err()
+}
@@ -0,0 +1,116 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/models"
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/storage"
+	"github.com/jackc/pgx/v5"
+)
+
+type DeadLetterRepository struct {
+	pg storage.PostgresClient
+}
+
+func NewDeadLetterRepository(pg storage.PostgresClient) *DeadLetterRepository {
+	return &DeadLetterRepository{
+		pg: pg,
+	}
+}
+
+func (d *DeadLetterRepository) Insert(ctx context.Context, entry models.DeadLetter) error {
+	sql := `
+		INSERT INTO dead_letter (correlationId, amount, processor, reason, payload, tenant_id, created_at)
+		VALUES (NULLIF($1, ''), $2, NULLIF($3, ''), $4, $5, $6, $7)
+	`
+	_, err := d.pg.Exec(ctx, sql,
+		entry.CorrelationId,
+		entry.Amount,
+		entry.Processor,
+		entry.Reason,
+		entry.Payload,
+		entry.TenantId,
+		entry.CreatedAt,
+	)
+
+	return err
+}
+
+// GetLatest devolve a entrada mais recente de correlationId.
+func (d *DeadLetterRepository) GetLatest(ctx context.Context, correlationId string) (models.DeadLetter, bool, error) {
+	query := `
+		SELECT correlationId, COALESCE(amount, 0), COALESCE(processor, ''), reason, payload, tenant_id, created_at
+		FROM dead_letter
+		WHERE correlationId = $1
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+
+	var entry models.DeadLetter
+	err := d.pg.QueryRow(ctx, query, correlationId).Scan(
+		&entry.CorrelationId,
+		&entry.Amount,
+		&entry.Processor,
+		&entry.Reason,
+		&entry.Payload,
+		&entry.TenantId,
+		&entry.CreatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entry, false, nil
+	}
+
+	return entry, err == nil, err
+}
+
+// Stream é o equivalente de PaymentRepository.Stream para o dead-letter.
+func (d *DeadLetterRepository) Stream(ctx context.Context, filter models.PaymentFilter, fn func(models.PaymentRecord) error) error {
+	query := `
+		SELECT correlationId, COALESCE(amount, 0), COALESCE(processor, ''), tenant_id, reason, created_at
+		FROM dead_letter
+		WHERE
+			correlationId IS NOT NULL
+			AND ($1::timestamp IS NULL OR created_at >= $1)
+			AND ($2::timestamp IS NULL OR created_at <= $2)
+			AND ($3 = '' OR processor = $3)
+			AND ($4::decimal IS NULL OR amount >= $4)
+			AND ($5::decimal IS NULL OR amount <= $5)
+			AND ($6 = '' OR tenant_id = $6)
+			AND ($7::timestamp IS NULL OR (created_at, correlationId) > ($7, $8::text))
+		ORDER BY created_at, correlationId
+		LIMIT $9
+	`
+
+	var afterAt *time.Time
+	var afterId *string
+	if filter.After != nil {
+		afterAt, afterId = &filter.After.CreatedAt, &filter.After.CorrelationId
+	}
+
+	var limit *int
+	if filter.Limit > 0 {
+		limit = &filter.Limit
+	}
+
+	rows, err := d.pg.Query(ctx, query, filter.From, filter.To, filter.Processor, filter.MinAmount, filter.MaxAmount,
+		filter.TenantId, afterAt, afterId, limit)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		record := models.PaymentRecord{Status: models.PaymentDeadLettered}
+		if err := rows.Scan(&record.CorrelationId, &record.Amount, &record.Processor, &record.TenantId,
+			&record.Reason, &record.CreatedAt); err != nil {
+			return err
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
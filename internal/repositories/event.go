@@ -0,0 +1,115 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/models"
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/storage"
+)
+
+// eventsChannel é o canal do NOTIFY disparado a cada INSERT em payment_events.
+const eventsChannel = "payment_events"
+
+type EventRepository struct {
+	pg storage.PostgresClient
+}
+
+func NewEventRepository(pg storage.PostgresClient) *EventRepository {
+	return &EventRepository{
+		pg: pg,
+	}
+}
+
+// Append grava os eventos na ordem recebida. O advisory lock serializa as
+// gravações das instâncias, para que os ids fiquem visíveis na ordem em que
+// foram gerados: quem lê com id > último nunca pula um id ainda não commitado.
+func (e *EventRepository) Append(ctx context.Context, records []models.EventRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	types := make([]string, len(records))
+	processors := make([]string, len(records))
+	correlationIds := make([]string, len(records))
+	tenantIds := make([]string, len(records))
+	data := make([]string, len(records))
+	for i, record := range records {
+		types[i] = record.Type
+		processors[i] = record.Processor
+		correlationIds[i] = record.CorrelationId
+		tenantIds[i] = record.TenantId
+		data[i] = record.Data
+	}
+
+	tx, err := e.pg.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(context.WithoutCancel(ctx)) }()
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('payment_events'))`); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO payment_events (type, processor, correlationId, tenant_id, data)
+		SELECT type, processor, correlationId, tenant_id, data
+		FROM unnest($1::text[], $2::text[], $3::text[], $4::text[], $5::text[]) WITH ORDINALITY AS e(type, processor, correlationId, tenant_id, data, n)
+		ORDER BY n
+	`, types, processors, correlationIds, tenantIds, data)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// After devolve, em ordem, até limit eventos com id maior que lastId e menor
+// que before (0 não limita).
+func (e *EventRepository) After(ctx context.Context, lastId, before uint64, limit int) ([]models.EventRecord, error) {
+	query := `
+		SELECT id, type, processor, correlationId, tenant_id, data
+		FROM payment_events
+		WHERE id > $1 AND ($2 = 0 OR id < $2)
+		ORDER BY id
+		LIMIT $3
+	`
+
+	rows, err := e.pg.Query(ctx, query, int64(lastId), int64(before), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []models.EventRecord
+	for rows.Next() {
+		var record models.EventRecord
+		var id int64
+		if err := rows.Scan(&id, &record.Type, &record.Processor, &record.CorrelationId, &record.TenantId, &record.Data); err != nil {
+			return nil, err
+		}
+		record.Id = uint64(id)
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
+}
+
+// LastId devolve o maior id gravado, ou 0 sem eventos.
+func (e *EventRepository) LastId(ctx context.Context) (uint64, error) {
+	var id int64
+	err := e.pg.QueryRow(ctx, `SELECT COALESCE(MAX(id), 0) FROM payment_events`).Scan(&id)
+	return uint64(id), err
+}
+
+// Prune remove os eventos gravados antes de before.
+func (e *EventRepository) Prune(ctx context.Context, before time.Time) (int64, error) {
+	return e.pg.Exec(ctx, `DELETE FROM payment_events WHERE created_at < $1`, before)
+}
+
+// Listen chama notify a cada gravação em payment_events, de qualquer
+// instância, até ctx acabar ou a conexão cair.
+func (e *EventRepository) Listen(ctx context.Context, notify func()) error {
+	return e.pg.Listen(ctx, eventsChannel, notify)
+}
@@ -0,0 +1,169 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	insertTarget = regexp.MustCompile(`(?s)INSERT INTO \w+ \(([^)]*)\)\s*SELECT (.*?)\s+FROM unnest\((.*?)\) WITH ORDINALITY AS \w+\(([^)]*)\)`)
+	placeholder  = regexp.MustCompile(`\$(\d+)`)
+)
+
+// eventPostgres confere os INSERTs de Append como o Postgres faria: mesmo
+// número de colunas e expressões, só colunas do unnest e um array por
+// parâmetro, todos do mesmo tamanho.
+type eventPostgres struct {
+	t         *testing.T
+	inserted  [][]string
+	committed bool
+}
+
+func (p *eventPostgres) Close() {}
+
+func (p *eventPostgres) Exec(context.Context, string, ...interface{}) (int64, error) {
+	return 0, errors.New("fora da transação")
+}
+
+func (p *eventPostgres) QueryRow(context.Context, string, ...interface{}) pgx.Row {
+	return nil
+}
+
+func (p *eventPostgres) Query(context.Context, string, ...interface{}) (pgx.Rows, error) {
+	return nil, errors.New("sem banco")
+}
+
+func (p *eventPostgres) Begin(context.Context) (pgx.Tx, error) {
+	return &eventTx{pg: p}, nil
+}
+
+func (p *eventPostgres) CopyTo(context.Context, io.Writer, string) (int64, error) {
+	return 0, errors.New("sem banco")
+}
+
+func (p *eventPostgres) Listen(context.Context, string, func()) error {
+	return errors.New("sem banco")
+}
+
+type eventTx struct {
+	pgx.Tx
+	pg *eventPostgres
+}
+
+func (tx *eventTx) Exec(_ context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	if !strings.Contains(sql, "INSERT INTO") {
+		return pgconn.NewCommandTag("SELECT 1"), nil
+	}
+
+	t := tx.pg.t
+	match := insertTarget.FindStringSubmatch(sql)
+	if match == nil {
+		t.Fatalf("INSERT inesperado: %s", sql)
+	}
+
+	targets, exprs, aliases := splitList(match[1]), splitList(match[2]), splitList(match[4])
+	if len(exprs) != len(targets) {
+		t.Fatalf("INSERT com %d expressões para %d colunas: %s", len(exprs), len(targets), sql)
+	}
+	known := map[string]bool{}
+	for _, alias := range aliases {
+		known[alias] = true
+	}
+	for _, expr := range exprs {
+		if !known[expr] {
+			t.Fatalf("expressão %q fora do unnest %v", expr, aliases)
+		}
+	}
+
+	if n := len(placeholder.FindAllString(match[3], -1)); n != len(args) {
+		t.Fatalf("%d parâmetros no unnest para %d argumentos", n, len(args))
+	}
+
+	var rows int
+	columns := make([][]string, len(args))
+	for i, arg := range args {
+		column, ok := arg.([]string)
+		if !ok {
+			t.Fatalf("argumento %d é %T, esperado []string", i+1, arg)
+		}
+		if i > 0 && len(column) != rows {
+			t.Fatalf("argumento %d com %d linhas, esperado %d", i+1, len(column), rows)
+		}
+		rows = len(column)
+		columns[i] = column
+	}
+
+	for row := 0; row < rows; row++ {
+		record := make([]string, len(columns))
+		for i, column := range columns {
+			record[i] = column[row]
+		}
+		tx.pg.inserted = append(tx.pg.inserted, record)
+	}
+	return pgconn.NewCommandTag(fmt.Sprintf("INSERT 0 %d", rows)), nil
+}
+
+func (tx *eventTx) Commit(context.Context) error {
+	tx.pg.committed = true
+	return nil
+}
+
+func (tx *eventTx) Rollback(context.Context) error {
+	return nil
+}
+
+func splitList(list string) []string {
+	parts := strings.Split(list, ",")
+	for i, part := range parts {
+		parts[i] = strings.TrimSpace(part)
+	}
+	return parts
+}
+
+func TestEventAppendInsertsNamedColumnsInOrder(t *testing.T) {
+	pg := &eventPostgres{t: t}
+	repo := NewEventRepository(pg)
+
+	records := []models.EventRecord{
+		{Type: "payment.accepted", CorrelationId: "a", TenantId: "t1", Data: `{"n":1}`},
+		{Type: "payment.processed", Processor: "default", CorrelationId: "b", Data: `{"n":2}`},
+	}
+	if err := repo.Append(context.Background(), records); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+
+	if !pg.committed {
+		t.Fatal("transação sem commit")
+	}
+	want := [][]string{
+		{"payment.accepted", "", "a", "t1", `{"n":1}`},
+		{"payment.processed", "default", "b", "", `{"n":2}`},
+	}
+	if len(pg.inserted) != len(want) {
+		t.Fatalf("inseridas %d linhas, esperadas %d", len(pg.inserted), len(want))
+	}
+	for i := range want {
+		if strings.Join(pg.inserted[i], "|") != strings.Join(want[i], "|") {
+			t.Fatalf("linha %d = %q, esperada %q", i, pg.inserted[i], want[i])
+		}
+	}
+}
+
+func TestEventAppendEmptyDoesNothing(t *testing.T) {
+	pg := &eventPostgres{t: t}
+	if err := NewEventRepository(pg).Append(context.Background(), nil); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	if pg.committed {
+		t.Fatal("transação aberta sem eventos")
+	}
+}
@@ -0,0 +1,278 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"time"
+
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/models"
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/storage"
+	"github.com/jackc/pgx/v5"
+)
+
+const partitionBoundLayout = "2006-01-02 15:04:05"
+
+var partitionBound = regexp.MustCompile(`FROM \((.+)\) TO \((.+)\)`)
+
+// PartitionRepository cria e remove as partições de entry_history. Criação e
+// remoção seguram advisory locks, então as duas instâncias podem rodar a
+// manutenção ao mesmo tempo.
+type PartitionRepository struct {
+	pg storage.PostgresClient
+}
+
+func NewPartitionRepository(pg storage.PostgresClient) *PartitionRepository {
+	return &PartitionRepository{
+		pg: pg,
+	}
+}
+
+// List devolve as partições por intervalo, sem a default.
+func (p *PartitionRepository) List(ctx context.Context) ([]models.EntryPartition, error) {
+	query := `
+		SELECT c.relname, pg_get_expr(c.relpartbound, c.oid)
+		FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		WHERE i.inhparent = 'entry_history'::regclass
+		ORDER BY c.relname
+	`
+
+	rows, err := p.pg.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var partitions []models.EntryPartition
+	for rows.Next() {
+		var name, bound string
+		if err := rows.Scan(&name, &bound); err != nil {
+			return nil, err
+		}
+
+		match := partitionBound.FindStringSubmatch(bound)
+		if match == nil {
+			continue
+		}
+
+		partition := models.EntryPartition{Name: name}
+		if partition.From, err = parsePartitionBound(match[1]); err != nil {
+			return nil, fmt.Errorf("partição %s: %w", name, err)
+		}
+		if partition.To, err = parsePartitionBound(match[2]); err != nil {
+			return nil, fmt.Errorf("partição %s: %w", name, err)
+		}
+		partitions = append(partitions, partition)
+	}
+
+	return partitions, rows.Err()
+}
+
+// parsePartitionBound lê um limite como '2025-07-10 00:00:00'; MINVALUE e
+// MAXVALUE viram o instante zero.
+func parsePartitionBound(bound string) (time.Time, error) {
+	if bound == "MINVALUE" || bound == "MAXVALUE" {
+		return time.Time{}, nil
+	}
+	if len(bound) < 2 || bound[0] != '\'' || bound[len(bound)-1] != '\'' {
+		return time.Time{}, fmt.Errorf("limite inesperado: %s", bound)
+	}
+	return time.Parse(partitionBoundLayout+".999999", bound[1:len(bound)-1])
+}
+
+// Create cria a partição [from, to) se ela ainda não existir. Linhas do
+// intervalo que já estejam em entry_history_default são movidas para ela, e a
+// tabela nova entra com ATTACH. O ATTACH segura em entry_history só um SHARE
+// UPDATE EXCLUSIVE, que deixa passar as escritas nas outras partições, mas
+// trava entry_history_default, que ele precisa varrer, até o commit; por isso
+// as partições são criadas com antecedência, quando a default ainda não tem
+// linhas do intervalo.
+func (p *PartitionRepository) Create(ctx context.Context, name string, from, to time.Time) (bool, error) {
+	tx, err := p.pg.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(context.WithoutCancel(ctx))
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('entry_history_partitions'))`); err != nil {
+		return false, err
+	}
+
+	var exists bool
+	if err := tx.QueryRow(ctx, `SELECT to_regclass($1) IS NOT NULL`, name).Scan(&exists); err != nil {
+		return false, err
+	}
+	if exists {
+		return false, nil
+	}
+
+	table := pgx.Identifier{name}.Sanitize()
+	statements := []string{
+		fmt.Sprintf(`CREATE UNLOGGED TABLE %s (LIKE entry_history INCLUDING DEFAULTS)`, table),
+		fmt.Sprintf(`
+			WITH moved AS (
+				DELETE FROM entry_history_default
+				WHERE created_at >= '%[2]s' AND created_at < '%[3]s'
+				RETURNING *
+			)
+			INSERT INTO %[1]s SELECT * FROM moved
+		`, table, from.Format(partitionBoundLayout), to.Format(partitionBoundLayout)),
+		fmt.Sprintf(`ALTER TABLE entry_history ATTACH PARTITION %s FOR VALUES FROM ('%s') TO ('%s')`,
+			table, from.Format(partitionBoundLayout), to.Format(partitionBoundLayout)),
+	}
+	for _, sql := range statements {
+		if _, err := tx.Exec(ctx, sql); err != nil {
+			return false, fmt.Errorf("partição %s: %w", name, err)
+		}
+	}
+
+	return true, tx.Commit(ctx)
+}
+
+// expiredComment marca uma partição desanexada por Expire que ainda não
+// passou por Finish.
+const expiredComment = "expired"
+
+// Expire desanexa a partição de entry_history e a marca para Finish. Devolve
+// false quando outra instância já está cuidando dela ou ela não está mais
+// anexada. Desanexada, a tabela não recebe mais escritas, então o que Finish
+// arquivar é tudo o que ela tem.
+func (p *PartitionRepository) Expire(ctx context.Context, partition models.EntryPartition) (bool, error) {
+	tx, err := p.pg.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(context.WithoutCancel(ctx))
+
+	var locked bool
+	if err := tx.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock(hashtext($1))`, partition.Name).Scan(&locked); err != nil {
+		return false, err
+	}
+	if !locked {
+		return false, nil
+	}
+
+	var attached bool
+	err = tx.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM pg_inherits
+			WHERE inhparent = 'entry_history'::regclass AND inhrelid = to_regclass($1)
+		)
+	`, partition.Name).Scan(&attached)
+	if err != nil || !attached {
+		return false, err
+	}
+
+	table := pgx.Identifier{partition.Name}.Sanitize()
+	statements := []string{
+		fmt.Sprintf(`ALTER TABLE entry_history DETACH PARTITION %s`, table),
+		fmt.Sprintf(`COMMENT ON TABLE %s IS '%s'`, table, expiredComment),
+	}
+	for _, sql := range statements {
+		if _, err := tx.Exec(ctx, sql); err != nil {
+			return false, err
+		}
+	}
+
+	// Sem a partição, os correlationIds dela podem ser gravados de novo.
+	for _, table := range []string{"entry_history_ids", "payment_owners"} {
+		_, err = tx.Exec(ctx, fmt.Sprintf(`
+			DELETE FROM %s
+			WHERE ($1::timestamp IS NULL OR created_at >= $1) AND created_at < $2
+		`, table), nullTime(partition.From), partition.To)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return true, tx.Commit(ctx)
+}
+
+// Expired devolve as partições desanexadas por Expire que ainda esperam
+// Finish, inclusive as de rodadas anteriores em que o arquivamento falhou.
+// Só Name é preenchido.
+func (p *PartitionRepository) Expired(ctx context.Context) ([]models.EntryPartition, error) {
+	query := `
+		SELECT c.relname
+		FROM pg_class c
+		WHERE c.relkind = 'r' AND c.relname LIKE 'entry_history%'
+		AND obj_description(c.oid, 'pg_class') = $1
+		ORDER BY c.relname
+	`
+
+	rows, err := p.pg.Query(ctx, query, expiredComment)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var partitions []models.EntryPartition
+	for rows.Next() {
+		var partition models.EntryPartition
+		if err := rows.Scan(&partition.Name); err != nil {
+			return nil, err
+		}
+		partitions = append(partitions, partition)
+	}
+
+	return partitions, rows.Err()
+}
+
+// Finish chama archive com a partição já desanexada e, com drop, apaga a
+// tabela; sem drop, só tira a marca de Expire. Devolve false quando outra
+// instância já está cuidando dela ou ela já foi finalizada. Com erro em
+// archive, nada muda e a partição volta em Expired.
+func (p *PartitionRepository) Finish(ctx context.Context, name string, drop bool, archive func(ctx context.Context) error) (bool, error) {
+	tx, err := p.pg.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(context.WithoutCancel(ctx))
+
+	var locked bool
+	if err := tx.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock(hashtext($1))`, name).Scan(&locked); err != nil {
+		return false, err
+	}
+	if !locked {
+		return false, nil
+	}
+
+	var expired bool
+	err = tx.QueryRow(ctx, `SELECT COALESCE(obj_description(to_regclass($1), 'pg_class') = $2, false)`, name, expiredComment).Scan(&expired)
+	if err != nil || !expired {
+		return false, err
+	}
+
+	if archive != nil {
+		if err := archive(ctx); err != nil {
+			return false, err
+		}
+	}
+
+	table := pgx.Identifier{name}.Sanitize()
+	sql := fmt.Sprintf(`COMMENT ON TABLE %s IS NULL`, table)
+	if drop {
+		sql = fmt.Sprintf(`DROP TABLE %s`, table)
+	}
+	if _, err := tx.Exec(ctx, sql); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit(ctx)
+}
+
+// Export escreve a partição em CSV, com cabeçalho, ordenada por created_at.
+func (p *PartitionRepository) Export(ctx context.Context, name string, w io.Writer) (int64, error) {
+	sql := fmt.Sprintf(`COPY (SELECT * FROM %s ORDER BY created_at, correlationId) TO STDOUT WITH (FORMAT csv, HEADER)`,
+		pgx.Identifier{name}.Sanitize())
+	return p.pg.CopyTo(ctx, w, sql)
+}
+
+func nullTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,48 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParsePartitionBound(t *testing.T) {
+	cases := []struct {
+		bound string
+		want  time.Time
+	}{
+		{"'2025-07-10 00:00:00'", time.Date(2025, 7, 10, 0, 0, 0, 0, time.UTC)},
+		{"'2025-07-10 13:00:00'", time.Date(2025, 7, 10, 13, 0, 0, 0, time.UTC)},
+		{"'2025-07-10 13:45:12.5'", time.Date(2025, 7, 10, 13, 45, 12, 500000000, time.UTC)},
+		{"MINVALUE", time.Time{}},
+		{"MAXVALUE", time.Time{}},
+	}
+
+	for _, c := range cases {
+		got, err := parsePartitionBound(c.bound)
+		if err != nil {
+			t.Fatalf("parsePartitionBound(%s): %v", c.bound, err)
+		}
+		if !got.Equal(c.want) {
+			t.Fatalf("parsePartitionBound(%s) = %v, esperado %v", c.bound, got, c.want)
+		}
+	}
+}
+
+func TestParsePartitionBoundRejectsUnexpected(t *testing.T) {
+	for _, bound := range []string{"", "'", "2025-07-10 00:00:00", "'2025-07-10'", "'10/07/2025 00:00:00'"} {
+		if _, err := parsePartitionBound(bound); err == nil {
+			t.Fatalf("parsePartitionBound(%q) aceitou um limite inválido", bound)
+		}
+	}
+}
+
+func TestPartitionBoundRegexp(t *testing.T) {
+	match := partitionBound.FindStringSubmatch("FOR VALUES FROM ('2025-07-10 00:00:00') TO ('2025-07-11 00:00:00')")
+	if match == nil || match[1] != "'2025-07-10 00:00:00'" || match[2] != "'2025-07-11 00:00:00'" {
+		t.Fatalf("match = %q", match)
+	}
+
+	if match := partitionBound.FindStringSubmatch("DEFAULT"); match != nil {
+		t.Fatalf("DEFAULT casou: %q", match)
+	}
+}
@@ -2,10 +2,12 @@ package repositories
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/models"
 	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/storage"
+	"github.com/jackc/pgx/v5"
 )
 
 type PaymentRepository struct {
@@ -18,71 +20,226 @@ func NewPaymentRepository(pg storage.PostgresClient) *PaymentRepository {
 	}
 }
 
+// Insert ignora correlationIds já gravados. Como a chave de entry_history
+// inclui created_at (a coluna de partição), a unicidade do correlationId vem
+// de entry_history_ids, na mesma instrução.
 func (p *PaymentRepository) Insert(ctx context.Context, payment models.PaymentDb) error {
 	sql := `
-		INSERT INTO entry_history (correlationId, amount, fallback, created_at)
-		VALUES ($1, $2, $3, $4)
+		WITH claimed AS (
+			INSERT INTO entry_history_ids (correlationId, created_at)
+			VALUES ($1, $7)
+			ON CONFLICT (correlationId) DO NOTHING
+			RETURNING correlationId
+		)
+		INSERT INTO entry_history (correlationId, amount, processor, routing_strategy, expected_cost, tenant_id, created_at, currency, processor_currency, rate, base_rate)
+		SELECT correlationId, $2::decimal, $3::text, $4::text, $5::decimal, $6::text, $7::timestamp, $8::text, $9::text, $10::decimal, $11::decimal
+		FROM claimed
 	`
 	_, err := p.pg.Exec(ctx, sql,
 		payment.CorrelationId,
 		payment.Amount,
-		payment.Fallback,
+		payment.Processor,
+		payment.Strategy,
+		payment.ExpectedCost,
+		payment.TenantId,
 		payment.CreatedAt,
+		payment.Currency,
+		payment.ProcessorCurrency,
+		payment.Rate,
+		payment.BaseRate,
 	)
 
 	return err
 }
 
-func (p *PaymentRepository) GetPaymentSummary(ctx context.Context, from, to *time.Time) (*models.SummaryResponse, error) {
+// ClaimOwner registra tenantId como dono de correlationId, se ninguém o for
+// ainda, e devolve o dono.
+func (p *PaymentRepository) ClaimOwner(ctx context.Context, correlationId, tenantId string) (string, error) {
+	sql := `
+		WITH claimed AS (
+			INSERT INTO payment_owners (correlationId, tenant_id, created_at)
+			VALUES ($1, $2, NOW())
+			ON CONFLICT (correlationId) DO NOTHING
+			RETURNING tenant_id
+		)
+		SELECT tenant_id FROM claimed
+		UNION ALL
+		SELECT tenant_id FROM payment_owners WHERE correlationId = $1
+		LIMIT 1
+	`
+
+	var owner string
+	err := p.pg.QueryRow(ctx, sql, correlationId, tenantId).Scan(&owner)
+	return owner, err
+}
+
+// GetPaymentSummary agrupa por processador; tenantId vazio soma todos os tenants.
+// TotalAmount é convertido para a moeda base pela cotação gravada em cada
+// pagamento e Currencies traz os totais por moeda, com a chave vazia para a
+// moeda base. O filtro de período é escrito sem OR para que o Postgres só leia
+// as partições do intervalo.
+func (p *PaymentRepository) GetPaymentSummary(ctx context.Context, tenantId string, from, to *time.Time) (*models.SummaryResponse, error) {
 	query := `
 		SELECT 
-			fallback,
+			processor,
+			currency,
 			COUNT(*) AS total_requests,
-			SUM(amount) AS total_amount
+			SUM(amount) AS total_amount,
+			SUM(ROUND(amount * base_rate, 2)) AS base_amount,
+			SUM(ROUND(amount * rate, 2)) AS processor_amount
 		FROM 
 			entry_history
 		WHERE 
-			($1::timestamp IS NULL OR created_at >= $1)
-			AND ($2::timestamp IS NULL OR created_at <= $2)
+			created_at >= COALESCE($1::timestamp, '-infinity')
+			AND created_at <= COALESCE($2::timestamp, 'infinity')
+			AND ($3 = '' OR tenant_id = $3)
 		GROUP BY 
-			fallback;
+			processor, currency;
 	`
 
-	rows, err := p.pg.Query(ctx, query, from, to)
+	rows, err := p.pg.Query(ctx, query, from, to, tenantId)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var summary models.SummaryResponse
+	summary := models.SummaryResponse{}
 
 	for rows.Next() {
-		var fallback bool
+		var processor, currency string
 		var totalRequests int
-		var totalAmount float64
+		var totalAmount, baseAmount, processorAmount float64
 
-		if err := rows.Scan(&fallback, &totalRequests, &totalAmount); err != nil {
+		if err := rows.Scan(&processor, &currency, &totalRequests, &totalAmount, &baseAmount, &processorAmount); err != nil {
 			return nil, err
 		}
 
-		if fallback {
-			summary.Fallback = models.PaymentSummary{
-				TotalRequests: totalRequests,
-				TotalAmount:   totalAmount,
-			}
-		} else {
-			summary.Default = models.PaymentSummary{
-				TotalRequests: totalRequests,
-				TotalAmount:   totalAmount,
-			}
+		item := summary[processor]
+		item.TotalRequests += totalRequests
+		item.TotalAmount += baseAmount
+		item.ProcessorAmount += processorAmount
+		if item.Currencies == nil {
+			item.Currencies = map[string]models.CurrencySummary{}
+		}
+		bucket := item.Currencies[currency]
+		bucket.TotalRequests += totalRequests
+		bucket.TotalAmount += totalAmount
+		item.Currencies[currency] = bucket
+		summary[processor] = item
+	}
+
+	return &summary, rows.Err()
+}
+
+func (p *PaymentRepository) Get(ctx context.Context, correlationId string) (models.PaymentDb, bool, error) {
+	query := `
+		SELECT correlationId::text, amount, processor, routing_strategy, COALESCE(expected_cost, 0), tenant_id, created_at,
+			currency, processor_currency, rate, base_rate
+		FROM entry_history
+		WHERE correlationId = $1
+	`
+
+	var payment models.PaymentDb
+	err := p.pg.QueryRow(ctx, query, correlationId).Scan(
+		&payment.CorrelationId,
+		&payment.Amount,
+		&payment.Processor,
+		&payment.Strategy,
+		&payment.ExpectedCost,
+		&payment.TenantId,
+		&payment.CreatedAt,
+		&payment.Currency,
+		&payment.ProcessorCurrency,
+		&payment.Rate,
+		&payment.BaseRate,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return payment, false, nil
+	}
+
+	return payment, err == nil, err
+}
+
+func (p *PaymentRepository) ListCorrelationIds(ctx context.Context, processor string, from, to time.Time, limit int) ([]models.PaymentDb, error) {
+	query := `
+		SELECT correlationId::text, amount, rate
+		FROM entry_history
+		WHERE processor = $1 AND created_at >= $2 AND created_at <= $3
+		ORDER BY created_at DESC
+		LIMIT $4
+	`
+
+	rows, err := p.pg.Query(ctx, query, processor, from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var payments []models.PaymentDb
+	for rows.Next() {
+		payment := models.PaymentDb{Processor: processor}
+		if err := rows.Scan(&payment.CorrelationId, &payment.Amount, &payment.Rate); err != nil {
+			return nil, err
 		}
+		payments = append(payments, payment)
 	}
 
-	return &summary, nil
+	return payments, rows.Err()
 }
 
 func (p *PaymentRepository) PurgeAll(ctx context.Context) error {
-	sql := `TRUNCATE TABLE entry_history RESTART IDENTITY;`
+	sql := `TRUNCATE TABLE entry_history, entry_history_ids, payment_owners, refund_history, payment_batches, scheduled_payments RESTART IDENTITY;`
 	_, err := p.pg.Exec(ctx, sql)
 	return err
 }
+
+// Stream percorre os pagamentos gravados em ordem de (created_at,
+// correlationId), lendo as linhas do Postgres conforme fn as consome, sem
+// carregar o resultado inteiro em memória.
+func (p *PaymentRepository) Stream(ctx context.Context, filter models.PaymentFilter, fn func(models.PaymentRecord) error) error {
+	query := `
+		SELECT correlationId::text, amount, processor, routing_strategy, tenant_id, created_at
+		FROM entry_history
+		WHERE
+			($1::timestamp IS NULL OR created_at >= $1)
+			AND ($2::timestamp IS NULL OR created_at <= $2)
+			AND ($3 = '' OR processor = $3)
+			AND ($4::decimal IS NULL OR amount >= $4)
+			AND ($5::decimal IS NULL OR amount <= $5)
+			AND ($6 = '' OR tenant_id = $6)
+			AND ($7::timestamp IS NULL OR (created_at, correlationId) > ($7, $8::uuid))
+		ORDER BY created_at, correlationId
+		LIMIT $9
+	`
+
+	var afterAt *time.Time
+	var afterId *string
+	if filter.After != nil {
+		afterAt, afterId = &filter.After.CreatedAt, &filter.After.CorrelationId
+	}
+
+	var limit *int
+	if filter.Limit > 0 {
+		limit = &filter.Limit
+	}
+
+	rows, err := p.pg.Query(ctx, query, filter.From, filter.To, filter.Processor, filter.MinAmount, filter.MaxAmount,
+		filter.TenantId, afterAt, afterId, limit)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		record := models.PaymentRecord{Status: models.PaymentProcessed}
+		if err := rows.Scan(&record.CorrelationId, &record.Amount, &record.Processor, &record.Strategy,
+			&record.TenantId, &record.CreatedAt); err != nil {
+			return err
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
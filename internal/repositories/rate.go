@@ -0,0 +1,40 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/storage"
+)
+
+type RateRepository struct {
+	pg storage.PostgresClient
+}
+
+func NewRateRepository(pg storage.PostgresClient) *RateRepository {
+	return &RateRepository{
+		pg: pg,
+	}
+}
+
+// List devolve as cotações por moeda, em unidades da moeda base.
+func (r *RateRepository) List(ctx context.Context) (map[string]float64, error) {
+	query := `SELECT currency, rate FROM currency_rates`
+
+	rows, err := r.pg.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rates := map[string]float64{}
+	for rows.Next() {
+		var currency string
+		var rate float64
+		if err := rows.Scan(&currency, &rate); err != nil {
+			return nil, err
+		}
+		rates[currency] = rate
+	}
+
+	return rates, rows.Err()
+}
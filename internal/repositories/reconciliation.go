@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/models"
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/storage"
+)
+
+type ReconciliationRepository struct {
+	pg storage.PostgresClient
+}
+
+func NewReconciliationRepository(pg storage.PostgresClient) *ReconciliationRepository {
+	return &ReconciliationRepository{
+		pg: pg,
+	}
+}
+
+// TryLock toma o lease da reconciliação para owner até now+lease, para que só
+// uma instância reconcilie por vez. Devolve false se outra o tem e ele ainda
+// não venceu. O lease não prende transação nem conexão durante a rodada.
+func (r *ReconciliationRepository) TryLock(ctx context.Context, owner string, now time.Time, lease time.Duration) (bool, error) {
+	sql := `
+		INSERT INTO reconciliation_lease (name, owner, expires_at)
+		VALUES ('reconciliation', $1, $2)
+		ON CONFLICT (name) DO UPDATE SET owner = EXCLUDED.owner, expires_at = EXCLUDED.expires_at
+		WHERE reconciliation_lease.expires_at <= $3 OR reconciliation_lease.owner = EXCLUDED.owner
+	`
+	affected, err := r.pg.Exec(ctx, sql, owner, now.Add(lease), now)
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// Unlock devolve o lease de owner; se ele já venceu e foi tomado por outra
+// instância, não faz nada.
+func (r *ReconciliationRepository) Unlock(ctx context.Context, owner string) error {
+	_, err := r.pg.Exec(ctx, `DELETE FROM reconciliation_lease WHERE name = 'reconciliation' AND owner = $1`, owner)
+	return err
+}
+
+func (r *ReconciliationRepository) Insert(ctx context.Context, entry models.ReconciliationEntry) error {
+	sql := `
+		INSERT INTO reconciliation_report (checked_at, processor, kind, window_from, window_to, correlationId,
+			local_requests, remote_requests, local_amount, remote_amount, detail)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+	_, err := r.pg.Exec(ctx, sql,
+		entry.CheckedAt,
+		entry.Processor,
+		entry.Kind,
+		entry.WindowFrom,
+		entry.WindowTo,
+		entry.CorrelationId,
+		entry.LocalRequests,
+		entry.RemoteRequests,
+		entry.LocalAmount,
+		entry.RemoteAmount,
+		entry.Detail,
+	)
+
+	return err
+}
+
+func (r *ReconciliationRepository) List(ctx context.Context, since *time.Time, limit int) (models.ReconciliationReport, error) {
+	query := `
+		SELECT id, checked_at, processor, kind, window_from, window_to, correlationId::text,
+			COALESCE(local_requests, 0), COALESCE(remote_requests, 0),
+			COALESCE(local_amount, 0), COALESCE(remote_amount, 0), detail
+		FROM reconciliation_report
+		WHERE ($1::timestamp IS NULL OR checked_at >= $1)
+		ORDER BY checked_at DESC, id DESC
+		LIMIT $2
+	`
+
+	rows, err := r.pg.Query(ctx, query, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	report := models.ReconciliationReport{}
+	for rows.Next() {
+		var entry models.ReconciliationEntry
+		if err := rows.Scan(&entry.Id, &entry.CheckedAt, &entry.Processor, &entry.Kind, &entry.WindowFrom, &entry.WindowTo,
+			&entry.CorrelationId, &entry.LocalRequests, &entry.RemoteRequests, &entry.LocalAmount, &entry.RemoteAmount,
+			&entry.Detail); err != nil {
+			return nil, err
+		}
+		report = append(report, entry)
+	}
+
+	return report, rows.Err()
+}
@@ -0,0 +1,168 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/models"
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/storage"
+	"github.com/jackc/pgx/v5"
+)
+
+type RefundRepository struct {
+	pg storage.PostgresClient
+}
+
+func NewRefundRepository(pg storage.PostgresClient) *RefundRepository {
+	return &RefundRepository{
+		pg: pg,
+	}
+}
+
+func (r *RefundRepository) Get(ctx context.Context, refundId string) (models.RefundDb, bool, error) {
+	query := `
+		SELECT r.refund_id::text, r.correlationId::text, r.amount, r.processor, r.status, r.reason, r.tenant_id, r.created_at,
+			COALESCE(e.rate, 1), COALESCE(e.processor_currency, '')
+		FROM refund_history r
+		LEFT JOIN entry_history e ON e.correlationId = r.correlationId
+		WHERE r.refund_id = $1
+	`
+
+	var refund models.RefundDb
+	err := r.pg.QueryRow(ctx, query, refundId).Scan(
+		&refund.RefundId,
+		&refund.CorrelationId,
+		&refund.Amount,
+		&refund.Processor,
+		&refund.Status,
+		&refund.Reason,
+		&refund.TenantId,
+		&refund.CreatedAt,
+		&refund.Rate,
+		&refund.ProcessorCurrency,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return refund, false, nil
+	}
+
+	return refund, err == nil, err
+}
+
+// Reserve separa o valor do estorno no saldo do pagamento e registra o estorno
+// como pendente, numa única instrução: o UPDATE em entry_history serializa
+// estornos concorrentes do mesmo pagamento, então a soma nunca passa do valor
+// cobrado. amount zero estorna todo o saldo. Devolve false quando o pagamento
+// não existe (ou é de outro tenant) ou o saldo não comporta o estorno.
+func (r *RefundRepository) Reserve(ctx context.Context, refund models.RefundDb) (models.RefundDb, bool, error) {
+	query := `
+		WITH target AS (
+			SELECT correlationId, CASE WHEN $3::decimal > 0 THEN $3::decimal ELSE amount - refunded_amount END AS delta
+			FROM entry_history
+			WHERE correlationId = $2::uuid AND ($4 = '' OR tenant_id = $4)
+		), reserved AS (
+			UPDATE entry_history e
+			SET refunded_amount = e.refunded_amount + t.delta
+			FROM target t
+			WHERE e.correlationId = t.correlationId AND t.delta > 0 AND e.refunded_amount + t.delta <= e.amount
+			RETURNING e.correlationId, e.processor, e.tenant_id, e.rate, e.processor_currency, t.delta
+		), inserted AS (
+			INSERT INTO refund_history (refund_id, correlationId, amount, processor, status, tenant_id, created_at)
+			SELECT $1::uuid, correlationId, delta, processor, 'pending', tenant_id, $5 FROM reserved
+			RETURNING amount, processor, tenant_id
+		)
+		SELECT i.amount, i.processor, i.tenant_id, r.rate, r.processor_currency
+		FROM inserted i, reserved r
+	`
+
+	err := r.pg.QueryRow(ctx, query, refund.RefundId, refund.CorrelationId, refund.Amount, refund.TenantId, refund.CreatedAt).
+		Scan(&refund.Amount, &refund.Processor, &refund.TenantId, &refund.Rate, &refund.ProcessorCurrency)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return refund, false, nil
+	}
+	if err != nil {
+		return refund, false, err
+	}
+
+	refund.Status = models.RefundPending
+	return refund, true, nil
+}
+
+// Reject registra um estorno que não pôde ser reservado.
+func (r *RefundRepository) Reject(ctx context.Context, refund models.RefundDb) error {
+	sql := `
+		INSERT INTO refund_history (refund_id, correlationId, amount, processor, status, reason, tenant_id, created_at)
+		VALUES ($1::uuid, $2::uuid, $3, $4, 'failed', $5, $6, $7)
+		ON CONFLICT (refund_id) DO NOTHING
+	`
+	_, err := r.pg.Exec(ctx, sql,
+		refund.RefundId,
+		refund.CorrelationId,
+		refund.Amount,
+		refund.Processor,
+		refund.Reason,
+		refund.TenantId,
+		refund.CreatedAt,
+	)
+
+	return err
+}
+
+func (r *RefundRepository) Complete(ctx context.Context, refundId string, processedAt time.Time) error {
+	sql := `
+		UPDATE refund_history SET status = 'refunded', processed_at = $2
+		WHERE refund_id = $1 AND status = 'pending'
+	`
+	_, err := r.pg.Exec(ctx, sql, refundId, processedAt)
+	return err
+}
+
+// Fail marca o estorno pendente como falho e devolve o valor ao saldo.
+func (r *RefundRepository) Fail(ctx context.Context, refundId, reason string) error {
+	sql := `
+		WITH failed AS (
+			UPDATE refund_history SET status = 'failed', reason = $2
+			WHERE refund_id = $1 AND status = 'pending'
+			RETURNING correlationId, amount
+		)
+		UPDATE entry_history e
+		SET refunded_amount = e.refunded_amount - f.amount
+		FROM failed f
+		WHERE e.correlationId = f.correlationId
+	`
+	_, err := r.pg.Exec(ctx, sql, refundId, reason)
+	return err
+}
+
+// RefundedByProcessor soma os estornos concluídos no período, pelo instante
+// do estorno, na moeda base; tenantId vazio soma todos os tenants.
+func (r *RefundRepository) RefundedByProcessor(ctx context.Context, tenantId string, from, to *time.Time) (map[string]float64, error) {
+	query := `
+		SELECT r.processor, SUM(ROUND(r.amount * COALESCE(e.base_rate, 1), 2))
+		FROM refund_history r
+		LEFT JOIN entry_history e ON e.correlationId = r.correlationId
+		WHERE r.status = 'refunded'
+			AND ($1::timestamp IS NULL OR r.processed_at >= $1)
+			AND ($2::timestamp IS NULL OR r.processed_at <= $2)
+			AND ($3 = '' OR r.tenant_id = $3)
+		GROUP BY r.processor
+	`
+
+	rows, err := r.pg.Query(ctx, query, from, to, tenantId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	refunded := map[string]float64{}
+	for rows.Next() {
+		var processor string
+		var amount float64
+		if err := rows.Scan(&processor, &amount); err != nil {
+			return nil, err
+		}
+		refunded[processor] = amount
+	}
+
+	return refunded, rows.Err()
+}
@@ -0,0 +1,144 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/models"
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/storage"
+	"github.com/jackc/pgx/v5"
+)
+
+type ScheduleRepository struct {
+	pg storage.PostgresClient
+}
+
+func NewScheduleRepository(pg storage.PostgresClient) *ScheduleRepository {
+	return &ScheduleRepository{
+		pg: pg,
+	}
+}
+
+// Insert devolve false quando o correlationId já foi agendado antes.
+func (s *ScheduleRepository) Insert(ctx context.Context, payment models.ScheduledPaymentDb) (bool, error) {
+	sql := `
+		INSERT INTO scheduled_payments (correlationId, amount, tenant_id, scheduled_at, payload, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, 'scheduled', $6, $6)
+		ON CONFLICT (correlationId) DO NOTHING
+	`
+	affected, err := s.pg.Exec(ctx, sql,
+		payment.CorrelationId,
+		payment.Amount,
+		payment.TenantId,
+		payment.ScheduledAt,
+		payment.Payload,
+		payment.CreatedAt,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// ClaimDue marca como liberados até limit agendamentos vencidos e devolve os
+// seus envelopes. SKIP LOCKED garante que cada um é liberado por uma única
+// instância e que um cancelamento em andamento não é atropelado. Um
+// agendamento liberado há mais de lease sem resultado em entry_history ou
+// dead_letter é liberado de novo: a instância que o liberou pode ter caído
+// com ele na fila. Os que já têm resultado passam a completed.
+func (s *ScheduleRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.ScheduledPaymentDb, error) {
+	expired := now.Add(-lease)
+
+	_, err := s.pg.Exec(ctx, `
+		UPDATE scheduled_payments s SET status = 'completed', updated_at = $1
+		WHERE status = 'released' AND updated_at <= $2
+		AND (
+			EXISTS (SELECT 1 FROM entry_history_ids i WHERE i.correlationId = s.correlationId)
+			OR EXISTS (SELECT 1 FROM dead_letter d WHERE d.correlationId = s.correlationId::text)
+		)
+	`, now, expired)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		UPDATE scheduled_payments SET status = 'released', updated_at = $1
+		WHERE correlationId IN (
+			SELECT correlationId FROM scheduled_payments
+			WHERE (status = 'scheduled' AND scheduled_at <= $1)
+			OR (status = 'released' AND updated_at <= $2)
+			ORDER BY scheduled_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING correlationId::text, amount, tenant_id, payload
+	`
+
+	rows, err := s.pg.Query(ctx, query, now, expired, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var due []models.ScheduledPaymentDb
+	for rows.Next() {
+		var payment models.ScheduledPaymentDb
+		if err := rows.Scan(&payment.CorrelationId, &payment.Amount, &payment.TenantId, &payment.Payload); err != nil {
+			return nil, err
+		}
+		due = append(due, payment)
+	}
+
+	return due, rows.Err()
+}
+
+// Unclaim devolve ao agendamento um pagamento que a fila recusou.
+func (s *ScheduleRepository) Unclaim(ctx context.Context, correlationId string) error {
+	sql := `
+		UPDATE scheduled_payments SET status = 'scheduled', updated_at = $2
+		WHERE correlationId = $1 AND status = 'released'
+	`
+	_, err := s.pg.Exec(ctx, sql, correlationId, time.Now().UTC())
+	return err
+}
+
+// Cancel cancela o agendamento se ele ainda não foi liberado; tenantId vazio
+// ignora o tenant.
+func (s *ScheduleRepository) Cancel(ctx context.Context, correlationId, tenantId string) (bool, error) {
+	sql := `
+		UPDATE scheduled_payments SET status = 'cancelled', updated_at = $3
+		WHERE correlationId = $1 AND status = 'scheduled' AND ($2 = '' OR tenant_id = $2)
+	`
+	affected, err := s.pg.Exec(ctx, sql, correlationId, tenantId, time.Now().UTC())
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+func (s *ScheduleRepository) Get(ctx context.Context, correlationId string) (models.ScheduledPaymentDb, bool, error) {
+	query := `
+		SELECT correlationId::text, amount, tenant_id, scheduled_at, status, created_at, updated_at
+		FROM scheduled_payments
+		WHERE correlationId = $1
+	`
+
+	var payment models.ScheduledPaymentDb
+	err := s.pg.QueryRow(ctx, query, correlationId).Scan(
+		&payment.CorrelationId,
+		&payment.Amount,
+		&payment.TenantId,
+		&payment.ScheduledAt,
+		&payment.Status,
+		&payment.CreatedAt,
+		&payment.UpdatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return payment, false, nil
+	}
+
+	return payment, err == nil, err
+}
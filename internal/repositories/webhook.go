@@ -0,0 +1,137 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/models"
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/storage"
+)
+
+type WebhookRepository struct {
+	pg storage.PostgresClient
+}
+
+func NewWebhookRepository(pg storage.PostgresClient) *WebhookRepository {
+	return &WebhookRepository{
+		pg: pg,
+	}
+}
+
+const webhookColumns = `id, event_id::text, event, correlationId::text, url, payload, status, attempts,
+	last_status_code, last_error, tenant_id, next_attempt_at, created_at, delivered_at`
+
+func (w *WebhookRepository) Insert(ctx context.Context, delivery models.WebhookDelivery) error {
+	sql := `
+		INSERT INTO webhook_deliveries (event_id, event, correlationId, url, payload, status, tenant_id, next_attempt_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, 'pending', $6, $7, $7)
+	`
+	_, err := w.pg.Exec(ctx, sql,
+		delivery.EventId,
+		delivery.Event,
+		delivery.CorrelationId,
+		delivery.Url,
+		delivery.Payload,
+		delivery.TenantId,
+		delivery.CreatedAt,
+	)
+
+	return err
+}
+
+// Claim reserva até limit entregas vencidas, empurrando next_attempt_at para
+// now+lease; com SKIP LOCKED, cada entrega é tentada por uma instância só. Se a
+// instância cair no meio da entrega, ela volta a vencer quando o lease acaba.
+func (w *WebhookRepository) Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) (models.WebhookDeliveries, error) {
+	query := `
+		UPDATE webhook_deliveries SET next_attempt_at = $2
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= $1
+			ORDER BY next_attempt_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + webhookColumns
+
+	return w.query(ctx, query, now, now.Add(lease), limit)
+}
+
+// Delivered, Reschedule e Fail registram o resultado de uma tentativa.
+func (w *WebhookRepository) Delivered(ctx context.Context, id int64, attempts, statusCode int, deliveredAt time.Time) error {
+	sql := `
+		UPDATE webhook_deliveries
+		SET status = 'delivered', attempts = $2, last_status_code = $3, last_error = '', delivered_at = $4
+		WHERE id = $1
+	`
+	_, err := w.pg.Exec(ctx, sql, id, attempts, statusCode, deliveredAt)
+	return err
+}
+
+func (w *WebhookRepository) Reschedule(ctx context.Context, id int64, attempts, statusCode int, lastError string, nextAttemptAt time.Time) error {
+	sql := `
+		UPDATE webhook_deliveries
+		SET attempts = $2, last_status_code = $3, last_error = $4, next_attempt_at = $5
+		WHERE id = $1
+	`
+	_, err := w.pg.Exec(ctx, sql, id, attempts, statusCode, lastError, nextAttemptAt)
+	return err
+}
+
+func (w *WebhookRepository) Fail(ctx context.Context, id int64, attempts, statusCode int, lastError string) error {
+	sql := `
+		UPDATE webhook_deliveries
+		SET status = 'failed', attempts = $2, last_status_code = $3, last_error = $4
+		WHERE id = $1
+	`
+	_, err := w.pg.Exec(ctx, sql, id, attempts, statusCode, lastError)
+	return err
+}
+
+// List devolve o log de entregas, mais recentes primeiro; correlationId vazio
+// lista todas.
+func (w *WebhookRepository) List(ctx context.Context, correlationId, status string, limit int) (models.WebhookDeliveries, error) {
+	query := `
+		SELECT ` + webhookColumns + `
+		FROM webhook_deliveries
+		WHERE ($1 = '' OR correlationId = NULLIF($1, '')::uuid) AND ($2 = '' OR status = $2)
+		ORDER BY id DESC
+		LIMIT $3
+	`
+
+	return w.query(ctx, query, correlationId, status, limit)
+}
+
+func (w *WebhookRepository) query(ctx context.Context, query string, args ...any) (models.WebhookDeliveries, error) {
+	rows, err := w.pg.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := models.WebhookDeliveries{}
+	for rows.Next() {
+		var d models.WebhookDelivery
+		if err := rows.Scan(
+			&d.Id,
+			&d.EventId,
+			&d.Event,
+			&d.CorrelationId,
+			&d.Url,
+			&d.Payload,
+			&d.Status,
+			&d.Attempts,
+			&d.LastStatusCode,
+			&d.LastError,
+			&d.TenantId,
+			&d.NextAttemptAt,
+			&d.CreatedAt,
+			&d.DeliveredAt,
+		); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, rows.Err()
+}
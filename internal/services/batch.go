@@ -0,0 +1,214 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Patrignani/patrignani-rinha-backend-go/internal/workers"
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/models"
+)
+
+var (
+	ErrInvalidBatch  = errors.New("lote inválido")
+	ErrBatchTooLarge = errors.New("lote acima do limite de itens")
+)
+
+// SplitBatch entrega a fn cada item de um lote, sem parsear: body é um array
+// JSON ou NDJSON (um pagamento por linha). Os itens são só delimitados aqui e
+// parseados uma única vez ao virar envelope, então o lote nunca vira uma
+// árvore fastjson inteira em memória.
+func SplitBatch(body []byte, maxItems int, fn func(item []byte)) error {
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return fmt.Errorf("%w: corpo vazio", ErrInvalidBatch)
+	}
+
+	count := 0
+	emit := func(item []byte) error {
+		if count++; maxItems > 0 && count > maxItems {
+			return fmt.Errorf("%w (%d)", ErrBatchTooLarge, maxItems)
+		}
+		fn(item)
+		return nil
+	}
+
+	if body[0] != '[' {
+		for len(body) > 0 {
+			var line []byte
+			line, body, _ = bytes.Cut(body, []byte("\n"))
+			if line = bytes.TrimSpace(line); len(line) > 0 {
+				if err := emit(line); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	depth, start := 0, -1
+	inString, escaped := false, false
+	for i := 1; i < len(body); i++ {
+		c := body[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch c {
+		case ' ', '\t', '\r', '\n':
+		case '"':
+			inString = true
+			if start < 0 {
+				start = i
+			}
+		case '{', '[':
+			depth++
+			if start < 0 {
+				start = i
+			}
+		case '}', ']':
+			if depth > 0 {
+				depth--
+				continue
+			}
+			if c == '}' || len(bytes.TrimSpace(body[i+1:])) > 0 {
+				return fmt.Errorf("%w: array malformado", ErrInvalidBatch)
+			}
+			if start >= 0 {
+				return emit(bytes.TrimSpace(body[start:i]))
+			}
+			if count > 0 {
+				return fmt.Errorf("%w: item vazio", ErrInvalidBatch)
+			}
+			return nil
+		case ',':
+			if depth > 0 {
+				continue
+			}
+			if start < 0 {
+				return fmt.Errorf("%w: item vazio", ErrInvalidBatch)
+			}
+			if err := emit(bytes.TrimSpace(body[start:i])); err != nil {
+				return err
+			}
+			start = -1
+		default:
+			if start < 0 {
+				start = i
+			}
+		}
+	}
+
+	return fmt.Errorf("%w: array não terminado", ErrInvalidBatch)
+}
+
+// ValidateBatchItem aplica a um item de lote as regras que o POST /payments
+// deixa para o processador, já que um lote é aceito ou recusado inteiro.
+func ValidateBatchItem(msg *workers.Envelope) error {
+	if !isUUID(msg.CorrelationId) {
+		return errors.New("correlationId deve ser um UUID")
+	}
+	if msg.Amount <= 0 {
+		return errors.New("amount deve ser maior que zero")
+	}
+	if !msg.ScheduledAt.IsZero() {
+		return errors.New("scheduledAt não é aceito em lotes")
+	}
+	if msg.CallbackUrl != "" {
+		return ValidateCallbackURL(msg.CallbackUrl)
+	}
+	return nil
+}
+
+// SubmitBatch grava o lote e enfileira msgs de uma vez. Com erro, nenhuma
+// mensagem entrou na fila e as referências continuam com quem chamou; um
+// correlationId em andamento para outro tenant recusa o lote inteiro com
+// ErrCorrelationConflict.
+func (p *PaymentService) SubmitBatch(ctx context.Context, msgs []*workers.Envelope, tenantId string) (string, error) {
+	batch := models.BatchDb{
+		BatchId:        newUUID(),
+		TenantId:       tenantId,
+		CorrelationIds: make([]string, len(msgs)),
+		CreatedAt:      time.Now().UTC(),
+	}
+	for i, msg := range msgs {
+		batch.CorrelationIds[i] = strings.Clone(msg.CorrelationId)
+	}
+
+	// Só sai do Tracker o que este lote colocou lá; um item já em andamento
+	// continua com o estado dele.
+	var created []string
+	untrack := func() {
+		for _, id := range created {
+			p.Untrack(id)
+		}
+	}
+	for i, msg := range msgs {
+		ok, err := p.TrackQueued(msg)
+		if err != nil {
+			untrack()
+			return "", err
+		}
+		if ok {
+			created = append(created, batch.CorrelationIds[i])
+		}
+	}
+
+	if err := p.batches.Insert(ctx, batch); err != nil {
+		untrack()
+		return "", err
+	}
+
+	if err := p.queue.SendBatch(msgs); err != nil {
+		untrack()
+		go func() {
+			if err := p.batches.Delete(context.WithoutCancel(ctx), batch.BatchId); err != nil {
+				println(fmt.Sprintf("Erro ao remover lote recusado %s: %v", batch.BatchId, err))
+			}
+		}()
+		return "", err
+	}
+
+	return batch.BatchId, nil
+}
+
+// GetBatch junta o estado gravado de cada item com o Tracker, para os que
+// ainda estão na fila desta instância; os demais constam como queued.
+func (p *PaymentService) GetBatch(ctx context.Context, batchId, tenantId string) (models.BatchStatus, bool, error) {
+	if !isUUID(batchId) {
+		return models.BatchStatus{}, false, nil
+	}
+
+	status, found, err := p.batches.Get(ctx, batchId, tenantId)
+	if err != nil || !found {
+		return status, found, err
+	}
+
+	status.Total = len(status.Items)
+	status.Counts = map[string]int{}
+	for i := range status.Items {
+		item := &status.Items[i]
+		if item.Status == "" {
+			item.Status = models.PaymentQueued
+			if tracked, ok := p.tracker.Get(item.CorrelationId, ""); ok {
+				item.Status = tracked.Status
+				item.Processor = tracked.Processor
+				item.Reason = tracked.Reason
+			}
+		}
+		status.Counts[item.Status]++
+	}
+
+	return status, true, nil
+}
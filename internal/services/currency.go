@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sync/atomic"
+
+	"github.com/Patrignani/patrignani-rinha-backend-go/internal/repositories"
+	"github.com/Patrignani/patrignani-rinha-backend-go/internal/workers"
+	"github.com/valyala/fastjson"
+)
+
+var ErrUnknownCurrency = errors.New("moeda desconhecida")
+
+// Quote fica no envelope para que a cotação de uma chamada com resultado
+// desconhecido sobreviva ao retry e ao backend durável.
+type Quote = workers.Quote
+
+// RateService guarda as cotações em memória, em unidades da moeda base por
+// unidade de cada moeda. Refresh troca o mapa inteiro, então as leituras no
+// loop do gnet e nos workers nunca esperam o banco.
+type RateService struct {
+	repo  *repositories.RateRepository
+	file  string
+	base  string
+	rates atomic.Pointer[map[string]float64]
+}
+
+// NewRateService lê as cotações de file (JSON {"USD": 5.1}) quando informado;
+// senão, da tabela currency_rates.
+func NewRateService(repo *repositories.RateRepository, file, base string) *RateService {
+	r := &RateService{repo: repo, file: file, base: base}
+	r.rates.Store(&map[string]float64{base: 1})
+	return r
+}
+
+// Base devolve a moeda em que os resumos são totalizados.
+func (r *RateService) Base() string {
+	return r.base
+}
+
+// Code devolve a moeda de um pagamento; vazia é a moeda base.
+func (r *RateService) Code(currency string) string {
+	if currency == "" {
+		return r.base
+	}
+	return currency
+}
+
+// Refresh recarrega as cotações; deve rodar via StartWorker. Um arquivo
+// inválido mantém as cotações anteriores; linhas inválidas da tabela são
+// ignoradas. A moeda base vale sempre 1.
+func (r *RateService) Refresh(ctx context.Context) error {
+	var rates map[string]float64
+	var err error
+	if r.file != "" {
+		rates, err = loadRatesFile(r.file)
+	} else {
+		rates, err = r.repo.List(ctx)
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao carregar cotações: %w", err)
+	}
+
+	for currency, rate := range rates {
+		if !isCurrencyCode(currency) || !(rate > 0) || math.IsInf(rate, 1) {
+			println(fmt.Sprintf("Cotação inválida ignorada: %s=%v", currency, rate))
+			delete(rates, currency)
+		}
+	}
+	rates[r.base] = 1
+
+	r.rates.Store(&rates)
+	return nil
+}
+
+func (r *RateService) rate(currency string) (float64, bool) {
+	if currency == "" || currency == r.base {
+		return 1, true
+	}
+	rate, ok := (*r.rates.Load())[currency]
+	return rate, ok
+}
+
+// Validate aceita a moeda vazia ou uma moeda com cotação carregada.
+func (r *RateService) Validate(currency string) error {
+	if _, ok := r.rate(currency); !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownCurrency, currency)
+	}
+	return nil
+}
+
+// Quote converte amount, na moeda currency, para target, arredondando em
+// centavos; target vazio é um processador que aceita qualquer moeda.
+func (r *RateService) Quote(currency string, amount float64, target string) (Quote, error) {
+	from, ok := r.rate(currency)
+	if !ok {
+		return Quote{}, fmt.Errorf("%w: %q", ErrUnknownCurrency, currency)
+	}
+
+	quote := Quote{Currency: currency, Amount: amount, Rate: 1, BaseRate: from}
+	if target == "" || target == r.Code(currency) {
+		if target != "" {
+			quote.Currency = target
+		}
+		return quote, nil
+	}
+
+	to, ok := r.rate(target)
+	if !ok {
+		return Quote{}, fmt.Errorf("%w: %q", ErrUnknownCurrency, target)
+	}
+
+	quote.Currency = target
+	quote.Rate = from / to
+	quote.Amount = roundCents(amount * quote.Rate)
+	return quote, nil
+}
+
+// Convert converte um valor na moeda base para currency.
+func (r *RateService) Convert(amount float64, currency string) (float64, error) {
+	rate, ok := r.rate(currency)
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrUnknownCurrency, currency)
+	}
+	return roundCents(amount / rate), nil
+}
+
+func loadRatesFile(path string) (map[string]float64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var parser fastjson.Parser
+	v, err := parser.ParseBytes(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	obj, err := v.Object()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	rates := make(map[string]float64, obj.Len())
+	obj.Visit(func(key []byte, v *fastjson.Value) {
+		if err != nil {
+			return
+		}
+		var rate float64
+		if rate, err = v.Float64(); err != nil {
+			err = fmt.Errorf("%s: cotação de %s: %w", path, key, err)
+			return
+		}
+		rates[string(key)] = rate
+	})
+
+	return rates, err
+}
+
+// isCurrencyCode confere o formato ISO 4217: três letras maiúsculas.
+func isCurrencyCode(s string) bool {
+	if len(s) != 3 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < 'A' || s[i] > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+func roundCents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
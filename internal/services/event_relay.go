@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Patrignani/patrignani-rinha-backend-go/internal/events"
+	"github.com/Patrignani/patrignani-rinha-backend-go/internal/repositories"
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/models"
+)
+
+// eventPage é quanto cada leitura de payment_events traz de uma vez.
+const eventPage = 500
+
+// EventRelay leva os eventos de cada instância para payment_events e de lá
+// para o Broker de todas elas, então um stream mostra os pagamentos das duas
+// instâncias e um Last-Event-ID vale em qualquer uma.
+type EventRelay struct {
+	repo    *repositories.EventRepository
+	broker  *events.Broker
+	pending chan events.Event
+	dropped atomic.Int64
+
+	mu     sync.Mutex
+	lastId uint64
+	primed bool
+	size   int
+}
+
+// NewEventRelay devolve nil sem Broker; todos os métodos aceitam um
+// EventRelay nil.
+func NewEventRelay(repo *repositories.EventRepository, broker *events.Broker, size int) *EventRelay {
+	if broker == nil {
+		return nil
+	}
+	return &EventRelay{repo: repo, broker: broker, pending: make(chan events.Event, max(size, eventPage)), size: size}
+}
+
+// Publish entrega o evento para Flush gravar; não bloqueia, e com a fila
+// cheia o evento é descartado. Assume a posse de Data.
+func (r *EventRelay) Publish(e events.Event) {
+	if r == nil || e.Type == "" {
+		return
+	}
+
+	select {
+	case r.pending <- e:
+	default:
+		r.dropped.Add(1)
+	}
+}
+
+// Broadcast entrega o evento só às assinaturas desta instância, sem gravá-lo.
+func (r *EventRelay) Broadcast(e events.Event) {
+	if r == nil {
+		return
+	}
+	r.broker.Broadcast(e)
+}
+
+func (r *EventRelay) Subscribers() int {
+	if r == nil {
+		return 0
+	}
+	return r.broker.Subscribers()
+}
+
+// Flush grava os eventos pendentes; deve rodar via StartWorker.
+func (r *EventRelay) Flush(ctx context.Context) error {
+	if dropped := r.dropped.Swap(0); dropped > 0 {
+		log.Printf("%d eventos descartados: fila de gravação cheia", dropped)
+	}
+
+	for {
+		records := r.take(eventPage)
+		if len(records) == 0 {
+			return nil
+		}
+		if err := r.repo.Append(ctx, records); err != nil {
+			return err
+		}
+	}
+}
+
+// take tira até n eventos pendentes, sem esperar.
+func (r *EventRelay) take(n int) []models.EventRecord {
+	var records []models.EventRecord
+	for len(records) < n {
+		select {
+		case e := <-r.pending:
+			records = append(records, models.EventRecord{
+				Type:          e.Type,
+				Processor:     e.Processor,
+				CorrelationId: e.CorrelationId,
+				TenantId:      e.TenantId,
+				Data:          string(e.Data),
+			})
+		default:
+			return records
+		}
+	}
+	return records
+}
+
+// Listen lê os eventos gravados por qualquer instância a cada NOTIFY; deve
+// rodar via StartWorker, que o chama de novo quando a conexão cai. Cada
+// chamada começa lendo o que foi gravado enquanto ela estava fora.
+func (r *EventRelay) Listen(ctx context.Context) error {
+	if err := r.pull(ctx); err != nil {
+		return err
+	}
+
+	return r.repo.Listen(ctx, func() {
+		if err := r.pull(ctx); err != nil {
+			log.Printf("Erro ao ler eventos: %v", err)
+		}
+	})
+}
+
+// pull publica no Broker os eventos depois do último lido. Na primeira vez
+// começa pelos últimos size, que preenchem o log de retomada.
+func (r *EventRelay) pull(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.primed {
+		lastId, err := r.repo.LastId(ctx)
+		if err != nil {
+			return err
+		}
+		r.lastId = lastId - min(lastId, uint64(r.size))
+		r.primed = true
+	}
+
+	for {
+		records, err := r.repo.After(ctx, r.lastId, 0, eventPage)
+		if err != nil {
+			return err
+		}
+		for _, record := range records {
+			r.broker.Publish(toEvent(record))
+			r.lastId = record.Id
+		}
+		if len(records) < eventPage {
+			return nil
+		}
+	}
+}
+
+// Subscribe assina o Broker e, quando parte dos eventos depois de lastId já
+// saiu do log em memória, completa o backlog com até size deles lidos de
+// payment_events. gap indica que nem assim o backlog ficou completo. Roda
+// fora do loop do gnet.
+func (r *EventRelay) Subscribe(ctx context.Context, filter events.Filter, lastId uint64, resume bool) (*events.Subscription, []events.Event, bool) {
+	sub, backlog, oldest := r.broker.Subscribe(filter, lastId, resume)
+	if oldest == 0 {
+		return sub, backlog, false
+	}
+
+	records, err := r.repo.After(ctx, lastId, oldest, r.size)
+	if err != nil {
+		log.Printf("Erro ao ler eventos para retomada: %v", err)
+		return sub, backlog, true
+	}
+
+	missed := make([]events.Event, 0, len(records)+len(backlog))
+	for _, record := range records {
+		if e := toEvent(record); filter.Match(&e) {
+			missed = append(missed, e)
+		}
+	}
+	return sub, append(missed, backlog...), len(records) == r.size
+}
+
+func (r *EventRelay) Unsubscribe(sub *events.Subscription) {
+	r.broker.Unsubscribe(sub)
+}
+
+// Prune remove de payment_events o que tem mais de retention; deve rodar via
+// StartWorker.
+func (r *EventRelay) Prune(ctx context.Context, retention time.Duration) error {
+	_, err := r.repo.Prune(ctx, time.Now().UTC().Add(-retention))
+	return err
+}
+
+func toEvent(record models.EventRecord) events.Event {
+	return events.Event{
+		Id:            record.Id,
+		Type:          record.Type,
+		Processor:     record.Processor,
+		CorrelationId: record.CorrelationId,
+		TenantId:      record.TenantId,
+		Data:          []byte(record.Data),
+	}
+}
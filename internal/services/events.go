@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Patrignani/patrignani-rinha-backend-go/internal/events"
+	"github.com/Patrignani/patrignani-rinha-backend-go/internal/workers"
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/models"
+)
+
+// paymentEvent monta o evento enquanto msg ainda é válido; com os eventos
+// desligados devolve o zero, que Publish ignora.
+func (p *PaymentService) paymentEvent(eventType string, msg *workers.Envelope, processor, reason string) events.Event {
+	if p.events == nil {
+		return events.Event{}
+	}
+
+	correlationId := strings.Clone(msg.CorrelationId)
+	data, err := (&models.PaymentEvent{
+		CorrelationId: correlationId,
+		Amount:        msg.Amount,
+		Currency:      msg.Currency,
+		Processor:     processor,
+		Reason:        reason,
+		Attempts:      msg.Attempts,
+		TenantId:      msg.TenantId,
+		At:            time.Now().UTC(),
+	}).MarshalJSON()
+	if err != nil {
+		println(fmt.Sprintf("Erro ao montar evento %s: %v", eventType, err))
+		return events.Event{}
+	}
+
+	return events.Event{
+		Type:          eventType,
+		Processor:     processor,
+		CorrelationId: correlationId,
+		TenantId:      msg.TenantId,
+		Data:          data,
+	}
+}
+
+// AcceptedEvent é publicado pelo servidor, com Publish, depois que a fila
+// aceita msg; precisa ser montado antes, pois msg pode já ter sido liberado.
+func (p *PaymentService) AcceptedEvent(msg *workers.Envelope) events.Event {
+	return p.paymentEvent(events.TypePaymentAccepted, msg, "", "")
+}
+
+func (p *PaymentService) Publish(event events.Event) {
+	p.events.Publish(event)
+}
+
+// PublishSummary publica o resumo de todos os tenants como summary.tick; deve
+// rodar via StartWorker e não consulta o banco se não houver assinantes.
+func (p *PaymentService) PublishSummary(ctx context.Context) error {
+	if p.events.Subscribers() == 0 {
+		return nil
+	}
+
+	summary, err := p.GetPaymentSummary(ctx, "", nil, nil, "")
+	if err != nil {
+		return err
+	}
+
+	data, err := summary.MarshalJSON()
+	if err != nil {
+		return err
+	}
+
+	// Cada instância calcula o mesmo resumo do banco: ele não vai para
+	// payment_events.
+	p.events.Broadcast(events.Event{Type: events.TypeSummaryTick, Data: data})
+	return nil
+}
@@ -0,0 +1,184 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Patrignani/patrignani-rinha-backend-go/internal/workers"
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/models"
+)
+
+var (
+	ErrInvalidCursor = errors.New("cursor inválido")
+	// ErrCorrelationConflict recusa um correlationId que já é de outro tenant.
+	ErrCorrelationConflict = errors.New("correlationId já usado por outro tenant")
+)
+
+// TrackQueued registra o pagamento como na fila; deve ser chamado antes do
+// envio, para que o worker nunca veja um estado anterior ao dele. Recusa com
+// ErrCorrelationConflict um correlationId em andamento para outro tenant.
+func (p *PaymentService) TrackQueued(msg *workers.Envelope) (bool, error) {
+	return p.tracker.Queued(msg.CorrelationId, msg.TenantId, msg.Amount, msg.RequestedAt)
+}
+
+// Untrack desfaz TrackQueued quando a fila recusa a mensagem.
+func (p *PaymentService) Untrack(correlationId string) {
+	p.tracker.Forget(correlationId)
+}
+
+// GetPayment junta o estado em memória (fila, processamento e tentativas) com
+// o que já foi gravado em entry_history ou em dead_letter. tenantId vazio
+// ignora o tenant.
+func (p *PaymentService) GetPayment(ctx context.Context, correlationId, tenantId string) (models.PaymentStatus, bool, error) {
+	status, tracked := p.tracker.Get(correlationId, tenantId)
+	if status.Attempts == nil {
+		status.Attempts = []models.PaymentAttempt{}
+	}
+
+	if tracked && status.Status != models.PaymentProcessed && status.Status != models.PaymentDeadLettered {
+		return status, true, nil
+	}
+
+	if isUUID(correlationId) {
+		payment, found, err := p.repo.Get(ctx, correlationId)
+		if err != nil {
+			return status, false, err
+		}
+
+		if found && (tenantId == "" || payment.TenantId == tenantId) {
+			processedAt := payment.CreatedAt
+			status.CorrelationId = payment.CorrelationId
+			status.Status = models.PaymentProcessed
+			status.Amount = payment.Amount
+			status.Processor = payment.Processor
+			status.Strategy = payment.Strategy
+			status.TenantId = payment.TenantId
+			status.ProcessedAt = &processedAt
+			if status.RequestedAt == nil {
+				status.RequestedAt = &processedAt
+			}
+			if !tracked {
+				status.UpdatedAt = processedAt
+			}
+			return status, true, nil
+		}
+	}
+
+	entry, found, err := p.deadLetters.GetLatest(ctx, correlationId)
+	if err != nil {
+		return status, false, err
+	}
+
+	if found && (tenantId == "" || entry.TenantId == tenantId) {
+		status.CorrelationId = entry.CorrelationId
+		status.Status = models.PaymentDeadLettered
+		status.Amount = entry.Amount
+		status.Processor = entry.Processor
+		status.TenantId = entry.TenantId
+		status.Reason = entry.Reason
+		if !tracked {
+			status.UpdatedAt = entry.CreatedAt
+		}
+		return status, true, nil
+	}
+
+	if !tracked && isUUID(correlationId) {
+		scheduled, found, err := p.schedules.Get(ctx, correlationId)
+		if err != nil {
+			return status, false, err
+		}
+
+		if found && (tenantId == "" || scheduled.TenantId == tenantId) {
+			scheduledAt := scheduled.ScheduledAt
+			status.CorrelationId = scheduled.CorrelationId
+			status.Status = scheduled.Status
+			if scheduled.Status == models.ScheduleReleased {
+				// Liberado por outra instância e ainda não gravado.
+				status.Status = models.PaymentQueued
+			}
+			status.Amount = scheduled.Amount
+			status.TenantId = scheduled.TenantId
+			status.RequestedAt = &scheduledAt
+			status.ScheduledAt = &scheduledAt
+			status.UpdatedAt = scheduled.UpdatedAt
+			return status, true, nil
+		}
+	}
+
+	// Ainda não gravado: o insert é assíncrono.
+	return status, tracked, nil
+}
+
+// ListPayments entrega a fn os pagamentos gravados (processed) ou em
+// dead-letter que passam pelo filtro, cada um com o seu cursor.
+func (p *PaymentService) ListPayments(ctx context.Context, filter models.PaymentFilter, fn func(models.PaymentRecord) error) error {
+	if filter.After != nil && filter.Status != models.PaymentDeadLettered && !isUUID(filter.After.CorrelationId) {
+		return ErrInvalidCursor
+	}
+
+	emit := func(record models.PaymentRecord) error {
+		record.Cursor = EncodeCursor(models.PaymentCursor{CreatedAt: record.CreatedAt, CorrelationId: record.CorrelationId})
+		return fn(record)
+	}
+
+	switch filter.Status {
+	case "", models.PaymentProcessed:
+		return p.repo.Stream(ctx, filter, emit)
+	case models.PaymentDeadLettered:
+		return p.deadLetters.Stream(ctx, filter, emit)
+	}
+
+	return fmt.Errorf("status %q não é listável: só %s e %s ficam gravados", filter.Status, models.PaymentProcessed, models.PaymentDeadLettered)
+}
+
+// EncodeCursor gera o cursor opaco usado em "after": base64 de
+// "<created_at em ns Unix>:<correlationId>".
+func EncodeCursor(cursor models.PaymentCursor) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(cursor.CreatedAt.UnixNano(), 10) + ":" + cursor.CorrelationId))
+}
+
+func ParseCursor(s string) (models.PaymentCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return models.PaymentCursor{}, ErrInvalidCursor
+	}
+
+	nanos, id, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return models.PaymentCursor{}, ErrInvalidCursor
+	}
+
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return models.PaymentCursor{}, ErrInvalidCursor
+	}
+
+	return models.PaymentCursor{CreatedAt: time.Unix(0, n).UTC(), CorrelationId: id}, nil
+}
+
+// isUUID evita consultar entry_history, cuja chave é UUID, com ids que o
+// Postgres recusaria.
+func isUUID(id string) bool {
+	if len(id) != 36 {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		switch c := id[i]; {
+		case i == 8 || i == 13 || i == 18 || i == 23:
+			if c != '-' {
+				return false
+			}
+		case '0' <= c && c <= '9', 'a' <= c && c <= 'f', 'A' <= c && c <= 'F':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
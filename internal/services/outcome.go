@@ -0,0 +1,195 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/Patrignani/patrignani-rinha-backend-go/internal/processors"
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/config"
+)
+
+type outcome int
+
+const (
+	outcomeFailed outcome = iota
+	outcomeCharged
+	outcomeRejected
+	outcomeUnknown
+)
+
+var errPendingOutcome = errors.New("resultado pendente de uma tentativa anterior")
+
+var errMessageExpired = errors.New("mensagem descartada: prazo expirado")
+
+// UnknownOutcomeError indica que não dá para saber se o processador cobrou o
+// pagamento (timeout ou conexão perdida depois do envio) e que a consulta ao
+// processador também não resolveu.
+type UnknownOutcomeError struct {
+	Processor string
+	Err       error
+	// Quote é a cotação enviada na chamada, que vale na gravação se uma
+	// consulta posterior confirmar a cobrança.
+	Quote Quote
+}
+
+func (e *UnknownOutcomeError) Error() string {
+	return fmt.Sprintf("resultado desconhecido em %s: %v", e.Processor, e.Err)
+}
+
+func (e *UnknownOutcomeError) Unwrap() error {
+	return e.Err
+}
+
+// RejectedError indica que o processador recusou o pagamento de vez (422 que
+// não é duplicidade) ou que não há como saber dele o resultado; a mensagem
+// vai para o dead-letter em vez de retry.
+type RejectedError struct {
+	Processor string
+	Reason    string
+}
+
+func (e *RejectedError) Error() string {
+	return fmt.Sprintf("pagamento recusado por %s: %s", e.Processor, e.Reason)
+}
+
+// stopsPlan diz se err encerra o plano: sem failover nem hedge depois dele.
+func stopsPlan(err error) bool {
+	var unknown *UnknownOutcomeError
+	var rejected *RejectedError
+	return errors.As(err, &unknown) || errors.As(err, &rejected)
+}
+
+// attempt converte amount, na moeda currency, para a moeda do processador e
+// devolve a cotação usada junto com o resultado. callTimeout limita só a
+// chamada de pagamento; estourado, ele é um resultado ambíguo como qualquer
+// timeout e passa pela consulta ao processador. Sem cotação a tentativa
+// falha sem ser recusada: a cotação pode voltar no próximo Refresh, e outro
+// processador pode aceitar a moeda.
+func (p *PaymentService) attempt(ctx context.Context, processor processors.Processor, correlationId, currency string, amount float64, createdAt time.Time, callTimeout time.Duration) (outcome, Quote, error) {
+	quote, err := p.rates.Quote(currency, amount, processor.Currency())
+	if err != nil {
+		return outcomeFailed, quote, err
+	}
+
+	quote.RequestedAt = createdAt
+
+	callCtx := ctx
+	if callTimeout > 0 {
+		var cancel context.CancelFunc
+		callCtx, cancel = context.WithTimeout(ctx, callTimeout)
+		defer cancel()
+	}
+
+	statusCode, err := p.call(callCtx, processor, correlationId, quote)
+	if err != nil {
+		if processors.IsAmbiguous(err) {
+			out, confirmedAt, err := p.resolve(ctx, processor, correlationId, err)
+			if !confirmedAt.IsZero() {
+				quote.RequestedAt = confirmedAt
+			}
+			return out, quote, withQuote(err, quote)
+		}
+		return outcomeFailed, quote, err
+	}
+
+	switch {
+	case statusCode >= 200 && statusCode < 300:
+		return outcomeCharged, quote, nil
+	case statusCode == 422:
+		out, confirmedAt, err := p.classifyConflict(ctx, processor, correlationId, quote.Amount)
+		if !confirmedAt.IsZero() {
+			quote.RequestedAt = confirmedAt
+		}
+		return out, quote, withQuote(err, quote)
+	}
+
+	return outcomeFailed, quote, fmt.Errorf("HTTP status fora da faixa 2xx: %d", statusCode)
+}
+
+// withQuote guarda em um UnknownOutcomeError a cotação enviada.
+func withQuote(err error, quote Quote) error {
+	var unknown *UnknownOutcomeError
+	if errors.As(err, &unknown) {
+		unknown.Quote = quote
+	}
+	return err
+}
+
+// resolve consulta GET /payments/{id} no processador para decidir um resultado
+// ambíguo e, se cobrado, devolve o requestedAt que o processador registrou.
+// Usa um contexto próprio porque o deadline da mensagem normalmente já
+// expirou quando se chega aqui. Um único 404 não basta, pois o processador
+// pode ainda não ter gravado o pagamento: só conta como não cobrado quando
+// continua ausente por PAYMENT_LOOKUP_GRACE. Sem consulta no processador a
+// dúvida nunca se resolve, então a mensagem vai direto para o dead-letter.
+func (p *PaymentService) resolve(ctx context.Context, processor processors.Processor, correlationId string, cause error) (outcome, time.Time, error) {
+	lookupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), config.Env.Payment.LookupTimeout)
+	defer cancel()
+
+	var notFoundSince time.Time
+	for i := 0; i < config.Env.Payment.LookupAttempts; i++ {
+		if i > 0 {
+			select {
+			case <-lookupCtx.Done():
+				return outcomeUnknown, time.Time{}, &UnknownOutcomeError{Processor: processor.Name(), Err: cause}
+			case <-time.After(config.Env.Payment.LookupInterval):
+			}
+		}
+
+		payment, found, err := processor.Lookup(lookupCtx, correlationId)
+		if errors.Is(err, processors.ErrLookupUnsupported) {
+			return outcomeRejected, time.Time{}, &RejectedError{Processor: processor.Name(), Reason: "resultado desconhecido sem consulta disponível no processador; conferir na reconciliação"}
+		}
+		if err != nil {
+			println(fmt.Sprintf("Erro ao consultar pagamento %s em %s: %v", correlationId, processor.Name(), err))
+			continue
+		}
+
+		if found {
+			return outcomeCharged, payment.RequestedAt, nil
+		}
+
+		if now := time.Now(); notFoundSince.IsZero() {
+			notFoundSince = now
+		} else if now.Sub(notFoundSince) >= config.Env.Payment.LookupGrace {
+			return outcomeFailed, time.Time{}, cause
+		}
+	}
+
+	return outcomeUnknown, time.Time{}, &UnknownOutcomeError{Processor: processor.Name(), Err: cause}
+}
+
+// classifyConflict trata o 422: normalmente o correlationId já foi processado
+// (por exemplo, por uma tentativa anterior que deu timeout). Se o processador
+// confirmar o pagamento com o mesmo valor (na moeda do processador), ele conta
+// como cobrado; caso contrário é um erro de validação de verdade. Sem consulta
+// no processador não há como decidir, e repetir só recebe outro 422: a
+// mensagem vai para o dead-letter e a reconciliação confere.
+func (p *PaymentService) classifyConflict(ctx context.Context, processor processors.Processor, correlationId string, amount float64) (outcome, time.Time, error) {
+	lookupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), config.Env.Payment.LookupTimeout)
+	defer cancel()
+
+	payment, found, err := processor.Lookup(lookupCtx, correlationId)
+	if errors.Is(err, processors.ErrLookupUnsupported) {
+		return outcomeRejected, time.Time{}, &RejectedError{Processor: processor.Name(), Reason: "422 sem consulta disponível no processador; conferir na reconciliação"}
+	}
+	if err != nil {
+		return outcomeUnknown, time.Time{}, &UnknownOutcomeError{Processor: processor.Name(), Err: fmt.Errorf("422 sem confirmação: %w", err)}
+	}
+
+	if !found {
+		return outcomeRejected, time.Time{}, &RejectedError{Processor: processor.Name(), Reason: "422 sem pagamento registrado no processador"}
+	}
+
+	if math.Abs(payment.Amount-amount) >= amountTolerance {
+		return outcomeRejected, time.Time{}, &RejectedError{
+			Processor: processor.Name(),
+			Reason:    fmt.Sprintf("correlationId já usado com valor %.2f", payment.Amount),
+		}
+	}
+
+	return outcomeCharged, payment.RequestedAt, nil
+}
@@ -0,0 +1,194 @@
+package services
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Patrignani/patrignani-rinha-backend-go/internal/repositories"
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/config"
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/models"
+)
+
+// Archiver recebe cada partição vencida já desanexada de entry_history, antes
+// do DROP; com erro, a partição fica para a próxima rodada.
+type Archiver interface {
+	Archive(ctx context.Context, partition models.EntryPartition) error
+}
+
+// PartitionService mantém as partições de entry_history: cria as próximas
+// PARTITION_AHEAD com antecedência e tira as que terminaram há mais de
+// PARTITION_RETENTION.
+type PartitionService struct {
+	repo      *repositories.PartitionRepository
+	step      time.Duration
+	layout    string
+	ahead     int
+	retention time.Duration
+	drop      bool
+	archiver  Archiver
+}
+
+// NewPartitionService aceita PARTITION_PERIOD day ou hour; archiver pode ser
+// nil.
+func NewPartitionService(repo *repositories.PartitionRepository, cfg config.Partition, archiver Archiver) (*PartitionService, error) {
+	s := &PartitionService{repo: repo, ahead: max(cfg.Ahead, 0), retention: cfg.Retention, drop: cfg.Drop, archiver: archiver}
+
+	switch cfg.Period {
+	case "day":
+		s.step, s.layout = 24*time.Hour, "20060102"
+	case "hour":
+		s.step, s.layout = time.Hour, "2006010215"
+	default:
+		return nil, fmt.Errorf("PARTITION_PERIOD inválido: %q", cfg.Period)
+	}
+
+	return s, nil
+}
+
+// Maintain deve rodar via StartWorker. Cada partição vencida é desanexada e
+// só depois arquivada e apagada, então nada gravado nela entre o arquivamento
+// e o DROP se perde; a que falhar no arquivamento fica desanexada e é tentada
+// de novo na próxima rodada.
+func (s *PartitionService) Maintain(ctx context.Context) error {
+	partitions, err := s.repo.List(ctx)
+	if err != nil {
+		return fmt.Errorf("erro ao listar partições: %w", err)
+	}
+
+	create, expire := s.plan(time.Now().UTC(), partitions)
+
+	var errs []error
+	for _, partition := range create {
+		created, err := s.repo.Create(ctx, partition.Name, partition.From, partition.To)
+		if err != nil {
+			errs = append(errs, err)
+			break
+		}
+		if created {
+			log.Printf("[Partitions] criada %s [%s, %s)", partition.Name, partition.From.Format(time.RFC3339), partition.To.Format(time.RFC3339))
+		}
+	}
+
+	for _, partition := range expire {
+		expired, err := s.repo.Expire(ctx, partition)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("partição %s: %w", partition.Name, err))
+		} else if expired {
+			log.Printf("[Partitions] desanexada %s", partition.Name)
+		}
+	}
+
+	expired, err := s.repo.Expired(ctx)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("erro ao listar partições desanexadas: %w", err))
+	}
+	for _, partition := range expired {
+		if err := s.finish(ctx, partition); err != nil {
+			errs = append(errs, fmt.Errorf("partição %s: %w", partition.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// plan devolve as partições a criar e as que terminaram há mais de
+// PARTITION_RETENTION. As novas começam onde a última termina, ou no período
+// atual se ela já ficou para trás, e vão até PARTITION_AHEAD períodos depois
+// do atual; o que cair num buraco entre as duas fica em entry_history_default.
+func (s *PartitionService) plan(now time.Time, partitions []models.EntryPartition) (create, expire []models.EntryPartition) {
+	current := now.Truncate(s.step)
+	start := current
+	for _, partition := range partitions {
+		if partition.To.After(start) {
+			start = partition.To
+		}
+	}
+
+	for end := current.Add(time.Duration(s.ahead+1) * s.step); start.Before(end); {
+		next := start.Truncate(s.step).Add(s.step)
+		create = append(create, models.EntryPartition{Name: "entry_history_p" + start.Format(s.layout), From: start, To: next})
+		start = next
+	}
+
+	if s.retention > 0 {
+		cutoff := now.Add(-s.retention)
+		for _, partition := range partitions {
+			if !partition.To.IsZero() && !partition.To.After(cutoff) {
+				expire = append(expire, partition)
+			}
+		}
+	}
+
+	return create, expire
+}
+
+func (s *PartitionService) finish(ctx context.Context, partition models.EntryPartition) error {
+	var archive func(ctx context.Context) error
+	if s.archiver != nil {
+		archive = func(ctx context.Context) error {
+			return s.archiver.Archive(ctx, partition)
+		}
+	}
+
+	finished, err := s.repo.Finish(ctx, partition.Name, s.drop, archive)
+	if err != nil || !finished {
+		return err
+	}
+
+	if s.drop {
+		log.Printf("[Partitions] removida %s", partition.Name)
+	}
+	return nil
+}
+
+// FileArchiver grava cada partição em dir/<partição>.csv.gz. O arquivo é
+// escrito com outro nome e renomeado no fim, então nunca fica pela metade.
+type FileArchiver struct {
+	repo *repositories.PartitionRepository
+	dir  string
+}
+
+func NewFileArchiver(repo *repositories.PartitionRepository, dir string) (*FileArchiver, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileArchiver{repo: repo, dir: dir}, nil
+}
+
+func (a *FileArchiver) Archive(ctx context.Context, partition models.EntryPartition) error {
+	tmp, err := os.CreateTemp(a.dir, partition.Name+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	gz := gzip.NewWriter(tmp)
+	rows, err := a.repo.Export(ctx, partition.Name, gz)
+	if err != nil {
+		return fmt.Errorf("erro ao exportar: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	path := filepath.Join(a.dir, partition.Name+".csv.gz")
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+
+	log.Printf("[Partitions] %s arquivada em %s (%d linhas)", partition.Name, path, rows)
+	return nil
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/config"
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/models"
+)
+
+func day(d int) time.Time {
+	return time.Date(2025, 7, d, 0, 0, 0, 0, time.UTC)
+}
+
+func partitionNames(partitions []models.EntryPartition) []string {
+	names := make([]string, len(partitions))
+	for i, partition := range partitions {
+		names[i] = partition.Name
+	}
+	return names
+}
+
+func equalNames(got []models.EntryPartition, want ...string) bool {
+	names := partitionNames(got)
+	if len(names) != len(want) {
+		return false
+	}
+	for i := range names {
+		if names[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestPartitionPlanCreatesAheadFromLastPartition(t *testing.T) {
+	s, err := NewPartitionService(nil, config.Partition{Period: "day", Ahead: 2}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := day(10).Add(15*time.Hour + 30*time.Minute)
+	partitions := []models.EntryPartition{
+		{Name: "entry_history_legacy", To: day(9)},
+		{Name: "entry_history_p20250709", From: day(9), To: day(10)},
+		{Name: "entry_history_p20250710", From: day(10), To: day(11)},
+	}
+
+	create, expire := s.plan(now, partitions)
+	if !equalNames(create, "entry_history_p20250711", "entry_history_p20250712") {
+		t.Fatalf("create = %v", partitionNames(create))
+	}
+	if !create[0].From.Equal(day(11)) || !create[0].To.Equal(day(12)) || !create[1].To.Equal(day(13)) {
+		t.Fatalf("intervalos = %+v", create)
+	}
+	if len(expire) != 0 {
+		t.Fatalf("expire sem retenção = %v", partitionNames(expire))
+	}
+}
+
+func TestPartitionPlanStartsAtCurrentPeriodAfterGap(t *testing.T) {
+	s, err := NewPartitionService(nil, config.Partition{Period: "hour", Ahead: 1}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := day(10).Add(13*time.Hour + 59*time.Minute)
+	partitions := []models.EntryPartition{
+		{Name: "entry_history_p2025071008", From: day(10).Add(8 * time.Hour), To: day(10).Add(9 * time.Hour)},
+	}
+
+	create, _ := s.plan(now, partitions)
+	if !equalNames(create, "entry_history_p2025071013", "entry_history_p2025071014") {
+		t.Fatalf("create = %v", partitionNames(create))
+	}
+	if !create[0].From.Equal(day(10).Add(13*time.Hour)) || !create[1].To.Equal(day(10).Add(15*time.Hour)) {
+		t.Fatalf("intervalos = %+v", create)
+	}
+}
+
+func TestPartitionPlanExpiresByEndAndRetention(t *testing.T) {
+	s, err := NewPartitionService(nil, config.Partition{Period: "day", Ahead: 0, Retention: 72 * time.Hour}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := day(10)
+	partitions := []models.EntryPartition{
+		{Name: "entry_history_legacy", To: day(6)},
+		// Termina exatamente no corte: já venceu.
+		{Name: "entry_history_p20250706", From: day(6), To: day(7)},
+		{Name: "entry_history_p20250707", From: day(7), To: day(8)},
+		{Name: "entry_history_p20250710", From: day(10), To: day(11)},
+		// Sem fim (MAXVALUE) nunca vence.
+		{Name: "entry_history_pmax", From: day(11)},
+	}
+
+	create, expire := s.plan(now, partitions)
+	if !equalNames(expire, "entry_history_legacy", "entry_history_p20250706") {
+		t.Fatalf("expire = %v", partitionNames(expire))
+	}
+	if len(create) != 0 {
+		t.Fatalf("create = %v", partitionNames(create))
+	}
+}
+
+func TestNewPartitionServiceRejectsPeriod(t *testing.T) {
+	if _, err := NewPartitionService(nil, config.Partition{Period: "week"}, nil); err == nil {
+		t.Fatal("PARTITION_PERIOD inválido aceito")
+	}
+}
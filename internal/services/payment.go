@@ -2,16 +2,20 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
-	"unsafe"
 
+	"github.com/Patrignani/patrignani-rinha-backend-go/internal/events"
+	"github.com/Patrignani/patrignani-rinha-backend-go/internal/processors"
 	"github.com/Patrignani/patrignani-rinha-backend-go/internal/repositories"
 	"github.com/Patrignani/patrignani-rinha-backend-go/internal/workers"
 	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/config"
 	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/models"
-	"github.com/valyala/fasthttp"
-	"github.com/valyala/fastjson"
 )
 
 // var randPool = sync.Pool{
@@ -21,37 +25,69 @@ import (
 // }
 
 type PaymentService struct {
-	queue        *workers.QueueWorker
-	repo         *repositories.PaymentRepository
-	defaultFast  *fasthttp.HostClient
-	fallbackFast *fasthttp.HostClient
+	queue       *workers.QueueWorker
+	repo        *repositories.PaymentRepository
+	deadLetters *repositories.DeadLetterRepository
+	refunds     *repositories.RefundRepository
+	batches     *repositories.BatchRepository
+	schedules   *repositories.ScheduleRepository
+	processors  *processors.Registry
+	strategy    processors.Strategy
+	// unresolved guarda, por tenant e correlationId, o processador de um
+	// resultado desconhecido.
+	unresolved sync.Map
+	tracker    *Tracker
+	webhooks   *WebhookService
+	events     *EventRelay
+	rates      *RateService
+	observer   atomic.Pointer[func(time.Duration, error)]
 }
 
-func NewPaymentService(repo *repositories.PaymentRepository, queue *workers.QueueWorker) *PaymentService {
-	var fastClient1 = &fasthttp.HostClient{
-		Addr:     config.Env.DefaultUrl,
-		MaxConns: 2048,
-	}
+func NewPaymentService(repo *repositories.PaymentRepository, deadLetters *repositories.DeadLetterRepository, refunds *repositories.RefundRepository, batches *repositories.BatchRepository, schedules *repositories.ScheduleRepository, queue *workers.QueueWorker, registry *processors.Registry, strategy processors.Strategy, tracker *Tracker, webhooks *WebhookService, relay *EventRelay, rates *RateService) *PaymentService {
+	return &PaymentService{repo: repo, deadLetters: deadLetters, refunds: refunds, batches: batches, schedules: schedules, queue: queue, processors: registry, strategy: strategy, tracker: tracker, webhooks: webhooks, events: relay, rates: rates}
+}
+
+// SetObserver recebe a latência e o erro de cada chamada aos processadores;
+// é o que alimenta o autoscaler.
+func (p *PaymentService) SetObserver(observer func(time.Duration, error)) {
+	p.observer.Store(&observer)
+}
 
-	var fastClient2 = &fasthttp.HostClient{
-		Addr:     config.Env.FallbackUrl,
-		MaxConns: 2048,
+// RunQueue recebe a referência de msg: ela é liberada aqui ou repassada para a
+// fila de retry. correlationId é uma view de msg, então tudo que o usa depois
+// de RunQueue retornar precisa segurar a sua própria referência.
+func (p *PaymentService) RunQueue(ctx context.Context, msg *workers.Envelope) error {
+	if msg.Kind == workers.KindRefund {
+		return p.runRefund(ctx, msg)
 	}
 
-	return &PaymentService{repo: repo, queue: queue, defaultFast: fastClient1, fallbackFast: fastClient2}
-}
+	correlationId := msg.CorrelationId
+	amount := msg.Amount
+	createdAt := requestedAt(msg)
+	msg.Attempts++
+	p.tracker.Processing(correlationId, amount)
 
-func (p *PaymentService) RunQueue(ctx context.Context, msg []byte) error {
-	var parser fastjson.Parser
-	v, err := parser.ParseBytes(msg)
-	if err != nil {
-		println(fmt.Sprintf("ERRO DE CONVERSÃO %v", err))
-		return err
+	if !msg.Deadline.IsZero() && time.Now().After(msg.Deadline) {
+		p.deadLetter(ctx, msg, msg.LastProcessor, correlationId, amount, fmt.Sprintf("prazo expirado após %d tentativas", msg.Attempts-1))
+		msg.Release()
+		return errMessageExpired
 	}
 
-	correlationId := b2s(v.GetStringBytes("correlationId"))
-	amount := v.GetFloat64("amount")
-	createdAt := time.Now().UTC()
+	// O processador deduplica só pelo correlationId: entre tenants, ele é de
+	// quem o usou primeiro.
+	if msg.TenantId != "" {
+		owner, err := p.repo.ClaimOwner(ctx, correlationId, msg.TenantId)
+		if err != nil {
+			p.tracker.Failed(correlationId, err.Error())
+			p.retry(ctx, msg)
+			return err
+		}
+		if owner != msg.TenantId {
+			p.deadLetter(ctx, msg, "", correlationId, amount, ErrCorrelationConflict.Error())
+			msg.Release()
+			return ErrCorrelationConflict
+		}
+	}
 
 	// if err := p.CallbackExc(ctx, correlationId, amount, createdAt, 0); err != nil {
 	// 	if err := p.ExecuteFallback(ctx, correlationId, amount, createdAt); err != nil {
@@ -72,15 +108,131 @@ func (p *PaymentService) RunQueue(ctx context.Context, msg []byte) error {
 	// 	p.queue.Send(msg)
 	// }
 
-	if err := p.ExecuteDefault(ctx, correlationId, amount, createdAt); err != nil {
-		if err := p.ExecuteFallback(ctx, correlationId, amount, createdAt); err != nil {
-			p.queue.Send(msg)
+	if deadline, ok := attemptDeadline(msg); ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, deadline)
+		defer cancel()
+	}
+
+	// unresolved é por tenant, como o dono do correlationId. Uma mensagem que
+	// volta do backend durável, depois de um restart ou em outra instância,
+	// traz o resultado pendente na cotação do envelope.
+	key := msg.TenantId + ":" + correlationId
+	pending, ok := p.unresolved.Load(key)
+	if !ok && !msg.Quote.RequestedAt.IsZero() && msg.LastProcessor != "" {
+		pending, ok = msg.LastProcessor, true
+	}
+	if ok {
+		processor, found := p.processors.Get(pending.(string))
+		if found {
+			switch out, confirmedAt, err := p.resolve(ctx, processor, correlationId, errPendingOutcome); out {
+			case outcomeCharged:
+				p.unresolved.Delete(key)
+				quote := msg.Quote
+				if quote.RequestedAt.IsZero() {
+					// Envelope anterior à cotação gravada; vale a atual.
+					quote, err = p.rates.Quote(msg.Currency, amount, processor.Currency())
+					if err != nil {
+						log.Printf("Sem cotação para gravar o pagamento %s: %v", correlationId, err)
+						quote = Quote{Currency: msg.Currency, Amount: amount, Rate: 1, BaseRate: 1}
+					}
+				}
+				if !confirmedAt.IsZero() {
+					quote.RequestedAt = confirmedAt
+				}
+				p.record(ctx, msg, processors.Decision{Processor: processor, Strategy: p.strategy.Name()}, quote, correlationId, amount, createdAt)
+				msg.Release()
+				return nil
+			case outcomeUnknown:
+				if limit := config.Env.Payment.UnresolvedAttempts; limit > 0 && msg.Attempts >= limit {
+					p.unresolved.Delete(key)
+					p.deadLetter(ctx, msg, processor.Name(), correlationId, amount,
+						fmt.Sprintf("resultado desconhecido em %s após %d tentativas; conferir na reconciliação", processor.Name(), msg.Attempts))
+					msg.Release()
+					return err
+				}
+				p.tracker.Failed(correlationId, err.Error())
+				p.events.Publish(p.paymentEvent(events.TypePaymentFailed, msg, processor.Name(), err.Error()))
+				p.retry(ctx, msg)
+				return err
+			case outcomeRejected:
+				p.unresolved.Delete(key)
+				var rejected *RejectedError
+				errors.As(err, &rejected)
+				p.deadLetter(ctx, msg, processor.Name(), correlationId, amount, rejected.Reason)
+				msg.Release()
+				return err
+			}
 		}
+		p.unresolved.Delete(key)
+		msg.Quote = Quote{}
+	}
+
+	err := p.dispatch(ctx, msg, p.strategy.Plan(amount), correlationId, amount, createdAt)
+	if err == nil {
+		msg.Release()
+		return nil
 	}
 
+	var unknown *UnknownOutcomeError
+	if errors.As(err, &unknown) {
+		p.unresolved.Store(key, unknown.Processor)
+		msg.Quote = unknown.Quote
+	}
+
+	var rejected *RejectedError
+	if errors.As(err, &rejected) {
+		p.deadLetter(ctx, msg, rejected.Processor, correlationId, amount, rejected.Reason)
+		msg.Release()
+		return err
+	}
+
+	p.tracker.Failed(correlationId, err.Error())
+	p.events.Publish(p.paymentEvent(events.TypePaymentFailed, msg, msg.LastProcessor, err.Error()))
+	p.retry(ctx, msg)
 	return nil
 }
 
+// retry devolve msg para a lane de retry; se ela estiver cheia (e não houver
+// spill), a mensagem vai para o dead-letter, de onde volta pelo replay.
+func (p *PaymentService) retry(ctx context.Context, msg *workers.Envelope) {
+	if err := p.queue.Retry(msg); err != nil {
+		p.deadLetter(ctx, msg, msg.LastProcessor, msg.CorrelationId, msg.Amount, fmt.Sprintf("fila de retry cheia: %v", err))
+		msg.Release()
+	}
+}
+
+// dispatch tenta os processadores na ordem do plano. Com HEDGE_AFTER, se o
+// primeiro não responder nesse tempo a chamada é abandonada e o pagamento vai
+// para o seguinte (normalmente o fallback), mas só depois que a consulta
+// GET /payments/{id} ao primeiro confirma, como em qualquer resultado ambíguo,
+// que ele não cobrou; assim o hedge nunca cobra duas vezes. Um primeiro
+// processador sem consulta não tem hedge: a chamada segue até o timeout dele.
+// Um resultado desconhecido ou uma recusa definitiva interrompem o plano: não
+// há failover sem antes saber, pelo processador, se o pagamento foi cobrado.
+func (p *PaymentService) dispatch(ctx context.Context, msg *workers.Envelope, plan []processors.Decision, correlationId string, amount float64, createdAt time.Time) error {
+	hedgeAfter := config.Env.Payment.HedgeAfter
+
+	err := errors.New("nenhum processador disponível")
+	for i, decision := range plan {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		var callTimeout time.Duration
+		if i == 0 && len(plan) > 1 && decision.Processor.CanLookup() {
+			callTimeout = hedgeAfter
+		}
+
+		msg.LastProcessor = decision.Processor.Name()
+		if err = p.Execute(ctx, msg, decision, correlationId, amount, createdAt, callTimeout); err == nil || stopsPlan(err) {
+			return err
+		}
+	}
+
+	return err
+}
+
 // func (p *PaymentService) CallbackExc(ctx context.Context, correlationId string, amount float64, createdAt time.Time, attempts int) error {
 // 	if err := p.ExecuteDefault(ctx, correlationId, amount, createdAt); err != nil {
 // 		if attempts < config.Env.AttempsRetry {
@@ -95,77 +247,245 @@ func (p *PaymentService) RunQueue(ctx context.Context, msg []byte) error {
 // 	return nil
 // }
 
-func (p *PaymentService) ExecuteDefault(ctx context.Context, correlationId string, amount float64, createdAt time.Time) error {
-	return p.execute(ctx, correlationId, amount, createdAt, false)
-}
+// Execute faz uma tentativa em decision; callTimeout, quando positivo, limita
+// só a chamada de pagamento, não a consulta que decide um resultado ambíguo.
+func (p *PaymentService) Execute(ctx context.Context, msg *workers.Envelope, decision processors.Decision, correlationId string, amount float64, createdAt time.Time, callTimeout time.Duration) error {
+	out, quote, err := p.attempt(ctx, decision.Processor, correlationId, msg.Currency, amount, callTime(createdAt), callTimeout)
+	if out == outcomeCharged {
+		p.record(ctx, msg, decision, quote, correlationId, amount, createdAt)
+		return nil
+	}
 
-func (p *PaymentService) ExecuteFallback(ctx context.Context, correlationId string, amount float64, createdAt time.Time) error {
-	return p.execute(ctx, correlationId, amount, createdAt, true)
+	return err
 }
 
-func (p *PaymentService) execute(ctx context.Context, correlationId string, amount float64, createdAt time.Time, fallback bool) error {
+func (p *PaymentService) call(ctx context.Context, processor processors.Processor, correlationId string, quote Quote) (int, error) {
+	start := time.Now()
+	statusCode, err := processor.Pay(ctx, models.PaymentRequest{
+		CorrelationId: correlationId,
+		Amount:        quote.Amount,
+		Currency:      quote.Currency,
+		RequestedAt:   quote.RequestedAt,
+	})
+	elapsed := time.Since(start)
+	p.processors.Record(processor.Name(), elapsed, err != nil || statusCode >= 500)
+	if observer := p.observer.Load(); observer != nil {
+		callErr := err
+		if callErr == nil && statusCode >= 500 {
+			callErr = fmt.Errorf("processador %s respondeu %d", processor.Name(), statusCode)
+		}
+		(*observer)(elapsed, callErr)
+	}
+
+	attempt := models.PaymentAttempt{
+		Processor:  processor.Name(),
+		StartedAt:  start.UTC(),
+		DurationMs: elapsed.Milliseconds(),
+		StatusCode: statusCode,
+	}
+	if err != nil {
+		attempt.Error = err.Error()
+	}
+	p.tracker.Attempt(correlationId, attempt)
 
-	statusCode, err := p.postPayment(fallback, correlationId, amount, createdAt)
 	if err != nil {
 		println(fmt.Sprintf("Erro post: %v", err))
-		return err
 	}
 
-	if statusCode >= 200 && statusCode < 300 {
-		go p.repo.Insert(ctx, models.PaymentDb{
-			CorrelationId: correlationId,
-			Amount:        amount,
-			Fallback:      fallback,
-			CreatedAt:     createdAt,
-		})
+	return statusCode, err
+}
 
-		return nil
-	} else if statusCode == 422 {
-		return nil
+// record grava o pagamento em segundo plano, com a cotação usada na chamada;
+// segura uma referência de msg porque correlationId aponta para ele.
+func (p *PaymentService) record(ctx context.Context, msg *workers.Envelope, decision processors.Decision, quote Quote, correlationId string, amount float64, createdAt time.Time) {
+	if !quote.RequestedAt.IsZero() {
+		createdAt = quote.RequestedAt
 	}
-
-	return fmt.Errorf("HTTP status fora da faixa 2xx: %d", statusCode)
+	p.tracker.Processed(correlationId, decision.Processor.Name(), decision.Strategy, createdAt)
+	p.events.Publish(p.paymentEvent(events.TypePaymentProcessed, msg, decision.Processor.Name(), ""))
+	p.webhooks.Notify(ctx, msg.CallbackUrl, models.WebhookEvent{
+		Event:         models.WebhookPaymentProcessed,
+		CorrelationId: correlationId,
+		Amount:        amount,
+		Currency:      msg.Currency,
+		Processor:     decision.Processor.Name(),
+		TenantId:      msg.TenantId,
+	})
+
+	msg.Retain()
+	go func() {
+		defer msg.Release()
+		p.repo.Insert(context.WithoutCancel(ctx), models.PaymentDb{
+			CorrelationId:     correlationId,
+			Amount:            amount,
+			Processor:         decision.Processor.Name(),
+			Strategy:          decision.Strategy,
+			ExpectedCost:      decision.ExpectedCost,
+			TenantId:          msg.TenantId,
+			CreatedAt:         createdAt,
+			Currency:          p.rates.Code(msg.Currency),
+			ProcessorCurrency: quote.Currency,
+			Rate:              quote.Rate,
+			BaseRate:          quote.BaseRate,
+		})
+	}()
 }
 
-func (p *PaymentService) postPayment(fallback bool, correlationId string, amount float64, createdAt time.Time) (int, error) {
+// attemptDeadline combina PAYMENT_DEADLINE, que limita cada tentativa, com o
+// prazo total do envelope.
+func attemptDeadline(msg *workers.Envelope) (time.Time, bool) {
+	deadline := msg.Deadline
+	if config.Env.Payment.Deadline > 0 {
+		attempt := time.Now().Add(config.Env.Payment.Deadline)
+		if deadline.IsZero() || attempt.Before(deadline) {
+			deadline = attempt
+		}
+	}
+	return deadline, !deadline.IsZero()
+}
 
-	pay := models.PaymentRequest{
+func (p *PaymentService) deadLetter(ctx context.Context, msg *workers.Envelope, processor, correlationId string, amount float64, reason string) {
+	entry := models.DeadLetter{
+		CorrelationId: strings.Clone(correlationId),
+		Amount:        amount,
+		Processor:     processor,
+		Reason:        reason,
+		Payload:       string(msg.Body.Bytes()),
+		TenantId:      msg.TenantId,
+		CreatedAt:     time.Now().UTC(),
+	}
+	p.tracker.DeadLettered(correlationId, processor, reason)
+	p.events.Publish(p.paymentEvent(events.TypePaymentDeadLettered, msg, processor, reason))
+	p.webhooks.Notify(ctx, msg.CallbackUrl, models.WebhookEvent{
+		Event:         models.WebhookPaymentDeadLettered,
 		CorrelationId: correlationId,
 		Amount:        amount,
-		RequestedAt:   createdAt,
+		Currency:      msg.Currency,
+		Processor:     processor,
+		Reason:        reason,
+		TenantId:      msg.TenantId,
+	})
+
+	go func() {
+		if err := p.deadLetters.Insert(context.WithoutCancel(ctx), entry); err != nil {
+			println(fmt.Sprintf("Erro ao gravar dead-letter: %v", err))
+		}
+	}()
+}
+
+// DrainQueue remove até n mensagens da fila e as grava no dead-letter antes de
+// devolver os payloads, para que nada se perca se a resposta do admin não
+// chegar ao cliente; dali podem voltar pela rota de replay. Se uma gravação
+// falhar, essa mensagem e as seguintes voltam para a fila.
+func (p *PaymentService) DrainQueue(ctx context.Context, n int) ([]string, error) {
+	drained := p.queue.Drain(n)
+	payloads := make([]string, 0, len(drained))
+
+	for i, msg := range drained {
+		entry := models.DeadLetter{
+			CorrelationId: strings.Clone(msg.CorrelationId),
+			Amount:        msg.Amount,
+			Reason:        "removida da fila pelo admin",
+			Payload:       string(msg.Body.Bytes()),
+			TenantId:      msg.TenantId,
+			CreatedAt:     time.Now().UTC(),
+		}
+
+		if err := p.deadLetters.Insert(ctx, entry); err != nil {
+			for _, rest := range drained[i:] {
+				if sendErr := p.queue.SendLane(workers.LaneReplay, rest); sendErr != nil {
+					rest.Release()
+				}
+			}
+			return payloads, fmt.Errorf("erro ao gravar mensagem drenada no dead-letter: %w", err)
+		}
+
+		p.tracker.DeadLettered(entry.CorrelationId, "", entry.Reason)
+		payloads = append(payloads, entry.Payload)
+		msg.Release()
 	}
 
-	body, err := pay.MarshalJSON()
+	return payloads, nil
+}
+
+// GetPaymentSummary totaliza na moeda base; currency, quando informada, acrescenta
+// os totais convertidos pela cotação atual.
+func (p *PaymentService) GetPaymentSummary(ctx context.Context, tenantId string, from, to *time.Time, currency string) (*models.SummaryResponse, error) {
+	if currency != "" {
+		if err := p.rates.Validate(currency); err != nil {
+			return nil, err
+		}
+	}
 
+	summary, err := p.repo.GetPaymentSummary(ctx, tenantId, from, to)
 	if err != nil {
-		println(fmt.Sprintf("HTTP status fora da faixa 2xx: %v", err))
+		return nil, err
 	}
 
-	req := fasthttp.AcquireRequest()
-	resp := fasthttp.AcquireResponse()
-	defer fasthttp.ReleaseRequest(req)
-	defer fasthttp.ReleaseResponse(resp)
+	refunded, err := p.refunds.RefundedByProcessor(ctx, tenantId, from, to)
+	if err != nil {
+		return nil, err
+	}
 
-	req.SetRequestURI("/payments")
-	req.Header.SetMethod(fasthttp.MethodPost)
-	req.Header.SetContentType("application/json")
-	req.SetBodyRaw(body)
+	for _, name := range p.processors.Names() {
+		if _, ok := (*summary)[name]; !ok {
+			(*summary)[name] = models.PaymentSummary{}
+		}
+	}
 
-	if fallback {
-		req.Header.Set("Host", config.Env.FallbackUrl)
-		err := p.fallbackFast.Do(req, resp)
-		return resp.StatusCode(), err
+	for name, item := range *summary {
+		item.TotalAmount = roundCents(item.TotalAmount)
+		item.RefundedAmount = refunded[name]
+		item.NetAmount = item.TotalAmount - item.RefundedAmount
+		item.Currencies = p.currencies(item.Currencies)
+
+		if currency != "" {
+			converted := &models.ConvertedSummary{Currency: currency}
+			converted.TotalAmount, _ = p.rates.Convert(item.TotalAmount, currency)
+			converted.RefundedAmount, _ = p.rates.Convert(item.RefundedAmount, currency)
+			converted.NetAmount = roundCents(converted.TotalAmount - converted.RefundedAmount)
+			item.Converted = converted
+		}
+
+		(*summary)[name] = item
 	}
 
-	req.Header.Set("Host", config.Env.DefaultUrl)
-	err = p.defaultFast.Do(req, resp)
-	return resp.StatusCode(), err
+	return summary, nil
 }
 
-func b2s(b []byte) string {
-	return *(*string)(unsafe.Pointer(&b))
+// currencies junta as linhas sem moeda (gravadas antes da moeda existir) às da
+// moeda base e omite o detalhe quando tudo está na moeda base.
+func (p *PaymentService) currencies(byCurrency map[string]models.CurrencySummary) map[string]models.CurrencySummary {
+	if legacy, ok := byCurrency[""]; ok {
+		delete(byCurrency, "")
+		base := byCurrency[p.rates.Base()]
+		base.TotalRequests += legacy.TotalRequests
+		base.TotalAmount += legacy.TotalAmount
+		byCurrency[p.rates.Base()] = base
+	}
+
+	if len(byCurrency) == 0 {
+		return nil
+	}
+	if _, ok := byCurrency[p.rates.Base()]; ok && len(byCurrency) == 1 {
+		return nil
+	}
+	return byCurrency
+}
+
+// ValidateCurrency recusa moedas sem cotação carregada; vazia é a moeda base.
+func (p *PaymentService) ValidateCurrency(currency string) error {
+	return p.rates.Validate(currency)
+}
+
+// WebhookDeliveries lista o log de entregas de webhooks.
+func (p *PaymentService) WebhookDeliveries(ctx context.Context, correlationId, status string, limit int) (models.WebhookDeliveries, error) {
+	if p.webhooks == nil {
+		return models.WebhookDeliveries{}, nil
+	}
+	return p.webhooks.Deliveries(ctx, correlationId, status, limit)
 }
 
-func (p *PaymentService) GetPaymentSummary(ctx context.Context, from, to *time.Time) (*models.SummaryResponse, error) {
-	return p.repo.GetPaymentSummary(ctx, from, to)
+func (p *PaymentService) RoutingStats() models.RoutingStats {
+	return p.strategy.Stats()
 }
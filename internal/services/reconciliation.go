@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/Patrignani/patrignani-rinha-backend-go/internal/processors"
+	"github.com/Patrignani/patrignani-rinha-backend-go/internal/repositories"
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/models"
+)
+
+const amountTolerance = 0.005
+
+type ReconciliationService struct {
+	payments   *repositories.PaymentRepository
+	reports    *repositories.ReconciliationRepository
+	processors *processors.Registry
+	windows    []time.Duration
+	lag        time.Duration
+	sample     int
+	lease      time.Duration
+	owner      string
+}
+
+func NewReconciliationService(payments *repositories.PaymentRepository, reports *repositories.ReconciliationRepository, registry *processors.Registry, windows []time.Duration, lag time.Duration, sample int, lease time.Duration) *ReconciliationService {
+	return &ReconciliationService{
+		payments:   payments,
+		reports:    reports,
+		processors: registry,
+		windows:    windows,
+		lag:        lag,
+		sample:     sample,
+		lease:      lease,
+		owner:      newUUID(),
+	}
+}
+
+// Run compara, para cada janela terminando em agora-lag, o resumo local com o
+// resumo de cada processador e, na menor janela, confere uma amostra de
+// correlationIds. Divergências vão para reconciliation_report. Só a instância
+// com o lease roda a reconciliação; as outras pulam a rodada. A rodada é
+// cortada quando o lease vence, para não rodar junto com quem o tomar.
+func (r *ReconciliationService) Run(ctx context.Context) error {
+	locked, err := r.reports.TryLock(ctx, r.owner, time.Now().UTC(), r.lease)
+	if err != nil || !locked {
+		return err
+	}
+	defer func() {
+		if err := r.reports.Unlock(context.WithoutCancel(ctx), r.owner); err != nil {
+			log.Printf("[Reconciliation] erro ao devolver o lease: %v", err)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, r.lease)
+	defer cancel()
+
+	checkedAt := time.Now().UTC()
+	to := checkedAt.Add(-r.lag)
+
+	var errs []error
+	for i, window := range r.windows {
+		from := to.Add(-window)
+
+		local, err := r.payments.GetPaymentSummary(ctx, "", &from, &to)
+		if err != nil {
+			return fmt.Errorf("resumo local: %w", err)
+		}
+
+		for _, processor := range r.processors.All() {
+			if err := r.compareSummary(ctx, checkedAt, processor, (*local)[processor.Name()], from, to); err != nil {
+				errs = append(errs, err)
+			}
+
+			if i == 0 && r.sample > 0 {
+				if err := r.comparePayments(ctx, checkedAt, processor, from, to); err != nil {
+					errs = append(errs, err)
+				}
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (r *ReconciliationService) compareSummary(ctx context.Context, checkedAt time.Time, processor processors.Processor, local models.PaymentSummary, from, to time.Time) error {
+	remote, err := processor.Summary(ctx, from, to)
+	if errors.Is(err, processors.ErrSummaryUnsupported) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// O processador totaliza na sua moeda; local.TotalAmount está na moeda base.
+	if local.TotalRequests == remote.TotalRequests && math.Abs(local.ProcessorAmount-remote.TotalAmount) < amountTolerance {
+		return nil
+	}
+
+	log.Printf("[Reconciliation] %s divergente em %v: local %d/%.2f, processador %d/%.2f",
+		processor.Name(), to.Sub(from), local.TotalRequests, local.ProcessorAmount, remote.TotalRequests, remote.TotalAmount)
+
+	return r.reports.Insert(ctx, models.ReconciliationEntry{
+		CheckedAt:      checkedAt,
+		Processor:      processor.Name(),
+		Kind:           models.ReconciliationSummary,
+		WindowFrom:     from,
+		WindowTo:       to,
+		LocalRequests:  local.TotalRequests,
+		RemoteRequests: remote.TotalRequests,
+		LocalAmount:    local.ProcessorAmount,
+		RemoteAmount:   remote.TotalAmount,
+	})
+}
+
+func (r *ReconciliationService) comparePayments(ctx context.Context, checkedAt time.Time, processor processors.Processor, from, to time.Time) error {
+	payments, err := r.payments.ListCorrelationIds(ctx, processor.Name(), from, to, r.sample)
+	if err != nil {
+		return err
+	}
+
+	for _, payment := range payments {
+		sent := roundCents(payment.Amount * payment.Rate)
+		remote, found, err := processor.Lookup(ctx, payment.CorrelationId)
+		if errors.Is(err, processors.ErrLookupUnsupported) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var detail string
+		switch {
+		case !found:
+			detail = "pagamento não encontrado no processador"
+		case math.Abs(remote.Amount-sent) >= amountTolerance:
+			detail = "valor divergente"
+		default:
+			continue
+		}
+
+		correlationId := payment.CorrelationId
+		entry := models.ReconciliationEntry{
+			CheckedAt:     checkedAt,
+			Processor:     processor.Name(),
+			Kind:          models.ReconciliationPayment,
+			WindowFrom:    from,
+			WindowTo:      to,
+			CorrelationId: &correlationId,
+			LocalRequests: 1,
+			LocalAmount:   sent,
+			Detail:        detail,
+		}
+		if found {
+			entry.RemoteRequests = 1
+			entry.RemoteAmount = remote.Amount
+		}
+
+		if err := r.reports.Insert(ctx, entry); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *ReconciliationService) Report(ctx context.Context, since *time.Time, limit int) (models.ReconciliationReport, error) {
+	return r.reports.List(ctx, since, limit)
+}
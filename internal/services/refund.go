@@ -0,0 +1,219 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Patrignani/patrignani-rinha-backend-go/internal/processors"
+	"github.com/Patrignani/patrignani-rinha-backend-go/internal/workers"
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/buffers"
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/config"
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/models"
+	"github.com/valyala/fastjson"
+)
+
+var (
+	ErrInvalidRefund = errors.New("estorno inválido")
+	errRefundWaiting = errors.New("estorno aguardando o pagamento")
+)
+
+// NewRefund monta o envelope de um pedido de estorno de correlationId. body é
+// opcional: {"amount": 10.5} para estorno parcial, "refundId" para que o
+// cliente possa repetir o pedido sem estornar duas vezes e "callbackUrl" para
+// receber o resultado; sem amount, estorna todo o saldo.
+func NewRefund(correlationId string, body []byte, receivedAt time.Time, traceParent []byte) (*workers.Envelope, error) {
+	if !isUUID(correlationId) {
+		return nil, fmt.Errorf("%w: correlationId deve ser um UUID", ErrInvalidRefund)
+	}
+
+	var amount float64
+	refundId, callbackUrl := "", ""
+	if len(body) > 0 {
+		var parser fastjson.Parser
+		v, err := parser.ParseBytes(body)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidRefund, err)
+		}
+		amount = v.GetFloat64("amount")
+		refundId = string(v.GetStringBytes("refundId"))
+		callbackUrl = string(v.GetStringBytes("callbackUrl"))
+	}
+
+	if amount < 0 {
+		return nil, fmt.Errorf("%w: amount negativo", ErrInvalidRefund)
+	}
+
+	if refundId == "" {
+		refundId = newUUID()
+	} else if !isUUID(refundId) {
+		return nil, fmt.Errorf("%w: refundId deve ser um UUID", ErrInvalidRefund)
+	}
+
+	raw := fmt.Appendf(nil, `{"refundId":%q,"correlationId":%q,"amount":%s`, refundId, correlationId, strconv.FormatFloat(amount, 'f', -1, 64))
+	if callbackUrl != "" {
+		if err := ValidateCallbackURL(callbackUrl); err != nil {
+			return nil, err
+		}
+		raw = fmt.Appendf(raw, `,"callbackUrl":%q`, callbackUrl)
+	}
+	raw = append(raw, '}')
+	return workers.NewRefundEnvelope(buffers.Copy(raw), receivedAt, traceParent)
+}
+
+// runRefund processa um estorno no processador que cobrou o pagamento. A
+// reserva do valor é feita uma vez, na primeira tentativa; as seguintes só
+// repetem a chamada ao processador com o mesmo refundId.
+func (p *PaymentService) runRefund(ctx context.Context, msg *workers.Envelope) error {
+	msg.Attempts++
+
+	if deadline, ok := attemptDeadline(msg); ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, deadline)
+		defer cancel()
+	}
+
+	refund, found, err := p.refunds.Get(ctx, msg.RefundId)
+	if err != nil {
+		p.retry(ctx, msg)
+		return err
+	}
+
+	if !found {
+		request := models.RefundDb{
+			RefundId:      msg.RefundId,
+			CorrelationId: msg.CorrelationId,
+			Amount:        msg.Amount,
+			TenantId:      msg.TenantId,
+			CreatedAt:     requestedAt(msg),
+		}
+
+		refund, found, err = p.refunds.Reserve(ctx, request)
+		if err != nil {
+			p.retry(ctx, msg)
+			return err
+		}
+
+		if !found {
+			// O pagamento pode ainda estar na fila ou em processamento nesta
+			// instância; a espera não conta como tentativa e acaba quando o
+			// Tracker o finaliza ou o descarta.
+			if p.paymentInFlight(msg.CorrelationId, msg.TenantId) {
+				msg.Attempts--
+				p.retry(ctx, msg)
+				return errRefundWaiting
+			}
+
+			request.Reason = "pagamento não encontrado ou saldo insuficiente"
+			err = p.refunds.Reject(ctx, request)
+			p.notifyRefund(ctx, msg, models.WebhookRefundFailed, request)
+			msg.Release()
+			if err != nil {
+				return err
+			}
+			return fmt.Errorf("%w: %s", ErrInvalidRefund, request.Reason)
+		}
+	}
+
+	if refund.Status != models.RefundPending {
+		msg.Release()
+		return nil
+	}
+
+	if !msg.Deadline.IsZero() && time.Now().After(msg.Deadline) {
+		return p.failRefund(ctx, msg, refund, "prazo expirado")
+	}
+
+	processor, ok := p.processors.Get(refund.Processor)
+	if !ok {
+		return p.failRefund(ctx, msg, refund, fmt.Sprintf("processador %s não está registrado", refund.Processor))
+	}
+	msg.LastProcessor = processor.Name()
+
+	// O estorno usa a cotação gravada com o pagamento, para devolver na moeda
+	// do processador a mesma fração do que foi cobrado.
+	statusCode, err := processor.Refund(ctx, models.RefundRequest{
+		RefundId:      refund.RefundId,
+		CorrelationId: refund.CorrelationId,
+		Amount:        roundCents(refund.Amount * refund.Rate),
+		Currency:      refund.ProcessorCurrency,
+		RequestedAt:   refund.CreatedAt,
+	})
+
+	switch {
+	case errors.Is(err, processors.ErrRefundUnsupported):
+		return p.failRefund(ctx, msg, refund, err.Error())
+	case err != nil, statusCode >= 500:
+		if limit := config.Env.Payment.RefundAttempts; limit > 0 && msg.Attempts >= limit {
+			return p.failRefund(ctx, msg, refund, fmt.Sprintf("processador falhou após %d tentativas; conferir na reconciliação", msg.Attempts))
+		}
+		// Ambíguo ou transitório: o refundId torna a repetição segura.
+		p.retry(ctx, msg)
+		if err == nil {
+			err = fmt.Errorf("processador respondeu HTTP %d ao estorno", statusCode)
+		}
+		return err
+	case statusCode >= 400:
+		return p.failRefund(ctx, msg, refund, fmt.Sprintf("processador recusou o estorno: HTTP %d", statusCode))
+	}
+
+	err = p.refunds.Complete(ctx, refund.RefundId, time.Now().UTC())
+	if err != nil {
+		// O processador já estornou; repetir só reenvia o mesmo refundId.
+		p.retry(ctx, msg)
+		return err
+	}
+
+	p.notifyRefund(ctx, msg, models.WebhookRefundRefunded, refund)
+
+	msg.Release()
+	return nil
+}
+
+// paymentInFlight diz se o Tracker desta instância tem o pagamento ainda sem
+// resultado final.
+func (p *PaymentService) paymentInFlight(correlationId, tenantId string) bool {
+	status, ok := p.tracker.Get(correlationId, tenantId)
+	if !ok {
+		return false
+	}
+	switch status.Status {
+	case models.PaymentQueued, models.PaymentProcessing, models.PaymentFailed:
+		return true
+	}
+	return false
+}
+
+func (p *PaymentService) failRefund(ctx context.Context, msg *workers.Envelope, refund models.RefundDb, reason string) error {
+	err := p.refunds.Fail(context.WithoutCancel(ctx), msg.RefundId, reason)
+	refund.Reason = reason
+	p.notifyRefund(ctx, msg, models.WebhookRefundFailed, refund)
+	msg.Release()
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("%w: %s", ErrInvalidRefund, reason)
+}
+
+func (p *PaymentService) notifyRefund(ctx context.Context, msg *workers.Envelope, event string, refund models.RefundDb) {
+	p.webhooks.Notify(ctx, msg.CallbackUrl, models.WebhookEvent{
+		Event:         event,
+		CorrelationId: refund.CorrelationId,
+		RefundId:      refund.RefundId,
+		Amount:        refund.Amount,
+		Processor:     refund.Processor,
+		Reason:        refund.Reason,
+		TenantId:      msg.TenantId,
+	})
+}
+
+func newUUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Patrignani/patrignani-rinha-backend-go/internal/workers"
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/config"
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/models"
+)
+
+var (
+	ErrInvalidSchedule  = errors.New("agendamento inválido")
+	ErrAlreadyScheduled = errors.New("pagamento já agendado")
+)
+
+// Schedule grava msg em scheduled_payments para ser enviado à fila em
+// msg.ScheduledAt. O instante do pagamento passa a ser o agendado e o prazo
+// total (PAYMENT_TTL) é deslocado junto. A referência de msg continua com quem
+// chamou.
+func (p *PaymentService) Schedule(ctx context.Context, msg *workers.Envelope) error {
+	if config.Env.Schedule.Interval <= 0 {
+		return fmt.Errorf("%w: SCHEDULER_INTERVAL=0 desliga o agendamento", ErrInvalidSchedule)
+	}
+	if !isUUID(msg.CorrelationId) {
+		return fmt.Errorf("%w: correlationId deve ser um UUID", ErrInvalidSchedule)
+	}
+	if horizon := config.Env.Schedule.MaxHorizon; horizon > 0 && msg.ScheduledAt.After(msg.RequestedAt.Add(horizon)) {
+		return fmt.Errorf("%w: scheduledAt além de %v", ErrInvalidSchedule, horizon)
+	}
+
+	if !msg.Deadline.IsZero() {
+		msg.Deadline = msg.Deadline.Add(msg.ScheduledAt.Sub(msg.RequestedAt))
+	}
+	msg.RequestedAt = msg.ScheduledAt
+
+	inserted, err := p.schedules.Insert(ctx, models.ScheduledPaymentDb{
+		CorrelationId: msg.CorrelationId,
+		Amount:        msg.Amount,
+		TenantId:      msg.TenantId,
+		ScheduledAt:   msg.ScheduledAt.UTC(),
+		Payload:       msg.AppendBinary(nil),
+		CreatedAt:     time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+	if !inserted {
+		return ErrAlreadyScheduled
+	}
+
+	return nil
+}
+
+// ReleaseDue envia para a fila os agendamentos vencidos; deve rodar via
+// StartWorker. O que a fila recusar volta para o agendamento e é tentado no
+// próximo ciclo; um envelope ilegível vai para o dead-letter.
+func (p *PaymentService) ReleaseDue(ctx context.Context) error {
+	due, err := p.schedules.ClaimDue(ctx, time.Now().UTC(), config.Env.Schedule.Lease, config.Env.Schedule.Batch)
+	if err != nil {
+		return err
+	}
+
+	var sendErr error
+	for _, payment := range due {
+		if sendErr == nil {
+			msg, err := workers.DecodeEnvelope(payment.Payload)
+			if err != nil {
+				log.Printf("Agendamento %s descartado: %v", payment.CorrelationId, err)
+				// Sem o dead-letter, o lease o libera de novo no próximo ciclo.
+				if err := p.deadLetters.Insert(ctx, models.DeadLetter{
+					CorrelationId: payment.CorrelationId,
+					Amount:        payment.Amount,
+					Reason:        fmt.Sprintf("agendamento ilegível: %v", err),
+					TenantId:      payment.TenantId,
+					CreatedAt:     time.Now().UTC(),
+				}); err != nil {
+					log.Printf("Erro ao gravar dead-letter do agendamento %s: %v", payment.CorrelationId, err)
+				}
+				continue
+			}
+
+			// Com o correlationId em andamento para outro tenant, o RunQueue
+			// manda o agendamento para o dead-letter.
+			created, _ := p.TrackQueued(msg)
+			if sendErr = p.queue.Send(msg); sendErr == nil {
+				continue
+			}
+			if created {
+				p.Untrack(payment.CorrelationId)
+			}
+			msg.Release()
+		}
+
+		if err := p.schedules.Unclaim(ctx, payment.CorrelationId); err != nil {
+			return err
+		}
+	}
+
+	return sendErr
+}
+
+// CancelScheduled cancela um agendamento ainda não liberado e devolve o seu
+// estado depois da tentativa: cancelled (agora ou antes) ou released, quando
+// o pagamento já foi para a fila.
+func (p *PaymentService) CancelScheduled(ctx context.Context, correlationId, tenantId string) (string, bool, error) {
+	if !isUUID(correlationId) {
+		return "", false, nil
+	}
+
+	cancelled, err := p.schedules.Cancel(ctx, correlationId, tenantId)
+	if err != nil || cancelled {
+		return models.PaymentCancelled, cancelled, err
+	}
+
+	payment, found, err := p.schedules.Get(ctx, correlationId)
+	if err != nil || !found || (tenantId != "" && payment.TenantId != tenantId) {
+		return "", false, err
+	}
+
+	return payment.Status, true, nil
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Patrignani/patrignani-rinha-backend-go/internal/ratelimit"
+	"github.com/Patrignani/patrignani-rinha-backend-go/internal/repositories"
+)
+
+// Tenant é um produto atendido pela instância, com o seu limite de taxa e a
+// sua cota diária. A cota é contada localmente e alinhada com o banco a cada
+// Refresh, então entre instâncias ela é aproximada.
+type Tenant struct {
+	ID     string
+	bucket *ratelimit.Bucket
+
+	mu    sync.Mutex
+	quota int64
+	day   time.Time
+	used  int64
+}
+
+// Admit aplica o limite de taxa e a cota diária a uma requisição.
+func (t *Tenant) Admit(now time.Time) error {
+	return t.AdmitN(now, 1)
+}
+
+// AdmitN admite um lote de n pagamentos, que conta como n na taxa e na cota.
+func (t *Tenant) AdmitN(now time.Time, n int) error {
+	if ok, wait := t.bucket.AllowN(now, n); !ok {
+		return &ratelimit.LimitError{Reason: fmt.Sprintf("taxa do tenant %s", t.ID), RetryAfter: wait}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.rollover(now)
+	if t.quota > 0 && t.used+int64(n) > t.quota {
+		t.bucket.Return(n)
+		return &ratelimit.LimitError{Reason: fmt.Sprintf("cota diária do tenant %s", t.ID), RetryAfter: t.day.AddDate(0, 0, 1).Sub(now)}
+	}
+	t.used += int64(n)
+	return nil
+}
+
+// Refund devolve a cota de uma requisição admitida que não entrou na fila.
+func (t *Tenant) Refund() {
+	t.RefundN(1)
+}
+
+func (t *Tenant) RefundN(n int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.used = max(t.used-int64(n), 0)
+}
+
+func (t *Tenant) rollover(now time.Time) {
+	if day := now.UTC().Truncate(24 * time.Hour); !day.Equal(t.day) {
+		t.day = day
+		t.used = 0
+	}
+}
+
+// TenantService autentica as requisições pelo header Authorization. As chaves
+// ficam na tabela api_keys (sha256 em hex) e são carregadas em memória por
+// Refresh, para que a verificação no loop do gnet não vá ao banco.
+type TenantService struct {
+	repo    *repositories.APIKeyRepository
+	keys    atomic.Pointer[map[[sha256.Size]byte]*Tenant]
+	tenants map[string]*Tenant
+}
+
+func NewTenantService(repo *repositories.APIKeyRepository) *TenantService {
+	t := &TenantService{repo: repo, tenants: map[string]*Tenant{}}
+	t.keys.Store(&map[[sha256.Size]byte]*Tenant{})
+	return t
+}
+
+// Refresh recarrega as chaves e alinha as cotas; deve rodar via StartWorker.
+// Tenants já conhecidos são reaproveitados para não zerar os seus buckets.
+func (t *TenantService) Refresh(ctx context.Context) error {
+	apiKeys, err := t.repo.ListActive(ctx)
+	if err != nil {
+		return fmt.Errorf("erro ao carregar api keys: %w", err)
+	}
+
+	now := time.Now()
+	counts, err := t.repo.CountByTenant(ctx, now.UTC().Truncate(24*time.Hour))
+	if err != nil {
+		return fmt.Errorf("erro ao contar uso dos tenants: %w", err)
+	}
+
+	keys := make(map[[sha256.Size]byte]*Tenant, len(apiKeys))
+	for _, apiKey := range apiKeys {
+		var hash [sha256.Size]byte
+		if n, err := hex.Decode(hash[:], []byte(apiKey.KeyHash)); err != nil || n != sha256.Size {
+			log.Printf("API key inválida para o tenant %s", apiKey.TenantId)
+			continue
+		}
+
+		tenant, ok := t.tenants[apiKey.TenantId]
+		if !ok {
+			tenant = &Tenant{ID: apiKey.TenantId, bucket: ratelimit.NewBucket(apiKey.RatePerSecond, apiKey.Burst)}
+			t.tenants[apiKey.TenantId] = tenant
+		} else {
+			tenant.bucket.SetLimit(apiKey.RatePerSecond, apiKey.Burst)
+		}
+
+		tenant.mu.Lock()
+		tenant.quota = apiKey.DailyQuota
+		tenant.rollover(now)
+		tenant.used = max(tenant.used, counts[apiKey.TenantId])
+		tenant.mu.Unlock()
+
+		keys[hash] = tenant
+	}
+
+	t.keys.Store(&keys)
+	return nil
+}
+
+// Authenticate aceita "Bearer <chave>" ou a chave pura.
+func (t *TenantService) Authenticate(header []byte) (*Tenant, bool) {
+	key := bytes.TrimSpace(header)
+	if len(key) > 7 && bytes.EqualFold(key[:7], []byte("bearer ")) {
+		key = bytes.TrimSpace(key[7:])
+	}
+	if len(key) == 0 {
+		return nil, false
+	}
+
+	tenant, ok := (*t.keys.Load())[sha256.Sum256(key)]
+	return tenant, ok
+}
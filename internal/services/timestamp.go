@@ -0,0 +1,48 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Patrignani/patrignani-rinha-backend-go/internal/workers"
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/config"
+)
+
+// Modos de PAYMENT_TIMESTAMP: qual instante vale como requestedAt do
+// pagamento, enviado ao processador e gravado em entry_history, e portanto
+// qual instante os filtros from/to do resumo usam.
+const (
+	// TimestampIntake usa a chegada da requisição no servidor; não muda com
+	// fila, retry ou spill.
+	TimestampIntake = "intake"
+	// TimestampDispatch usa o momento em que o worker tira a mensagem da fila.
+	TimestampDispatch = "dispatch"
+	// TimestampAck usa o instante de cada chamada ao processador; o que
+	// confirmou é o enviado e o gravado.
+	TimestampAck = "ack"
+)
+
+func ValidateTimestampMode(mode string) error {
+	switch mode {
+	case TimestampIntake, TimestampDispatch, TimestampAck:
+		return nil
+	}
+	return fmt.Errorf("PAYMENT_TIMESTAMP inválido: %q", mode)
+}
+
+// callTime devolve o requestedAt de uma chamada ao processador.
+func callTime(createdAt time.Time) time.Time {
+	if config.Env.Payment.Timestamp == TimestampAck {
+		return time.Now().UTC()
+	}
+	return createdAt
+}
+
+// requestedAt devolve o timestamp enviado ao processador. Mensagens sem
+// instante de chegada (replay) usam o instante atual.
+func requestedAt(msg *workers.Envelope) time.Time {
+	if config.Env.Payment.Timestamp == TimestampIntake && !msg.RequestedAt.IsZero() {
+		return msg.RequestedAt.UTC()
+	}
+	return time.Now().UTC()
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/models"
+)
+
+// Tracker guarda em memória o estado dos pagamentos em andamento e o histórico
+// de tentativas. Pagamentos finalizados (gravados ou em dead-letter) ficam
+// por ttl e depois só são consultados no banco. Os em andamento sem mudança
+// há staleTTL também saem: a mensagem pode ter se perdido num restart ou sido
+// drenada, e a entrada ficaria para sempre.
+type Tracker struct {
+	ttl      time.Duration
+	staleTTL time.Duration
+	mu       sync.Mutex
+	payments map[string]*models.PaymentStatus
+}
+
+// NewTracker devolve nil com ttl zero; todos os métodos aceitam um Tracker nil.
+// staleTTL zero mantém os pagamentos em andamento até terminarem.
+func NewTracker(ttl, staleTTL time.Duration) *Tracker {
+	if ttl <= 0 {
+		return nil
+	}
+	return &Tracker{ttl: ttl, staleTTL: staleTTL, payments: map[string]*models.PaymentStatus{}}
+}
+
+// update aplica fn ao pagamento, criando-o se create for verdadeiro.
+func (t *Tracker) update(correlationId string, create bool, fn func(*models.PaymentStatus)) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	payment, ok := t.payments[correlationId]
+	if !ok {
+		if !create {
+			return
+		}
+		correlationId = strings.Clone(correlationId)
+		payment = &models.PaymentStatus{CorrelationId: correlationId}
+		t.payments[correlationId] = payment
+	}
+
+	fn(payment)
+	payment.UpdatedAt = time.Now().UTC()
+}
+
+// Queued devolve ErrCorrelationConflict, sem mudar nada, se o correlationId
+// já está com outro tenant, e created quando a entrada foi criada agora.
+func (t *Tracker) Queued(correlationId, tenantId string, amount float64, requestedAt time.Time) (created bool, err error) {
+	if t == nil {
+		return false, nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	payment, ok := t.payments[correlationId]
+	if ok && payment.TenantId != tenantId {
+		return false, ErrCorrelationConflict
+	}
+	if !ok {
+		correlationId = strings.Clone(correlationId)
+		payment = &models.PaymentStatus{CorrelationId: correlationId, TenantId: strings.Clone(tenantId)}
+		t.payments[correlationId] = payment
+	}
+
+	payment.Status = models.PaymentQueued
+	payment.Amount = amount
+	if !requestedAt.IsZero() {
+		requestedAt = requestedAt.UTC()
+		payment.RequestedAt = &requestedAt
+	}
+	payment.UpdatedAt = time.Now().UTC()
+	return !ok, nil
+}
+
+func (t *Tracker) Forget(correlationId string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	delete(t.payments, correlationId)
+	t.mu.Unlock()
+}
+
+func (t *Tracker) Processing(correlationId string, amount float64) {
+	t.update(correlationId, true, func(s *models.PaymentStatus) {
+		s.Status = models.PaymentProcessing
+		s.Amount = amount
+	})
+}
+
+func (t *Tracker) Attempt(correlationId string, attempt models.PaymentAttempt) {
+	t.update(correlationId, false, func(s *models.PaymentStatus) {
+		s.Attempts = append(s.Attempts, attempt)
+	})
+}
+
+func (t *Tracker) Processed(correlationId, processor, strategy string, processedAt time.Time) {
+	t.update(correlationId, false, func(s *models.PaymentStatus) {
+		s.Status = models.PaymentProcessed
+		s.Processor = processor
+		s.Strategy = strategy
+		s.ProcessedAt = &processedAt
+	})
+}
+
+func (t *Tracker) Failed(correlationId string, reason string) {
+	t.update(correlationId, false, func(s *models.PaymentStatus) {
+		s.Status = models.PaymentFailed
+		s.Reason = reason
+	})
+}
+
+func (t *Tracker) DeadLettered(correlationId, processor, reason string) {
+	t.update(correlationId, false, func(s *models.PaymentStatus) {
+		s.Status = models.PaymentDeadLettered
+		s.Processor = processor
+		s.Reason = reason
+	})
+}
+
+// Get devolve uma cópia do estado; tenantId vazio ignora o tenant.
+func (t *Tracker) Get(correlationId, tenantId string) (models.PaymentStatus, bool) {
+	if t == nil {
+		return models.PaymentStatus{}, false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	payment, ok := t.payments[correlationId]
+	if !ok || (tenantId != "" && payment.TenantId != tenantId) {
+		return models.PaymentStatus{}, false
+	}
+
+	status := *payment
+	status.Attempts = append([]models.PaymentAttempt(nil), status.Attempts...)
+	return status, true
+}
+
+// Sweep remove os pagamentos finalizados há mais de ttl e os em andamento
+// parados há mais de staleTTL; deve rodar via StartWorker.
+func (t *Tracker) Sweep(ctx context.Context) error {
+	now := time.Now().UTC()
+	cutoff := now.Add(-t.ttl)
+	staleCutoff := now.Add(-t.staleTTL)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for id, payment := range t.payments {
+		switch payment.Status {
+		case models.PaymentProcessed, models.PaymentDeadLettered:
+			if payment.UpdatedAt.Before(cutoff) {
+				delete(t.payments, id)
+			}
+		default:
+			if t.staleTTL > 0 && payment.UpdatedAt.Before(staleCutoff) {
+				delete(t.payments, id)
+			}
+		}
+	}
+
+	return nil
+}
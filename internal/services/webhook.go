@@ -0,0 +1,260 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Patrignani/patrignani-rinha-backend-go/internal/repositories"
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/config"
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/models"
+	"github.com/valyala/fasthttp"
+)
+
+var (
+	ErrInvalidCallback = errors.New("callbackUrl inválido")
+	errBlockedAddress  = errors.New("endereço do callback não permitido")
+)
+
+// blockedPrefixes completa os testes de netip.Addr com faixas que também não
+// são destinos públicos: "esta rede", CGNAT e benchmark.
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+}
+
+// WebhookService avisa o cliente do resultado final de um pagamento ou
+// estorno. Cada aviso é gravado em webhook_deliveries antes de ser enviado, e
+// Deliver (via StartWorker) envia os pendentes com backoff exponencial até
+// WEBHOOK_MAX_ATTEMPTS; a tabela é também o log das entregas.
+type WebhookService struct {
+	repo   *repositories.WebhookRepository
+	client *fasthttp.Client
+	cfg    config.Webhook
+}
+
+// NewWebhookService devolve nil com WEBHOOK_INTERVAL zero; Notify aceita um
+// WebhookService nil. Sem WEBHOOK_SECRET os avisos não teriam assinatura, e o
+// serviço se recusa a subir.
+func NewWebhookService(repo *repositories.WebhookRepository, cfg config.Webhook) (*WebhookService, error) {
+	if cfg.Interval <= 0 {
+		return nil, nil
+	}
+	if cfg.Secret == "" {
+		return nil, errors.New("WEBHOOK_SECRET é obrigatório com os webhooks ligados")
+	}
+
+	w := &WebhookService{repo: repo, cfg: cfg}
+	w.client = &fasthttp.Client{
+		ReadTimeout:     cfg.Timeout,
+		WriteTimeout:    cfg.Timeout,
+		MaxConnsPerHost: 64,
+		Dial:            w.dial,
+	}
+	return w, nil
+}
+
+// ValidateCallbackURL aceita apenas URLs http(s) absolutas de até 2048 bytes,
+// sem IP interno no host; nomes são conferidos ao conectar, por dial.
+func ValidateCallbackURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || len(raw) > 2048 {
+		return fmt.Errorf("%w: %q", ErrInvalidCallback, raw)
+	}
+	if addr, err := netip.ParseAddr(u.Hostname()); err == nil && !config.Env.Webhook.AllowPrivate && blockedAddr(addr) {
+		return fmt.Errorf("%w: %q", ErrInvalidCallback, raw)
+	}
+	return nil
+}
+
+// blockedAddr recusa loopback, redes privadas, link-local (que inclui o
+// metadata das nuvens, 169.254.169.254), multicast e afins.
+func blockedAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() || addr.IsMulticast() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() {
+		return true
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// dial resolve o host e conecta no próprio endereço conferido, para que uma
+// segunda resolução (DNS rebinding) não leve a conexão para a rede interna.
+func (w *WebhookService) dial(addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), w.cfg.Timeout)
+	defer cancel()
+
+	ips, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return nil, err
+	}
+
+	var dialer net.Dialer
+	for _, ip := range ips {
+		if !w.cfg.AllowPrivate && blockedAddr(ip) {
+			continue
+		}
+		return dialer.DialContext(ctx, "tcp", net.JoinHostPort(ip.Unmap().String(), port))
+	}
+	return nil, fmt.Errorf("%w: %s", errBlockedAddress, host)
+}
+
+// SignWebhook é a assinatura enviada em X-Webhook-Signature: HMAC-SHA256, com
+// WEBHOOK_SECRET, de "<X-Webhook-Timestamp>.<corpo>", em hex.
+func SignWebhook(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Notify registra o aviso para callbackUrl; a gravação é feita em segundo
+// plano e os campos de event são copiados, então podem ser views de um
+// envelope.
+func (w *WebhookService) Notify(ctx context.Context, callbackUrl string, event models.WebhookEvent) {
+	if w == nil || callbackUrl == "" {
+		return
+	}
+
+	now := time.Now().UTC()
+	event.Id = newUUID()
+	event.CorrelationId = strings.Clone(event.CorrelationId)
+	event.RefundId = strings.Clone(event.RefundId)
+	event.Currency = strings.Clone(event.Currency)
+	event.OccurredAt = now
+
+	payload, err := event.MarshalJSON()
+	if err != nil {
+		println(fmt.Sprintf("Erro ao montar webhook %s: %v", event.CorrelationId, err))
+		return
+	}
+
+	delivery := models.WebhookDelivery{
+		EventId:       event.Id,
+		Event:         event.Event,
+		CorrelationId: event.CorrelationId,
+		Url:           strings.Clone(callbackUrl),
+		Payload:       string(payload),
+		TenantId:      event.TenantId,
+		CreatedAt:     now,
+	}
+
+	go func() {
+		if err := w.repo.Insert(context.WithoutCancel(ctx), delivery); err != nil {
+			println(fmt.Sprintf("Erro ao gravar webhook %s: %v", delivery.CorrelationId, err))
+		}
+	}()
+}
+
+// Deliver envia, em paralelo, as entregas vencidas; deve rodar via
+// StartWorker.
+func (w *WebhookService) Deliver(ctx context.Context) error {
+	// O lease cobre a tentativa mais lenta possível do lote com folga.
+	deliveries, err := w.repo.Claim(ctx, time.Now().UTC(), 2*w.cfg.Timeout+time.Second, w.cfg.Batch)
+	if err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	for _, delivery := range deliveries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.attempt(ctx, delivery)
+		}()
+	}
+	wg.Wait()
+
+	return nil
+}
+
+func (w *WebhookService) attempt(ctx context.Context, delivery models.WebhookDelivery) {
+	attempts := delivery.Attempts + 1
+	statusCode, err := w.send(delivery)
+	if err == nil && (statusCode < 200 || statusCode >= 300) {
+		err = fmt.Errorf("HTTP %d", statusCode)
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	switch {
+	case err == nil:
+		err = w.repo.Delivered(ctx, delivery.Id, attempts, statusCode, time.Now().UTC())
+	case attempts >= w.cfg.MaxAttempts:
+		println(fmt.Sprintf("Webhook %d para %s descartado após %d tentativas: %v", delivery.Id, delivery.Url, attempts, err))
+		err = w.repo.Fail(ctx, delivery.Id, attempts, statusCode, err.Error())
+	default:
+		err = w.repo.Reschedule(ctx, delivery.Id, attempts, statusCode, err.Error(), time.Now().UTC().Add(w.backoff(attempts)))
+	}
+
+	if err != nil {
+		println(fmt.Sprintf("Erro ao registrar entrega do webhook %d: %v", delivery.Id, err))
+	}
+}
+
+func (w *WebhookService) send(delivery models.WebhookDelivery) (int, error) {
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+
+	payload := []byte(delivery.Payload)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req.SetRequestURI(delivery.Url)
+	req.Header.SetMethod(fasthttp.MethodPost)
+	req.Header.SetContentType("application/json")
+	req.Header.Set("X-Webhook-Id", delivery.EventId)
+	req.Header.Set("X-Webhook-Event", delivery.Event)
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	req.Header.Set("X-Webhook-Signature", SignWebhook(w.cfg.Secret, timestamp, payload))
+	req.SetBodyRaw(payload)
+
+	if err := w.client.DoTimeout(req, resp, w.cfg.Timeout); err != nil {
+		return 0, err
+	}
+
+	return resp.StatusCode(), nil
+}
+
+// backoff dobra a espera a cada tentativa, até WEBHOOK_MAX_BACKOFF, com até
+// 20% de jitter para não sincronizar os reenvios.
+func (w *WebhookService) backoff(attempts int) time.Duration {
+	// Dobra comparando com o teto antes, para que um Backoff grande não
+	// estoure o time.Duration.
+	wait := min(w.cfg.Backoff, w.cfg.MaxBackoff)
+	for range attempts - 1 {
+		if wait > w.cfg.MaxBackoff/2 {
+			wait = w.cfg.MaxBackoff
+			break
+		}
+		wait *= 2
+	}
+	return wait + time.Duration(rand.Int64N(int64(wait)/5+1))
+}
+
+func (w *WebhookService) Deliveries(ctx context.Context, correlationId, status string, limit int) (models.WebhookDeliveries, error) {
+	return w.repo.List(ctx, correlationId, status, limit)
+}
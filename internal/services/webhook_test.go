@@ -0,0 +1,132 @@
+package services
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/config"
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/models"
+)
+
+func TestWebhookSendSignsPayload(t *testing.T) {
+	const secret = "segredo"
+
+	received := make(chan *http.Request, 1)
+	bodies := make(chan []byte, 1)
+	stub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- r
+		bodies <- body
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer stub.Close()
+
+	w, _ := NewWebhookService(nil, config.Webhook{Interval: time.Second, Secret: secret, Timeout: time.Second, AllowPrivate: true})
+	delivery := models.WebhookDelivery{
+		EventId: "0f7e7bd1-3c7b-4a40-9d0e-0b5c1c3bb2a1",
+		Event:   models.WebhookPaymentProcessed,
+		Url:     stub.URL + "/hooks",
+		Payload: `{"event":"payment.processed","correlationId":"4a7901b8-7d26-4d9d-aa19-4dc1c7cf60b3"}`,
+	}
+
+	statusCode, err := w.send(delivery)
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if statusCode != http.StatusNoContent {
+		t.Fatalf("status = %d, esperado %d", statusCode, http.StatusNoContent)
+	}
+
+	r, body := <-received, <-bodies
+	if r.Method != http.MethodPost || r.URL.Path != "/hooks" {
+		t.Fatalf("requisição = %s %s", r.Method, r.URL.Path)
+	}
+	if string(body) != delivery.Payload {
+		t.Fatalf("corpo = %s", body)
+	}
+	if r.Header.Get("X-Webhook-Id") != delivery.EventId || r.Header.Get("X-Webhook-Event") != delivery.Event {
+		t.Fatalf("headers = %v", r.Header)
+	}
+
+	want := SignWebhook(secret, r.Header.Get("X-Webhook-Timestamp"), body)
+	if got := r.Header.Get("X-Webhook-Signature"); got != want {
+		t.Fatalf("assinatura = %s, esperada %s", got, want)
+	}
+}
+
+func TestWebhookSendReportsServerError(t *testing.T) {
+	stub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer stub.Close()
+
+	w, _ := NewWebhookService(nil, config.Webhook{Interval: time.Second, Secret: "segredo", Timeout: time.Second, AllowPrivate: true})
+	statusCode, err := w.send(models.WebhookDelivery{Url: stub.URL, Payload: `{}`})
+	if err != nil || statusCode != http.StatusBadGateway {
+		t.Fatalf("send = %d, %v", statusCode, err)
+	}
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	w, _ := NewWebhookService(nil, config.Webhook{Interval: time.Second, Secret: "segredo", Backoff: time.Second, MaxBackoff: time.Minute})
+
+	for attempts, base := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 4: 8 * time.Second, 7: time.Minute, 100: time.Minute} {
+		if wait := w.backoff(attempts); wait < base || wait > base+base/5 {
+			t.Fatalf("backoff(%d) = %v, esperado entre %v e %v", attempts, wait, base, base+base/5)
+		}
+	}
+}
+
+func TestWebhookBackoffLargeBaseDoesNotOverflow(t *testing.T) {
+	w, _ := NewWebhookService(nil, config.Webhook{Interval: time.Second, Secret: "segredo", Backoff: 5 * time.Second, MaxBackoff: 24 * time.Hour})
+
+	for _, attempts := range []int{20, 32, 40, 1000} {
+		if wait := w.backoff(attempts); wait < 24*time.Hour || wait > 24*time.Hour+24*time.Hour/5 {
+			t.Fatalf("backoff(%d) = %v, esperado o teto de 24h", attempts, wait)
+		}
+	}
+}
+
+func TestWebhookSendRejectsInternalAddress(t *testing.T) {
+	stub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("callback interno recebeu a requisição")
+	}))
+	defer stub.Close()
+
+	w, _ := NewWebhookService(nil, config.Webhook{Interval: time.Second, Secret: "segredo", Timeout: time.Second})
+	if _, err := w.send(models.WebhookDelivery{Url: stub.URL, Payload: `{}`}); !errors.Is(err, errBlockedAddress) {
+		t.Fatalf("send = %v, esperado %v", err, errBlockedAddress)
+	}
+}
+
+func TestWebhookRequiresSecret(t *testing.T) {
+	if _, err := NewWebhookService(nil, config.Webhook{Interval: time.Second}); err == nil {
+		t.Fatal("webhooks ligados sem WEBHOOK_SECRET")
+	}
+}
+
+func TestBlockedAddr(t *testing.T) {
+	for addr, blocked := range map[string]bool{
+		"127.0.0.1":        true,
+		"10.1.2.3":         true,
+		"172.16.0.1":       true,
+		"192.168.0.10":     true,
+		"169.254.169.254":  true,
+		"100.64.0.1":       true,
+		"0.0.0.0":          true,
+		"::1":              true,
+		"fd00:ec2::254":    true,
+		"::ffff:127.0.0.1": true,
+		"8.8.8.8":          false,
+		"2606:4700::1111":  false,
+	} {
+		if got := blockedAddr(netip.MustParseAddr(addr)); got != blocked {
+			t.Errorf("blockedAddr(%s) = %v, esperado %v", addr, got, blocked)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/metrics"
+)
+
+var (
+	ErrBacklogFull = errors.New("fila acima da capacidade")
+	ErrMemoryLimit = errors.New("memória acima do limite")
+)
+
+type AdmissionConfig struct {
+	MaxBacklog   int
+	MaxHeapBytes uint64
+	Spill        *DiskSpill
+}
+
+var memorySamples = []metrics.Sample{
+	{Name: "/memory/classes/total:bytes"},
+	{Name: "/memory/classes/heap/released:bytes"},
+}
+
+func (q *QueueWorker) SetAdmission(cfg AdmissionConfig) {
+	q.admission = cfg
+}
+
+// SampleMemory atualiza a memória usada pelo runtime, usada pela admissão.
+// Deve ser executado periodicamente via StartWorker.
+func (q *QueueWorker) SampleMemory(ctx context.Context) error {
+	samples := make([]metrics.Sample, len(memorySamples))
+	copy(samples, memorySamples)
+	metrics.Read(samples)
+
+	q.memory.Store(samples[0].Value.Uint64() - samples[1].Value.Uint64())
+	return nil
+}
+
+// admit reserva lugar para mais n mensagens. A reserva é feita com CAS sobre
+// q.backlog, que conta as mensagens na fila e as já reservadas, para que
+// envios concorrentes não passem juntos de MaxBacklog; quem reservou e não
+// enfileirou devolve a reserva com unreserve.
+func (q *QueueWorker) admit(n int) error {
+	if q.admission.MaxHeapBytes > 0 && q.memory.Load() >= q.admission.MaxHeapBytes {
+		return ErrMemoryLimit
+	}
+
+	if q.admission.MaxBacklog <= 0 {
+		q.backlog.Add(int64(n))
+		return nil
+	}
+
+	for {
+		backlog := q.backlog.Load()
+		if backlog+int64(n) > int64(q.admission.MaxBacklog) {
+			return ErrBacklogFull
+		}
+		if q.backlog.CompareAndSwap(backlog, backlog+int64(n)) {
+			return nil
+		}
+	}
+}
+
+func (q *QueueWorker) unreserve(n int) {
+	q.backlog.Add(-int64(n))
+}
+
+// RestoreSpill devolve para a fila um segmento do spill quando a fila está
+// abaixo da metade da capacidade.
+func (q *QueueWorker) RestoreSpill() (int, error) {
+	spill := q.admission.Spill
+	if spill == nil || spill.Len() == 0 {
+		return 0, nil
+	}
+
+	limit := q.scheduler.capacity()
+	if q.admission.MaxBacklog > 0 {
+		limit = min(limit, q.admission.MaxBacklog)
+	}
+
+	if q.Depth() > limit/2 {
+		return 0, nil
+	}
+
+	// As mensagens restauradas passam pela admissão como qualquer outra: o
+	// que não couber volta para o spill, num segmento novo.
+	invalid, rejected := 0, 0
+	restored, err := spill.Restore(func(lane Lane, record []byte) {
+		msg, err := DecodeEnvelope(record)
+		if err != nil {
+			invalid++
+			return
+		}
+		if err := q.SendLane(lane, msg); err != nil {
+			rejected++
+			msg.Release()
+		}
+	})
+
+	if invalid > 0 || rejected > 0 {
+		err = errors.Join(err, fmt.Errorf("spill: %d registros inválidos e %d recusados pela fila foram descartados", invalid, rejected))
+	}
+	return restored - invalid - rejected, err
+}
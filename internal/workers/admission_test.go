@@ -0,0 +1,134 @@
+package workers
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func newTestQueue(buffer int, admission AdmissionConfig) *QueueWorker {
+	q := NewQueueWorker([LaneCount]LaneConfig{
+		LaneNew:    {Buffer: buffer, Weight: 1},
+		LaneRetry:  {Buffer: buffer, Weight: 1},
+		LaneReplay: {Buffer: buffer, Weight: 1},
+	})
+	q.SetAdmission(admission)
+	return q
+}
+
+func TestAdmissionMaxBacklog(t *testing.T) {
+	q := newTestQueue(10, AdmissionConfig{MaxBacklog: 3})
+
+	for i := range 3 {
+		if err := q.Send(testEnvelope(t, i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	msg := testEnvelope(t, 3)
+	if err := q.Send(msg); !errors.Is(err, ErrBacklogFull) {
+		t.Fatalf("Send acima de MaxBacklog = %v, esperado ErrBacklogFull", err)
+	}
+	msg.Release()
+
+	for _, msg := range q.Drain(2) {
+		msg.Release()
+	}
+
+	// Duas vagas: um lote de três é recusado inteiro, um de dois entra.
+	batch := []*Envelope{testEnvelope(t, 4), testEnvelope(t, 5), testEnvelope(t, 6)}
+	if err := q.SendBatch(batch); !errors.Is(err, ErrBacklogFull) {
+		t.Fatalf("SendBatch acima de MaxBacklog = %v, esperado ErrBacklogFull", err)
+	}
+	batch[2].Release()
+	if err := q.SendBatch(batch[:2]); err != nil {
+		t.Fatal(err)
+	}
+
+	if q.Depth() != 3 || q.backlog.Load() != 3 {
+		t.Fatalf("depth = %d, backlog = %d, esperado 3 e 3", q.Depth(), q.backlog.Load())
+	}
+}
+
+// Envios concorrentes reservam a vaga antes de enfileirar e não passam
+// juntos de MaxBacklog.
+func TestAdmissionMaxBacklogConcurrent(t *testing.T) {
+	const maxBacklog = 10
+	q := newTestQueue(100, AdmissionConfig{MaxBacklog: maxBacklog})
+
+	var accepted atomic.Int64
+	var wg sync.WaitGroup
+	for i := range 64 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			msg := testEnvelope(t, i)
+			if err := q.Send(msg); err != nil {
+				msg.Release()
+				return
+			}
+			accepted.Add(1)
+		}()
+	}
+	wg.Wait()
+
+	if accepted.Load() != maxBacklog || q.Depth() != maxBacklog {
+		t.Fatalf("aceitos %d, depth %d, esperado %d", accepted.Load(), q.Depth(), maxBacklog)
+	}
+}
+
+func TestAdmissionMemoryLimit(t *testing.T) {
+	q := newTestQueue(10, AdmissionConfig{MaxHeapBytes: 1 << 20})
+	q.memory.Store(2 << 20)
+
+	msg := testEnvelope(t, 0)
+	if err := q.Send(msg); !errors.Is(err, ErrMemoryLimit) {
+		t.Fatalf("Send acima do limite de memória = %v, esperado ErrMemoryLimit", err)
+	}
+	msg.Release()
+
+	// Um retry já foi aceito antes e ainda entra se a lane tiver espaço.
+	if err := q.Retry(testEnvelope(t, 1)); err != nil {
+		t.Fatalf("Retry acima do limite de memória: %v", err)
+	}
+
+	q.memory.Store(0)
+	if err := q.Send(testEnvelope(t, 2)); err != nil {
+		t.Fatal(err)
+	}
+
+	if q.Depth() != 2 || q.backlog.Load() != 2 {
+		t.Fatalf("depth = %d, backlog = %d, esperado 2 e 2", q.Depth(), q.backlog.Load())
+	}
+}
+
+func TestAdmissionSpillsAndRestores(t *testing.T) {
+	spill, err := NewDiskSpill(t.TempDir(), 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := newTestQueue(10, AdmissionConfig{MaxBacklog: 2, Spill: spill})
+
+	for i := range 5 {
+		if err := q.Send(testEnvelope(t, i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if q.Depth() != 2 || spill.Len() != 3 {
+		t.Fatalf("depth = %d, spill = %d, esperado 2 e 3", q.Depth(), spill.Len())
+	}
+
+	for _, msg := range q.Drain(2) {
+		msg.Release()
+	}
+
+	// Só duas cabem; a terceira volta para o spill num segmento novo.
+	restored, err := q.RestoreSpill()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored != 3 || q.Depth() != 2 || spill.Len() != 1 {
+		t.Fatalf("restauradas %d, depth %d, spill %d, esperado 3, 2 e 1", restored, q.Depth(), spill.Len())
+	}
+}
@@ -0,0 +1,152 @@
+package workers
+
+import (
+	"context"
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/models"
+)
+
+const (
+	decisionHold     = "hold"
+	decisionIncrease = "increase"
+	decisionDecrease = "decrease"
+)
+
+// Autoscaler ajusta a quantidade de workers da fila com AIMD: soma um worker
+// enquanto houver backlog e o processador responder bem, reduz de forma
+// multiplicativa quando a latência ou a taxa de erro passam do alvo e devolve
+// um worker por vez quando a fila fica ociosa. Observe recebe o resultado de
+// cada chamada a um processador, não o do processamento da mensagem inteira.
+type Autoscaler struct {
+	queue         *QueueWorker
+	min           int
+	max           int
+	targetLatency time.Duration
+	maxErrorRate  float64
+	backoff       float64
+
+	mu           sync.Mutex
+	limit        float64
+	samples      int64
+	errors       int64
+	latencySum   time.Duration
+	avgLatency   time.Duration
+	errorRate    float64
+	lastDecision string
+	decidedAt    time.Time
+	increases    int64
+	decreases    int64
+}
+
+func NewAutoscaler(queue *QueueWorker, minWorkers, maxWorkers int, targetLatency time.Duration, maxErrorRate float64) *Autoscaler {
+	minWorkers = max(minWorkers, 1)
+	maxWorkers = max(maxWorkers, minWorkers)
+
+	return &Autoscaler{
+		queue:         queue,
+		min:           minWorkers,
+		max:           maxWorkers,
+		targetLatency: targetLatency,
+		maxErrorRate:  maxErrorRate,
+		backoff:       0.75,
+		limit:         float64(minWorkers),
+		lastDecision:  decisionHold,
+	}
+}
+
+func (a *Autoscaler) Observe(latency time.Duration, err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.samples++
+	a.latencySum += latency
+	if err != nil {
+		a.errors++
+	}
+}
+
+func (a *Autoscaler) Adjust(ctx context.Context) error {
+	current := a.queue.Workers()
+	depth := a.queue.Depth()
+
+	a.mu.Lock()
+
+	if int(math.Round(a.limit)) != current {
+		a.limit = float64(current)
+	}
+
+	decision := decisionHold
+	if a.samples > 0 {
+		a.avgLatency = a.latencySum / time.Duration(a.samples)
+		a.errorRate = float64(a.errors) / float64(a.samples)
+
+		switch {
+		case a.errorRate > a.maxErrorRate || a.avgLatency > a.targetLatency:
+			a.limit *= a.backoff
+			decision = decisionDecrease
+		case depth > current:
+			a.limit++
+			decision = decisionIncrease
+		case depth == 0 && a.queue.InProgress() < int64(current)/2:
+			a.limit--
+			decision = decisionDecrease
+		}
+	} else if depth > 0 && current < a.min {
+		a.limit = float64(a.min)
+		decision = decisionIncrease
+	} else if depth == 0 && a.queue.InProgress() == 0 {
+		// Sem tráfego não há amostras; volta aos poucos para o mínimo.
+		a.limit--
+		decision = decisionDecrease
+	}
+
+	a.limit = math.Min(math.Max(a.limit, float64(a.min)), float64(a.max))
+	target := int(math.Round(a.limit))
+
+	if target > current {
+		a.increases++
+	} else if target < current {
+		a.decreases++
+	} else {
+		decision = decisionHold
+	}
+
+	a.lastDecision = decision
+	a.decidedAt = time.Now()
+	a.samples, a.errors, a.latencySum = 0, 0, 0
+	avgLatency, errorRate := a.avgLatency, a.errorRate
+
+	a.mu.Unlock()
+
+	if target == current {
+		return nil
+	}
+
+	log.Printf("[Autoscaler] workers %d -> %d (%s, latência média %v, taxa de erro %.2f, fila %d)",
+		current, target, decision, avgLatency, errorRate, depth)
+
+	return a.queue.SetWorkers(target)
+}
+
+func (a *Autoscaler) Stats() models.AutoscalerStats {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return models.AutoscalerStats{
+		Workers:         a.queue.Workers(),
+		Limit:           a.limit,
+		Min:             a.min,
+		Max:             a.max,
+		TargetLatencyMs: a.targetLatency.Milliseconds(),
+		AvgLatencyMs:    float64(a.avgLatency.Microseconds()) / 1000,
+		ErrorRate:       a.errorRate,
+		LastDecision:    a.lastDecision,
+		DecidedAt:       a.decidedAt,
+		Increases:       a.increases,
+		Decreases:       a.decreases,
+	}
+}
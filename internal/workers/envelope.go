@@ -0,0 +1,348 @@
+package workers
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
+
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/buffers"
+	"github.com/valyala/fastjson"
+)
+
+const envelopeVersion = 1
+
+// Kind diz o que a mensagem pede ao processador.
+type Kind byte
+
+const (
+	KindPayment Kind = iota
+	KindRefund
+)
+
+var (
+	ErrInvalidEnvelope = errors.New("envelope inválido")
+
+	envelopePool = sync.Pool{
+		New: func() interface{} {
+			return &Envelope{fields: make([]byte, 0, 128)}
+		},
+	}
+
+	parserPool fastjson.ParserPool
+)
+
+// Quote é a conversão de um pagamento para a moeda de um processador; a cotação
+// usada é gravada com o pagamento para auditoria.
+type Quote struct {
+	// Currency é a moeda enviada ao processador; vazia quando nem o pagamento
+	// nem o processador informam moeda.
+	Currency string
+	Amount   float64
+	// Rate converte o valor original para Currency, antes do arredondamento.
+	Rate float64
+	// BaseRate converte o valor original para a moeda base.
+	BaseRate float64
+	// RequestedAt é o instante enviado ao processador; quando o pagamento foi
+	// confirmado por consulta, o que o processador registrou.
+	RequestedAt time.Time
+}
+
+// Envelope é a mensagem que circula pela fila: o corpo original já parseado
+// uma única vez na entrada, junto com os metadados que o pipeline precisa.
+// Segue a mesma regra de posse do buffers.Buffer: quem recebe um *Envelope
+// recebe uma referência e precisa chamar Release ou repassá-la. CorrelationId,
+// RefundId, TraceParent, CallbackUrl e Currency apontam para memória do
+// envelope e só valem enquanto houver uma referência viva.
+type Envelope struct {
+	Body          *buffers.Buffer
+	Kind          Kind
+	CorrelationId string
+	RefundId      string
+	Amount        float64
+	RequestedAt   time.Time
+	Deadline      time.Time
+	Attempts      int
+	LastProcessor string
+	TraceParent   string
+	TenantId      string
+	CallbackUrl   string
+	// Currency é o código ISO 4217 do valor; vazio é a moeda base.
+	Currency string
+	// ScheduledAt só é usado na entrada, para decidir se o pagamento vai para
+	// o agendamento; não é codificado por AppendBinary.
+	ScheduledAt time.Time
+	// Quote é a cotação da chamada a LastProcessor cujo resultado ficou
+	// desconhecido; zero quando não há resultado pendente.
+	Quote Quote
+
+	fields []byte
+	refs   atomic.Int32
+}
+
+// NewEnvelope parseia o corpo de um pagamento e assume a sua referência,
+// inclusive em caso de erro. requestedAt zero significa instante de chegada
+// desconhecido. Um refundId no corpo é ignorado: o tipo nunca vem do cliente.
+func NewEnvelope(body *buffers.Buffer, requestedAt time.Time, traceParent []byte) (*Envelope, error) {
+	return newEnvelope(body, KindPayment, requestedAt, traceParent)
+}
+
+// NewRefundEnvelope é o NewEnvelope de um pedido de estorno, montado pelo
+// serviço, que precisa do refundId.
+func NewRefundEnvelope(body *buffers.Buffer, requestedAt time.Time, traceParent []byte) (*Envelope, error) {
+	return newEnvelope(body, KindRefund, requestedAt, traceParent)
+}
+
+// DecodeStoredJSON reconstrói uma mensagem gravada em JSON pelo próprio
+// serviço (dead-letter), em que só estornos têm refundId.
+func DecodeStoredJSON(body *buffers.Buffer, requestedAt time.Time) (*Envelope, error) {
+	var parser fastjson.Parser
+	v, err := parser.ParseBytes(body.Bytes())
+	if err != nil {
+		body.Release()
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEnvelope, err)
+	}
+	if len(v.GetStringBytes("refundId")) > 0 {
+		return NewRefundEnvelope(body, requestedAt, nil)
+	}
+	return NewEnvelope(body, requestedAt, nil)
+}
+
+func newEnvelope(body *buffers.Buffer, kind Kind, requestedAt time.Time, traceParent []byte) (*Envelope, error) {
+	parser := parserPool.Get()
+	defer parserPool.Put(parser)
+
+	v, err := parser.ParseBytes(body.Bytes())
+	if err != nil {
+		body.Release()
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEnvelope, err)
+	}
+
+	id := v.GetStringBytes("correlationId")
+	if len(id) == 0 {
+		body.Release()
+		return nil, fmt.Errorf("%w: correlationId ausente", ErrInvalidEnvelope)
+	}
+
+	var refundId []byte
+	if kind == KindRefund {
+		if refundId = v.GetStringBytes("refundId"); len(refundId) == 0 {
+			body.Release()
+			return nil, fmt.Errorf("%w: refundId ausente", ErrInvalidEnvelope)
+		}
+	}
+
+	var scheduledAt time.Time
+	if raw := v.GetStringBytes("scheduledAt"); len(raw) > 0 {
+		if scheduledAt, err = time.Parse(time.RFC3339Nano, string(raw)); err != nil {
+			body.Release()
+			return nil, fmt.Errorf("%w: scheduledAt deve estar em RFC 3339", ErrInvalidEnvelope)
+		}
+	}
+
+	e := acquireEnvelope()
+	e.Body = body
+	e.Kind = kind
+	e.Amount = v.GetFloat64("amount")
+	e.RequestedAt = requestedAt
+	e.ScheduledAt = scheduledAt
+	e.setFields(id, refundId, traceParent, v.GetStringBytes("callbackUrl"), v.GetStringBytes("currency"))
+	return e, nil
+}
+
+func acquireEnvelope() *Envelope {
+	e := envelopePool.Get().(*Envelope)
+	e.refs.Store(1)
+	return e
+}
+
+// setFields copia os campos de texto para a memória do envelope; as strings
+// são views sobre ela, então o envelope não depende mais de quem as forneceu.
+func (e *Envelope) setFields(id, refundId, traceParent, callbackUrl, currency []byte) {
+	e.fields = append(append(append(append(append(e.fields[:0], id...), refundId...), traceParent...), callbackUrl...), currency...)
+	fields := e.fields
+	e.CorrelationId, fields = view(fields[:len(id)]), fields[len(id):]
+	e.RefundId, fields = view(fields[:len(refundId)]), fields[len(refundId):]
+	e.TraceParent, fields = view(fields[:len(traceParent)]), fields[len(traceParent):]
+	e.CallbackUrl, fields = view(fields[:len(callbackUrl)]), fields[len(callbackUrl):]
+	e.Currency = view(fields)
+}
+
+func (e *Envelope) Retain() *Envelope {
+	if e.refs.Add(1) <= 1 {
+		panic("workers: Retain em envelope já liberado")
+	}
+	return e
+}
+
+func (e *Envelope) Release() {
+	refs := e.refs.Add(-1)
+	if refs > 0 {
+		return
+	}
+	if refs < 0 {
+		panic("workers: Release em envelope já liberado")
+	}
+
+	e.Body.Release()
+	*e = Envelope{fields: e.fields[:0]}
+	envelopePool.Put(e)
+}
+
+// AppendBinary codifica o envelope no formato usado pelos backends duráveis:
+//
+//	[versão:1][tipo:1][requestedAt:8][deadline:8][tentativas:4][valor:8]
+//	[id:2+n][estorno:1+n][último processador:1+n][traceparent:1+n][tenant:1+n]
+//	[callback:2+n][moeda:1+n][cotação: moeda 1+n, valor 8, taxa 8,
+//	taxa base 8, requestedAt 8][corpo:4+n]
+//
+// Instantes são nanossegundos Unix (0 quando zero) e inteiros são big-endian.
+func (e *Envelope) AppendBinary(dst []byte) []byte {
+	dst = append(dst, envelopeVersion, byte(e.Kind))
+	dst = binary.BigEndian.AppendUint64(dst, uint64(unixNano(e.RequestedAt)))
+	dst = binary.BigEndian.AppendUint64(dst, uint64(unixNano(e.Deadline)))
+	dst = binary.BigEndian.AppendUint32(dst, uint32(e.Attempts))
+	dst = binary.BigEndian.AppendUint64(dst, math.Float64bits(e.Amount))
+
+	dst = binary.BigEndian.AppendUint16(dst, uint16(len(e.CorrelationId)))
+	dst = append(dst, e.CorrelationId...)
+	dst = appendShortString(dst, e.RefundId)
+	dst = appendShortString(dst, e.LastProcessor)
+	dst = appendShortString(dst, e.TraceParent)
+	dst = appendShortString(dst, e.TenantId)
+	dst = binary.BigEndian.AppendUint16(dst, uint16(len(e.CallbackUrl)))
+	dst = append(dst, e.CallbackUrl...)
+	dst = appendShortString(dst, e.Currency)
+	dst = appendShortString(dst, e.Quote.Currency)
+	dst = binary.BigEndian.AppendUint64(dst, math.Float64bits(e.Quote.Amount))
+	dst = binary.BigEndian.AppendUint64(dst, math.Float64bits(e.Quote.Rate))
+	dst = binary.BigEndian.AppendUint64(dst, math.Float64bits(e.Quote.BaseRate))
+	dst = binary.BigEndian.AppendUint64(dst, uint64(unixNano(e.Quote.RequestedAt)))
+
+	body := e.Body.Bytes()
+	dst = binary.BigEndian.AppendUint32(dst, uint32(len(body)))
+	return append(dst, body...)
+}
+
+// DecodeEnvelope faz o inverso de AppendBinary; o envelope devolvido não
+// referencia data.
+func DecodeEnvelope(data []byte) (*Envelope, error) {
+	r := envelopeReader{data: data}
+
+	if version := r.byte(); version != envelopeVersion {
+		return nil, fmt.Errorf("%w: versão %d", ErrInvalidEnvelope, version)
+	}
+
+	kind := Kind(r.byte())
+	requestedAt := fromUnixNano(int64(r.uint64()))
+	deadline := fromUnixNano(int64(r.uint64()))
+	attempts := int(r.uint32())
+	amount := math.Float64frombits(r.uint64())
+	id := r.bytes(int(r.uint16()))
+	refundId := r.bytes(int(r.byte()))
+	lastProcessor := r.bytes(int(r.byte()))
+	traceParent := r.bytes(int(r.byte()))
+	tenantId := r.bytes(int(r.byte()))
+	callbackUrl := r.bytes(int(r.uint16()))
+	currency := r.bytes(int(r.byte()))
+	var quote Quote
+	quote.Currency = string(r.bytes(int(r.byte())))
+	quote.Amount = math.Float64frombits(r.uint64())
+	quote.Rate = math.Float64frombits(r.uint64())
+	quote.BaseRate = math.Float64frombits(r.uint64())
+	quote.RequestedAt = fromUnixNano(int64(r.uint64()))
+	body := r.bytes(int(r.uint32()))
+
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	e := acquireEnvelope()
+	e.Body = buffers.Copy(body)
+	e.Kind = kind
+	e.Amount = amount
+	e.RequestedAt = requestedAt
+	e.Deadline = deadline
+	e.Attempts = attempts
+	e.LastProcessor = string(lastProcessor)
+	e.TenantId = string(tenantId)
+	e.Quote = quote
+	e.setFields(id, refundId, traceParent, callbackUrl, currency)
+	return e, nil
+}
+
+func appendShortString(dst []byte, s string) []byte {
+	s = s[:min(len(s), math.MaxUint8)]
+	return append(append(dst, byte(len(s))), s...)
+}
+
+type envelopeReader struct {
+	data []byte
+	err  error
+}
+
+func (r *envelopeReader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n > len(r.data) {
+		r.err = fmt.Errorf("%w: registro truncado", ErrInvalidEnvelope)
+		return nil
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+func (r *envelopeReader) byte() byte {
+	if b := r.bytes(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (r *envelopeReader) uint16() uint16 {
+	if b := r.bytes(2); b != nil {
+		return binary.BigEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (r *envelopeReader) uint32() uint32 {
+	if b := r.bytes(4); b != nil {
+		return binary.BigEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (r *envelopeReader) uint64() uint64 {
+	if b := r.bytes(8); b != nil {
+		return binary.BigEndian.Uint64(b)
+	}
+	return 0
+}
+
+func unixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+func fromUnixNano(nanos int64) time.Time {
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
+
+func view(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	return unsafe.String(unsafe.SliceData(b), len(b))
+}
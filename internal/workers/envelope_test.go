@@ -0,0 +1,148 @@
+package workers
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/buffers"
+)
+
+func TestEnvelopeRoundTrip(t *testing.T) {
+	body := `{"refundId":"7d0c5f0e-5f43-4b0e-9a59-0c1f7a3a2b11","correlationId":"4a7901b8-7d26-4d9d-aa19-4dc1c7cf60b3","amount":19.9,"callbackUrl":"https://example.com/hooks","currency":"USD"}`
+	requestedAt := time.Unix(1_700_000_000, 123456789)
+
+	msg, err := NewRefundEnvelope(buffers.Copy([]byte(body)), requestedAt, []byte("00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg.Deadline = requestedAt.Add(time.Minute)
+	msg.Attempts = 3
+	msg.LastProcessor = "fallback"
+	msg.TenantId = "acme"
+	msg.Quote = Quote{Currency: "BRL", Amount: 107.86, Rate: 5.42, BaseRate: 5.42, RequestedAt: requestedAt.Add(time.Second)}
+
+	data := msg.AppendBinary(nil)
+	decoded, err := DecodeEnvelope(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer decoded.Release()
+
+	// O envelope decodificado não referencia data.
+	for i := range data {
+		data[i] = 0
+	}
+
+	checks := []struct {
+		name      string
+		got, want any
+	}{
+		{"Kind", decoded.Kind, KindRefund},
+		{"CorrelationId", decoded.CorrelationId, "4a7901b8-7d26-4d9d-aa19-4dc1c7cf60b3"},
+		{"RefundId", decoded.RefundId, "7d0c5f0e-5f43-4b0e-9a59-0c1f7a3a2b11"},
+		{"Amount", decoded.Amount, 19.9},
+		{"RequestedAt", decoded.RequestedAt.UnixNano(), requestedAt.UnixNano()},
+		{"Deadline", decoded.Deadline.UnixNano(), msg.Deadline.UnixNano()},
+		{"Attempts", decoded.Attempts, 3},
+		{"LastProcessor", decoded.LastProcessor, "fallback"},
+		{"TraceParent", decoded.TraceParent, msg.TraceParent},
+		{"TenantId", decoded.TenantId, "acme"},
+		{"CallbackUrl", decoded.CallbackUrl, "https://example.com/hooks"},
+		{"Currency", decoded.Currency, "USD"},
+		{"Quote.Currency", decoded.Quote.Currency, "BRL"},
+		{"Quote.Amount", decoded.Quote.Amount, 107.86},
+		{"Quote.Rate", decoded.Quote.Rate, 5.42},
+		{"Quote.RequestedAt", decoded.Quote.RequestedAt.UnixNano(), msg.Quote.RequestedAt.UnixNano()},
+		{"Body", string(decoded.Body.Bytes()), body},
+	}
+	msg.Release()
+
+	for _, c := range checks {
+		if c.got != c.want {
+			t.Errorf("%s = %v, esperado %v", c.name, c.got, c.want)
+		}
+	}
+}
+
+func TestEnvelopeZeroTimesRoundTrip(t *testing.T) {
+	msg, err := NewEnvelope(buffers.Copy([]byte(`{"correlationId":"4a7901b8-7d26-4d9d-aa19-4dc1c7cf60b3","amount":1}`)), time.Time{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer msg.Release()
+
+	decoded, err := DecodeEnvelope(msg.AppendBinary(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer decoded.Release()
+
+	if !decoded.RequestedAt.IsZero() || !decoded.Deadline.IsZero() || !decoded.Quote.RequestedAt.IsZero() {
+		t.Fatalf("instantes zero não sobreviveram: %v %v %v", decoded.RequestedAt, decoded.Deadline, decoded.Quote.RequestedAt)
+	}
+	if decoded.Kind != KindPayment || decoded.RefundId != "" {
+		t.Fatalf("Kind = %v, RefundId = %q", decoded.Kind, decoded.RefundId)
+	}
+}
+
+func TestDecodeEnvelopeRejectsInvalid(t *testing.T) {
+	msg := testEnvelope(t, 0)
+	data := msg.AppendBinary(nil)
+	msg.Release()
+
+	for name, record := range map[string][]byte{
+		"vazio":          nil,
+		"versão":         append([]byte{envelopeVersion + 1}, data[1:]...),
+		"truncado":       data[:len(data)-1],
+		"sem o corpo":    data[:len(data)-len(`{"correlationId":"00000000-0000-4000-8000-000000000000","amount":10}`)-4],
+		"só o cabeçalho": data[:2],
+	} {
+		if _, err := DecodeEnvelope(record); !errors.Is(err, ErrInvalidEnvelope) {
+			t.Errorf("%s: err = %v, esperado ErrInvalidEnvelope", name, err)
+		}
+	}
+}
+
+func TestEnvelopeRetainRelease(t *testing.T) {
+	msg := testEnvelope(t, 0)
+	body := msg.Body
+
+	msg.Retain()
+	msg.Release()
+	if got := body.Bytes(); len(got) == 0 {
+		t.Fatal("o corpo foi liberado com uma referência viva")
+	}
+	if msg.CorrelationId != "00000000-0000-4000-8000-000000000000" {
+		t.Fatalf("CorrelationId = %q com uma referência viva", msg.CorrelationId)
+	}
+	msg.Release()
+
+	assertPanics(t, "Release em envelope já liberado", func() { msg.Release() })
+	assertPanics(t, "Retain em envelope já liberado", func() { msg.Retain() })
+}
+
+func TestNewEnvelopeReleasesBodyOnError(t *testing.T) {
+	for name, body := range map[string]string{
+		"json inválido":     `{"correlationId":`,
+		"sem correlationId": `{"amount":10}`,
+		"scheduledAt":       `{"correlationId":"4a7901b8-7d26-4d9d-aa19-4dc1c7cf60b3","scheduledAt":"amanhã"}`,
+	} {
+		buf := buffers.Copy([]byte(body))
+		if _, err := NewEnvelope(buf, time.Time{}, nil); !errors.Is(err, ErrInvalidEnvelope) {
+			t.Errorf("%s: err = %v, esperado ErrInvalidEnvelope", name, err)
+			continue
+		}
+		assertPanics(t, name+": o corpo não foi liberado", func() { buf.Release() })
+	}
+}
+
+func assertPanics(t *testing.T, name string, fn func()) {
+	t.Helper()
+	defer func() {
+		if recover() == nil {
+			t.Errorf("%s: esperado panic", name)
+		}
+	}()
+	fn()
+}
@@ -0,0 +1,194 @@
+package workers
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/models"
+)
+
+type Lane int
+
+const (
+	LaneNew Lane = iota
+	LaneRetry
+	LaneReplay
+	LaneCount
+)
+
+var laneNames = [LaneCount]string{"new", "retry", "replay"}
+
+func (l Lane) String() string {
+	if l < 0 || l >= LaneCount {
+		return "unknown"
+	}
+	return laneNames[l]
+}
+
+type LaneConfig struct {
+	Buffer int
+	Weight int
+}
+
+// lane é um buffer circular de capacidade fixa; todo acesso passa por
+// scheduler.mu.
+type lane struct {
+	items   []queueItem
+	head    int
+	size    int
+	weight  int
+	current int
+}
+
+func (l *lane) push(item queueItem) {
+	l.items[(l.head+l.size)%len(l.items)] = item
+	l.size++
+}
+
+func (l *lane) pop() queueItem {
+	item := l.items[l.head]
+	l.items[l.head] = queueItem{}
+	l.head = (l.head + 1) % len(l.items)
+	l.size--
+	return item
+}
+
+// scheduler escolhe a próxima lane com smooth weighted round-robin entre as
+// lanes que têm mensagens, para que o backlog de retry/replay não atrase as
+// mensagens novas mas também não fique parado.
+type scheduler struct {
+	mu    sync.Mutex
+	lanes [LaneCount]*lane
+}
+
+func newScheduler(configs [LaneCount]LaneConfig) *scheduler {
+	s := &scheduler{}
+	for i, cfg := range configs {
+		s.lanes[i] = &lane{
+			items:  make([]queueItem, max(cfg.Buffer, 1)),
+			weight: max(cfg.Weight, 1),
+		}
+	}
+	return s
+}
+
+func (s *scheduler) capacity() int {
+	total := 0
+	for _, l := range s.lanes {
+		total += len(l.items)
+	}
+	return total
+}
+
+func (s *scheduler) depth() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	total := 0
+	for _, l := range s.lanes {
+		total += l.size
+	}
+	return total
+}
+
+func (s *scheduler) free(lane Lane) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l := s.lanes[lane]
+	return len(l.items) - l.size
+}
+
+func (s *scheduler) offer(item queueItem) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l := s.lanes[item.lane]
+	if l.size == len(l.items) {
+		return false
+	}
+	l.push(item)
+	return true
+}
+
+// offerAll ocupa a lane de todos os itens de uma vez, ou de nenhum.
+func (s *scheduler) offerAll(items []queueItem) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	free := [LaneCount]int{}
+	for i, l := range s.lanes {
+		free[i] = len(l.items) - l.size
+	}
+	for _, item := range items {
+		if free[item.lane]--; free[item.lane] < 0 {
+			return false
+		}
+	}
+
+	for _, item := range items {
+		s.lanes[item.lane].push(item)
+	}
+	return true
+}
+
+// next só deve ser chamado por quem já reservou um token de q.ready, o que
+// garante que existe ao menos uma mensagem em alguma lane.
+func (s *scheduler) next() (queueItem, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	total := 0
+	var best *lane
+	for _, l := range s.lanes {
+		if l.size == 0 {
+			continue
+		}
+		l.current += l.weight
+		total += l.weight
+		if best == nil || l.current > best.current {
+			best = l
+		}
+	}
+
+	if best == nil {
+		return queueItem{}, false
+	}
+
+	best.current -= total
+	return best.pop(), true
+}
+
+// oldest devolve o instante de entrada da mensagem mais antiga entre as
+// cabeças das lanes; zero com a fila vazia.
+func (s *scheduler) oldest() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var oldest time.Time
+	for _, l := range s.lanes {
+		if l.size == 0 {
+			continue
+		}
+		if at := l.items[l.head].enqueuedAt; oldest.IsZero() || at.Before(oldest) {
+			oldest = at
+		}
+	}
+	return oldest
+}
+
+func (s *scheduler) stats() []models.LaneStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := make([]models.LaneStats, 0, len(s.lanes))
+	for i, l := range s.lanes {
+		stats = append(stats, models.LaneStats{
+			Name:     Lane(i).String(),
+			Depth:    l.size,
+			Capacity: len(l.items),
+			Weight:   l.weight,
+		})
+	}
+	return stats
+}
@@ -0,0 +1,151 @@
+package workers
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/buffers"
+)
+
+func testEnvelope(t *testing.T, i int) *Envelope {
+	t.Helper()
+	body := fmt.Sprintf(`{"correlationId":"00000000-0000-4000-8000-%012d","amount":10}`, i)
+	msg, err := NewEnvelope(buffers.Copy([]byte(body)), time.Now(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
+
+func fillScheduler(s *scheduler, lane Lane, n int) {
+	for range n {
+		s.offer(queueItem{lane: lane})
+	}
+}
+
+func TestSchedulerWeightedRoundRobin(t *testing.T) {
+	s := newScheduler([LaneCount]LaneConfig{
+		LaneNew:    {Buffer: 100, Weight: 6},
+		LaneRetry:  {Buffer: 100, Weight: 3},
+		LaneReplay: {Buffer: 100, Weight: 1},
+	})
+	for lane := range LaneCount {
+		fillScheduler(s, lane, 100)
+	}
+
+	var counts [LaneCount]int
+	var order []Lane
+	for range 10 {
+		item, ok := s.next()
+		if !ok {
+			t.Fatal("next sem mensagem com as lanes cheias")
+		}
+		counts[item.lane]++
+		order = append(order, item.lane)
+	}
+
+	if counts != [LaneCount]int{6, 3, 1} {
+		t.Fatalf("contagem por lane = %v, esperado [6 3 1]", counts)
+	}
+
+	// O round-robin suave intercala as lanes em vez de esgotar a mais
+	// pesada primeiro.
+	for i := 2; i < len(order); i++ {
+		if order[i] == LaneNew && order[i-1] == LaneNew && order[i-2] == LaneNew {
+			t.Fatalf("três mensagens novas seguidas: %v", order)
+		}
+	}
+}
+
+func TestSchedulerSkipsEmptyLanes(t *testing.T) {
+	s := newScheduler([LaneCount]LaneConfig{
+		LaneNew:    {Buffer: 4, Weight: 6},
+		LaneRetry:  {Buffer: 4, Weight: 3},
+		LaneReplay: {Buffer: 4, Weight: 1},
+	})
+	fillScheduler(s, LaneReplay, 3)
+
+	for range 3 {
+		if item, ok := s.next(); !ok || item.lane != LaneReplay {
+			t.Fatalf("next = %v, %v; esperado replay", item.lane, ok)
+		}
+	}
+	if _, ok := s.next(); ok {
+		t.Fatal("next devolveu mensagem com a fila vazia")
+	}
+}
+
+func TestSchedulerLaneLimit(t *testing.T) {
+	s := newScheduler([LaneCount]LaneConfig{
+		LaneNew:   {Buffer: 2, Weight: 1},
+		LaneRetry: {Buffer: 1, Weight: 1},
+	})
+
+	if !s.offer(queueItem{lane: LaneNew}) || !s.offer(queueItem{lane: LaneNew}) {
+		t.Fatal("lane recusou mensagem com espaço")
+	}
+	if s.offer(queueItem{lane: LaneNew}) {
+		t.Fatal("lane cheia aceitou mensagem")
+	}
+	if !s.offer(queueItem{lane: LaneRetry}) {
+		t.Fatal("uma lane cheia não deve ocupar o espaço de outra")
+	}
+
+	if s.offerAll([]queueItem{{lane: LaneReplay}, {lane: LaneReplay}}) {
+		t.Fatal("offerAll aceitou um lote maior que a lane")
+	}
+	if got := s.depth(); got != 3 {
+		t.Fatalf("depth = %d depois de um offerAll recusado, esperado 3", got)
+	}
+}
+
+// Cada mensagem na fila tem exatamente um token em q.ready: um envio
+// recusado não deixa token, e cada token consumido tira uma mensagem.
+func TestQueueReadyTokens(t *testing.T) {
+	q := NewQueueWorker([LaneCount]LaneConfig{
+		LaneNew:    {Buffer: 3, Weight: 1},
+		LaneRetry:  {Buffer: 2, Weight: 1},
+		LaneReplay: {Buffer: 1, Weight: 1},
+	})
+
+	if cap(q.ready) != q.scheduler.capacity() {
+		t.Fatalf("cap(ready) = %d, capacidade = %d", cap(q.ready), q.scheduler.capacity())
+	}
+
+	for i := range 3 {
+		if err := q.Send(testEnvelope(t, i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rejected := testEnvelope(t, 3)
+	if err := q.Send(rejected); err == nil {
+		t.Fatal("lane cheia aceitou mensagem")
+	}
+	rejected.Release()
+
+	batch := []*Envelope{testEnvelope(t, 4)}
+	if err := q.SendBatch(batch); err == nil {
+		t.Fatal("lote aceito com a lane cheia")
+	}
+	batch[0].Release()
+
+	if err := q.Retry(testEnvelope(t, 5)); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(q.ready) != q.Depth() || q.Depth() != 4 {
+		t.Fatalf("tokens = %d, depth = %d, esperado 4 e 4", len(q.ready), q.Depth())
+	}
+
+	drained := q.Drain(10)
+	if len(drained) != 4 {
+		t.Fatalf("Drain devolveu %d mensagens, esperado 4", len(drained))
+	}
+	for _, msg := range drained {
+		msg.Release()
+	}
+	if len(q.ready) != 0 || q.Depth() != 0 {
+		t.Fatalf("depois do Drain: tokens = %d, depth = %d", len(q.ready), q.Depth())
+	}
+}
@@ -2,77 +2,171 @@ package workers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/models"
 )
 
+type queueItem struct {
+	msg        *Envelope
+	lane       Lane
+	enqueuedAt time.Time
+}
+
 type QueueWorker struct {
-	channel  chan []byte
-	fallback [][]byte
-	mu       sync.Mutex
+	scheduler *scheduler
+	ready     chan struct{}
+
+	workersMu sync.Mutex
+	stops     []chan struct{}
+	wg        sync.WaitGroup
+	ctx       context.Context
+	process   func(context.Context, *Envelope) error
+
+	pauseMu sync.Mutex
+	changed chan struct{}
+	paused  bool
+
+	inProgress atomic.Int64
+	processed  atomic.Int64
+	failed     atomic.Int64
+
+	admission AdmissionConfig
+	backlog   atomic.Int64
+	memory    atomic.Uint64
+	rejected  atomic.Int64
+	spilled   atomic.Int64
+
+	rateMu     sync.Mutex
+	rateAt     time.Time
+	rateCount  int64
+	throughput float64
 }
 
-func NewQueueWorker(buffer int) *QueueWorker {
+func NewQueueWorker(lanes [LaneCount]LaneConfig) *QueueWorker {
+	s := newScheduler(lanes)
+
 	return &QueueWorker{
-		channel:  make(chan []byte, buffer),
-		fallback: [][]byte{},
+		scheduler: s,
+		ready:     make(chan struct{}, s.capacity()),
+		changed:   make(chan struct{}),
+		rateAt:    time.Now(),
 	}
 }
 
-func (q *QueueWorker) Send(msg []byte) {
-	select {
-	case q.channel <- msg:
-	default:
-		q.mu.Lock()
-		q.fallback = append(q.fallback, msg)
-		q.mu.Unlock()
-		fmt.Println("Fila cheia, mensagem salva no fallback")
-	}
+func (q *QueueWorker) Send(msg *Envelope) error {
+	return q.SendLane(LaneNew, msg)
 }
 
-func (q *QueueWorker) RetryFallback() {
-	q.mu.Lock()
-	defer q.mu.Unlock()
+// Retry devolve msg para a lane de retry. Com erro a referência continua com
+// quem chamou, que deve gravá-la em outro lugar (dead-letter).
+func (q *QueueWorker) Retry(msg *Envelope) error {
+	return q.SendLane(LaneRetry, msg)
+}
 
-	newFallback := q.fallback[:0]
+// SendLane aplica a admissão antes de enfileirar. Acima da capacidade, seja
+// da admissão ou da própria lane, a mensagem vai para o spill em disco quando
+// configurado; sem spill, mensagens de retry e replay, que já foram aceitas,
+// ainda entram se a lane tiver espaço, e o resto é recusado. Nenhuma lane
+// passa do seu limite.
+// A referência de msg passa para a fila quando não há erro; em caso de erro
+// continua com quem chamou.
+func (q *QueueWorker) SendLane(lane Lane, msg *Envelope) error {
+	item := queueItem{msg: msg, lane: lane, enqueuedAt: time.Now()}
 
-	for _, msg := range q.fallback {
-		select {
-		case q.channel <- msg:
-			fmt.Println("Mensagem reprocessada do fallback")
-		default:
-			newFallback = append(newFallback, msg)
+	err := q.admit(1)
+	if err == nil {
+		if q.offer(item) {
+			return nil
 		}
+		q.unreserve(1)
+		err = ErrBacklogFull
 	}
 
-	q.fallback = newFallback
-}
-
-func (q *QueueWorker) Consume(ctx context.Context, workers int, process func(context.Context, []byte) error) {
-	var wg sync.WaitGroup
-
-	for i := 0; i < workers; i++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			for {
-				select {
-				case <-ctx.Done():
-					return
-				case msg, ok := <-q.channel:
-					if !ok {
-						return
-					}
-					if err := process(ctx, msg); err != nil {
-						fmt.Printf("Erro ao processar mensagem %v\n", err)
-					}
-				}
+	if spill := q.admission.Spill; spill != nil {
+		spillErr := spill.Append(lane, msg.AppendBinary(nil))
+		if spillErr == nil {
+			msg.Release()
+			q.spilled.Add(1)
+			return nil
+		}
+		fmt.Printf("Erro ao salvar mensagem no spill %v\n", spillErr)
+	}
+
+	if lane != LaneNew && !errors.Is(err, ErrBacklogFull) {
+		q.backlog.Add(1)
+		if q.offer(item) {
+			return nil
+		}
+		q.unreserve(1)
+	}
+
+	q.rejected.Add(1)
+	return err
+}
+
+// SendBatch enfileira msgs na lane de mensagens novas como uma unidade: ou
+// todas entram ou nenhuma, e nesse caso as referências continuam com quem
+// chamou. Um lote não vai para o spill; precisa caber na admissão e no espaço
+// livre da lane, que é verificado e ocupado sob o mesmo lock.
+func (q *QueueWorker) SendBatch(msgs []*Envelope) error {
+	err := q.admit(len(msgs))
+	if err == nil {
+		now := time.Now()
+		items := make([]queueItem, len(msgs))
+		for i, msg := range msgs {
+			items[i] = queueItem{msg: msg, lane: LaneNew, enqueuedAt: now}
+		}
+
+		if q.scheduler.offerAll(items) {
+			for range items {
+				q.ready <- struct{}{}
 			}
-		}()
+			return nil
+		}
+		q.unreserve(len(msgs))
+		err = ErrBacklogFull
+	}
+
+	q.rejected.Add(int64(len(msgs)))
+	return err
+}
+
+func (q *QueueWorker) offer(item queueItem) bool {
+	if !q.scheduler.offer(item) {
+		return false
 	}
+	q.ready <- struct{}{}
+	return true
+}
+
+// next tira a próxima mensagem da fila e a desconta do backlog; só deve ser
+// chamado por quem já reservou um token de q.ready.
+func (q *QueueWorker) next() (queueItem, bool) {
+	item, ok := q.scheduler.next()
+	if ok {
+		q.unreserve(1)
+	}
+	return item, ok
+}
+
+// Consume entrega cada envelope para process junto com a sua referência:
+// process deve liberá-lo ou repassá-lo (por exemplo, com Retry).
+func (q *QueueWorker) Consume(ctx context.Context, workers int, process func(context.Context, *Envelope) error) {
+	q.workersMu.Lock()
+	q.ctx = ctx
+	q.process = process
+	q.workersMu.Unlock()
+
+	q.SetWorkers(workers)
 
 	<-ctx.Done()
-	wg.Wait()
+	q.SetWorkers(0)
+	q.wg.Wait()
 }
 
 // func (q *QueueWorker) Consume(ctx context.Context, workers int, process func(context.Context, []byte) error) {
@@ -107,6 +201,182 @@ func (q *QueueWorker) Consume(ctx context.Context, workers int, process func(con
 // 	}
 // }
 
-func (q *QueueWorker) CountFallback() int {
-	return len(q.fallback)
+func (q *QueueWorker) SetWorkers(workers int) error {
+	if workers < 0 {
+		return fmt.Errorf("quantidade de workers inválida: %d", workers)
+	}
+
+	q.workersMu.Lock()
+	defer q.workersMu.Unlock()
+
+	if q.process == nil {
+		return fmt.Errorf("consumo da fila não iniciado")
+	}
+
+	for len(q.stops) < workers {
+		stop := make(chan struct{})
+		q.stops = append(q.stops, stop)
+		q.wg.Add(1)
+		go q.work(q.ctx, stop, q.process)
+	}
+
+	for len(q.stops) > workers {
+		last := len(q.stops) - 1
+		close(q.stops[last])
+		q.stops = q.stops[:last]
+	}
+
+	return nil
+}
+
+func (q *QueueWorker) Depth() int {
+	return q.scheduler.depth()
+}
+
+func (q *QueueWorker) InProgress() int64 {
+	return q.inProgress.Load()
+}
+
+func (q *QueueWorker) Workers() int {
+	q.workersMu.Lock()
+	defer q.workersMu.Unlock()
+	return len(q.stops)
+}
+
+func (q *QueueWorker) work(ctx context.Context, stop chan struct{}, process func(context.Context, *Envelope) error) {
+	defer q.wg.Done()
+
+	for {
+		paused, changed := q.pauseState()
+
+		var ready chan struct{}
+		if !paused {
+			ready = q.ready
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-stop:
+			return
+		case <-changed:
+		case <-ready:
+			if item, ok := q.next(); ok {
+				q.handle(ctx, item, process)
+			}
+		}
+	}
+}
+
+func (q *QueueWorker) handle(ctx context.Context, item queueItem, process func(context.Context, *Envelope) error) {
+	q.inProgress.Add(1)
+	defer q.inProgress.Add(-1)
+
+	err := process(ctx, item.msg)
+
+	if err != nil {
+		q.failed.Add(1)
+		fmt.Printf("Erro ao processar mensagem %v\n", err)
+		return
+	}
+
+	q.processed.Add(1)
+}
+
+func (q *QueueWorker) pauseState() (bool, <-chan struct{}) {
+	q.pauseMu.Lock()
+	defer q.pauseMu.Unlock()
+	return q.paused, q.changed
+}
+
+func (q *QueueWorker) setPaused(paused bool) {
+	q.pauseMu.Lock()
+	defer q.pauseMu.Unlock()
+
+	if q.paused == paused {
+		return
+	}
+	q.paused = paused
+	close(q.changed)
+	q.changed = make(chan struct{})
+}
+
+func (q *QueueWorker) Pause() {
+	q.setPaused(true)
+}
+
+func (q *QueueWorker) Resume() {
+	q.setPaused(false)
+}
+
+func (q *QueueWorker) Paused() bool {
+	paused, _ := q.pauseState()
+	return paused
+}
+
+// Drain remove até n envelopes da fila; quem chama fica com as referências.
+func (q *QueueWorker) Drain(n int) []*Envelope {
+	drained := make([]*Envelope, 0, n)
+
+	for len(drained) < n {
+		select {
+		case <-q.ready:
+			if item, ok := q.next(); ok {
+				drained = append(drained, item.msg)
+			}
+			continue
+		default:
+		}
+		break
+	}
+
+	return drained
+}
+
+func (q *QueueWorker) Stats() models.QueueStats {
+	now := time.Now()
+	depth := q.scheduler.depth()
+
+	var oldest time.Duration
+	if head := q.scheduler.oldest(); !head.IsZero() {
+		oldest = now.Sub(head)
+	}
+
+	return models.QueueStats{
+		Depth:          depth,
+		Capacity:       q.scheduler.capacity(),
+		Workers:        q.Workers(),
+		InProgress:     q.inProgress.Load(),
+		Processed:      q.processed.Load(),
+		Failed:         q.failed.Load(),
+		OldestAgeMs:    oldest.Milliseconds(),
+		ThroughputPerS: q.rate(now),
+		Paused:         q.Paused(),
+		Lanes:          q.scheduler.stats(),
+		SpillSize:      q.spillSize(),
+		Spilled:        q.spilled.Load(),
+		Rejected:       q.rejected.Load(),
+		MemoryBytes:    q.memory.Load(),
+	}
+}
+
+func (q *QueueWorker) rate(now time.Time) float64 {
+	q.rateMu.Lock()
+	defer q.rateMu.Unlock()
+
+	if elapsed := now.Sub(q.rateAt); elapsed >= time.Second {
+		done := q.processed.Load() + q.failed.Load()
+		q.throughput = float64(done-q.rateCount) / elapsed.Seconds()
+		q.rateCount = done
+		q.rateAt = now
+	}
+
+	return q.throughput
+}
+
+func (q *QueueWorker) spillSize() int64 {
+	if q.admission.Spill == nil {
+		return 0
+	}
+	return q.admission.Spill.Len()
 }
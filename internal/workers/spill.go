@@ -0,0 +1,262 @@
+package workers
+
+import (
+	"encoding/binary"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// DiskSpill guarda em disco, em segmentos append-only, as mensagens que não
+// cabem na memória. Cada segmento começa com o cabeçalho de spill_format.go e
+// cada registro é [lane:1][tamanho:4][envelope], com o envelope codificado por
+// Envelope.AppendBinary.
+type DiskSpill struct {
+	dir          string
+	segmentBytes int64
+
+	mu      sync.Mutex
+	file    *os.File
+	seq     int64
+	written int64
+	records atomic.Int64
+}
+
+// NewDiskSpill adota os segmentos que já estão em dir. Um registro cortado no
+// fim de um segmento (queda no meio de uma gravação) é descartado; um segmento
+// ilegível é renomeado para .corrupt e fica fora da fila.
+func NewDiskSpill(dir string, segmentBytes int64) (*DiskSpill, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("erro ao criar diretório de spill: %w", err)
+	}
+
+	d := &DiskSpill{dir: dir, segmentBytes: segmentBytes}
+
+	segments, err := d.segments()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, segment := range segments {
+		var seq int64
+		if _, err := fmt.Sscanf(filepath.Base(segment), "spill-%d.log", &seq); err == nil && seq > d.seq {
+			d.seq = seq
+		}
+
+		count, err := repairSegment(segment)
+		if err != nil {
+			log.Printf("Segmento de spill ilegível %s: %v", segment, err)
+			quarantine(segment)
+			continue
+		}
+		d.records.Add(count)
+	}
+
+	return d, nil
+}
+
+func (d *DiskSpill) Len() int64 {
+	return d.records.Load()
+}
+
+func (d *DiskSpill) Append(lane Lane, msg []byte) error {
+	record := make([]byte, spillRecordHeaderSize+len(msg))
+	record[0] = byte(lane)
+	binary.BigEndian.PutUint32(record[1:spillRecordHeaderSize], uint32(len(msg)))
+	copy(record[spillRecordHeaderSize:], msg)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.file == nil || d.written >= d.segmentBytes {
+		if err := d.rotate(); err != nil {
+			return err
+		}
+	}
+
+	if _, err := d.file.Write(record); err != nil {
+		// Desfaz a gravação parcial para não deixar um registro cortado no
+		// meio do segmento; se nem isso der, o próximo Append abre outro.
+		if truncErr := d.file.Truncate(d.written); truncErr != nil {
+			_ = d.closeCurrent()
+		}
+		return fmt.Errorf("erro ao gravar spill: %w", err)
+	}
+
+	d.written += int64(len(record))
+	d.records.Add(1)
+	return nil
+}
+
+// Restore lê o segmento mais antigo, entrega cada mensagem para restore e
+// remove o arquivo. Se o único segmento for o que está aberto, ele é fechado
+// antes da leitura. Um segmento corrompido é entregue até o último registro
+// íntegro e renomeado para .corrupt, com erro; ele nunca volta a ser lido.
+func (d *DiskSpill) Restore(restore func(lane Lane, msg []byte)) (int, error) {
+	d.mu.Lock()
+	segments, err := d.segments()
+	if err != nil || len(segments) == 0 {
+		d.mu.Unlock()
+		return 0, err
+	}
+
+	oldest := segments[0]
+	if d.file != nil && d.file.Name() == oldest {
+		if err := d.closeCurrent(); err != nil {
+			d.mu.Unlock()
+			return 0, err
+		}
+	}
+	d.mu.Unlock()
+
+	restored := 0
+	count, readErr := readSegment(oldest, func(lane Lane, msg []byte) {
+		restore(lane, msg)
+		restored++
+	})
+	d.records.Add(-count)
+
+	if readErr != nil {
+		quarantine(oldest)
+		d.recount()
+		return restored, fmt.Errorf("spill corrompido %s: %w", oldest, readErr)
+	}
+
+	if err := os.Remove(oldest); err != nil {
+		return restored, fmt.Errorf("erro ao remover spill: %w", err)
+	}
+
+	return restored, nil
+}
+
+// recount refaz a contagem a partir dos segmentos, depois que um deles saiu
+// da fila sem ter sido lido por inteiro.
+func (d *DiskSpill) recount() {
+	segments, err := d.segments()
+	if err != nil {
+		return
+	}
+
+	var total int64
+	for _, segment := range segments {
+		count, _ := readSegment(segment, nil)
+		total += count
+	}
+	d.records.Store(total)
+}
+func (d *DiskSpill) segments() ([]string, error) {
+	segments, err := filepath.Glob(filepath.Join(d.dir, "spill-*.log"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(segments)
+	return segments, nil
+}
+
+func (d *DiskSpill) rotate() error {
+	if err := d.closeCurrent(); err != nil {
+		return err
+	}
+
+	d.seq++
+	f, err := os.OpenFile(filepath.Join(d.dir, fmt.Sprintf("spill-%020d.log", d.seq)), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("erro ao criar segmento de spill: %w", err)
+	}
+
+	header := appendSegmentHeader(nil)
+	if _, err := f.Write(header); err != nil {
+		f.Close()
+		return fmt.Errorf("erro ao criar segmento de spill: %w", err)
+	}
+
+	d.file = f
+	d.written = int64(len(header))
+	return nil
+}
+
+func (d *DiskSpill) closeCurrent() error {
+	if d.file == nil {
+		return nil
+	}
+
+	err := d.file.Close()
+	d.file = nil
+	return err
+}
+
+// readSegment entrega a fn (quando não é nil) cada registro íntegro do
+// segmento e devolve quantos foram lidos. Um registro cortado no fim do
+// arquivo é tratado como fim; qualquer outra falha é devolvida.
+func readSegment(segment string, fn func(lane Lane, msg []byte)) (int64, error) {
+	count, _, err := scanSegment(segment, fn)
+	return count, err
+}
+
+// repairSegment corta um registro incompleto no fim do segmento e devolve
+// quantos registros íntegros ele tem.
+func repairSegment(segment string) (int64, error) {
+	count, valid, err := scanSegment(segment, nil)
+	if err != nil {
+		return count, err
+	}
+
+	info, err := os.Stat(segment)
+	if err != nil {
+		return count, err
+	}
+
+	if info.Size() > valid {
+		log.Printf("Spill %s termina com um registro incompleto; %d bytes descartados", segment, info.Size()-valid)
+		if err := os.Truncate(segment, valid); err != nil {
+			return count, err
+		}
+	}
+
+	return count, nil
+}
+
+// scanSegment lê o segmento inteiro (segmentos têm ADMISSION_SPILL_SEGMENT_KB)
+// e devolve quantos registros íntegros ele tem e onde termina o último.
+func scanSegment(segment string, fn func(lane Lane, msg []byte)) (int64, int64, error) {
+	data, err := os.ReadFile(segment)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	offset, err := segmentStart(data)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var count int64
+	for offset < len(data) {
+		lane, msg, size, ok, err := nextRecord(data[offset:])
+		if err != nil {
+			return count, int64(offset), fmt.Errorf("%w no offset %d", err, offset)
+		}
+		if !ok {
+			break
+		}
+
+		if fn != nil {
+			fn(lane, msg)
+		}
+		count++
+		offset += size
+	}
+
+	return count, int64(offset), nil
+}
+
+// quarantine tira o segmento da fila sem apagá-lo, para inspeção manual.
+func quarantine(segment string) {
+	if err := os.Rename(segment, segment+".corrupt"); err != nil {
+		log.Printf("Erro ao isolar spill %s: %v", segment, err)
+		_ = os.Remove(segment)
+	}
+}
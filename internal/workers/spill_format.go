@@ -0,0 +1,64 @@
+package workers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Segmentos de spill começam com [magic:4][versão:1], seguidos dos registros
+// [lane:1][tamanho:4][envelope].
+const (
+	spillMagic            = "RSPL"
+	spillVersion          = 1
+	spillHeaderSize       = len(spillMagic) + 1
+	spillRecordHeaderSize = 5
+)
+
+var errCorruptSegment = errors.New("segmento corrompido")
+
+func appendSegmentHeader(dst []byte) []byte {
+	return append(append(dst, spillMagic...), spillVersion)
+}
+
+// segmentStart confere o cabeçalho do segmento e devolve onde começam os
+// registros. Um cabeçalho cortado (queda logo ao criar o segmento) não tem o
+// que restaurar.
+func segmentStart(data []byte) (int, error) {
+	if len(data) < spillHeaderSize && bytes.HasPrefix([]byte(spillMagic), data) {
+		return len(data), nil
+	}
+	if !bytes.HasPrefix(data, []byte(spillMagic)) {
+		return 0, fmt.Errorf("%w: cabeçalho ausente", errCorruptSegment)
+	}
+	if version := data[len(spillMagic)]; version != spillVersion {
+		return 0, fmt.Errorf("%w: versão %d desconhecida", errCorruptSegment, version)
+	}
+	return spillHeaderSize, nil
+}
+
+// nextRecord lê o registro no início de data. ok falso sem erro é um registro
+// cortado no fim do arquivo.
+func nextRecord(data []byte) (lane Lane, msg []byte, size int, ok bool, err error) {
+	if len(data) < spillRecordHeaderSize {
+		return 0, nil, 0, false, nil
+	}
+
+	lane = Lane(data[0])
+	if lane >= LaneCount {
+		return 0, nil, 0, false, fmt.Errorf("%w: lane %d", errCorruptSegment, data[0])
+	}
+
+	size = spillRecordHeaderSize + int(binary.BigEndian.Uint32(data[1:spillRecordHeaderSize]))
+	if size > len(data) {
+		return 0, nil, 0, false, nil
+	}
+
+	msg = data[spillRecordHeaderSize:size]
+	if len(msg) == 0 || msg[0] != envelopeVersion {
+		return 0, nil, 0, false, fmt.Errorf("%w: registro inválido", errCorruptSegment)
+	}
+
+	return lane, msg, size, true, nil
+}
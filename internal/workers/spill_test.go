@@ -0,0 +1,144 @@
+package workers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeSpill grava n envelopes num spill novo em dir e fecha o segmento,
+// como numa parada da instância.
+func writeSpill(t *testing.T, dir string, n int) string {
+	t.Helper()
+
+	spill, err := NewDiskSpill(dir, 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range n {
+		msg := testEnvelope(t, i)
+		if err := spill.Append(LaneRetry, msg.AppendBinary(nil)); err != nil {
+			t.Fatal(err)
+		}
+		msg.Release()
+	}
+	if err := spill.closeCurrent(); err != nil {
+		t.Fatal(err)
+	}
+
+	segments, err := spill.segments()
+	if err != nil || len(segments) != 1 {
+		t.Fatalf("segmentos = %v, %v", segments, err)
+	}
+	return segments[0]
+}
+
+func restoreAll(t *testing.T, spill *DiskSpill) ([]string, error) {
+	t.Helper()
+
+	var ids []string
+	_, err := spill.Restore(func(lane Lane, record []byte) {
+		msg, decodeErr := DecodeEnvelope(record)
+		if decodeErr != nil {
+			t.Fatalf("registro restaurado inválido: %v", decodeErr)
+		}
+		if lane != LaneRetry {
+			t.Fatalf("lane = %v, esperado retry", lane)
+		}
+		ids = append(ids, strings.Clone(msg.CorrelationId))
+		msg.Release()
+	})
+	return ids, err
+}
+
+func TestSpillRestoreAfterTruncatedRecord(t *testing.T) {
+	dir := t.TempDir()
+	segment := writeSpill(t, dir, 3)
+
+	// Queda no meio da gravação do último registro.
+	info, err := os.Stat(segment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Truncate(segment, info.Size()-7); err != nil {
+		t.Fatal(err)
+	}
+
+	spill, err := NewDiskSpill(dir, 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spill.Len() != 2 {
+		t.Fatalf("Len = %d, esperado 2", spill.Len())
+	}
+
+	ids, err := restoreAll(t, spill)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2 || ids[0] != "00000000-0000-4000-8000-000000000000" || ids[1] != "00000000-0000-4000-8000-000000000001" {
+		t.Fatalf("restaurados %v", ids)
+	}
+	if _, err := os.Stat(segment); !os.IsNotExist(err) {
+		t.Fatalf("segmento restaurado não foi removido: %v", err)
+	}
+}
+
+func TestSpillRestoreCorruptSegment(t *testing.T) {
+	dir := t.TempDir()
+	segment := writeSpill(t, dir, 3)
+
+	spill, err := NewDiskSpill(dir, 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Corrompe a lane do segundo registro depois que o spill adotou o
+	// segmento.
+	data, err := os.ReadFile(segment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, size, ok, err := nextRecord(data[spillHeaderSize:])
+	if !ok || err != nil {
+		t.Fatalf("primeiro registro: %v, %v", ok, err)
+	}
+	data[spillHeaderSize+size] = byte(LaneCount)
+	if err := os.WriteFile(segment, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ids, err := restoreAll(t, spill)
+	if err == nil {
+		t.Fatal("Restore de segmento corrompido sem erro")
+	}
+	if len(ids) != 1 {
+		t.Fatalf("restaurados %v, esperado só o registro antes do corrompido", ids)
+	}
+	if _, err := os.Stat(segment + ".corrupt"); err != nil {
+		t.Fatalf("segmento corrompido não foi isolado: %v", err)
+	}
+	if spill.Len() != 0 {
+		t.Fatalf("Len = %d depois do isolamento, esperado 0", spill.Len())
+	}
+}
+
+func TestSpillQuarantinesSegmentWithoutHeader(t *testing.T) {
+	dir := t.TempDir()
+	segment := filepath.Join(dir, "spill-00000000000000000001.log")
+	if err := os.WriteFile(segment, []byte{byte(LaneNew), 0, 0, 0, 2, '{', '}'}, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	spill, err := NewDiskSpill(dir, 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spill.Len() != 0 {
+		t.Fatalf("Len = %d, esperado 0", spill.Len())
+	}
+	if _, err := os.Stat(segment + ".corrupt"); err != nil {
+		t.Fatalf("segmento sem cabeçalho não foi isolado: %v", err)
+	}
+}
@@ -4,9 +4,14 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"os"
 	"runtime/debug"
 	"time"
 
+	"github.com/Patrignani/patrignani-rinha-backend-go/internal/events"
+	"github.com/Patrignani/patrignani-rinha-backend-go/internal/migrations"
+	"github.com/Patrignani/patrignani-rinha-backend-go/internal/processors"
+	"github.com/Patrignani/patrignani-rinha-backend-go/internal/ratelimit"
 	"github.com/Patrignani/patrignani-rinha-backend-go/internal/repositories"
 	"github.com/Patrignani/patrignani-rinha-backend-go/internal/services"
 	"github.com/Patrignani/patrignani-rinha-backend-go/internal/workers"
@@ -33,34 +38,198 @@ func main() {
 	}
 	defer pg.Close()
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(ctx, pg, os.Args[2:]); err != nil {
+			log.Fatalf("Erro na migração: %v", err)
+		}
+		return
+	}
+
+	if config.Env.MigrateOnStart {
+		if _, err := migrations.Up(ctx, pg); err != nil {
+			panic(fmt.Errorf("erro ao migrar o banco: %w", err))
+		}
+	}
+
 	paymentRepo := repositories.NewPaymentRepository(pg)
-	queue := workers.NewQueueWorker(config.Env.Queue.Buffer)
-	paymentService := services.NewPaymentService(paymentRepo, queue)
+	queue := workers.NewQueueWorker([workers.LaneCount]workers.LaneConfig{
+		workers.LaneNew:    {Buffer: config.Env.Queue.Buffer, Weight: config.Env.Queue.NewWeight},
+		workers.LaneRetry:  {Buffer: config.Env.Queue.RetryBuffer, Weight: config.Env.Queue.RetryWeight},
+		workers.LaneReplay: {Buffer: config.Env.Queue.ReplayBuffer, Weight: config.Env.Queue.ReplayWeight},
+	})
+	processorConfigs, err := config.Env.ProcessorList()
+	if err != nil {
+		panic(fmt.Errorf("erro ao configurar processadores: %w", err))
+	}
 
-	go queue.Consume(ctx, config.Env.Queue.Workers, paymentService.RunQueue)
+	processorList := make([]processors.Processor, 0, len(processorConfigs))
+	for _, cfg := range processorConfigs {
+		processorList = append(processorList, processors.NewHTTPProcessor(cfg))
+	}
+	registry := processors.NewRegistry(processors.WindowConfig{
+		Size:   config.Env.Routing.WindowSize,
+		MaxAge: config.Env.Routing.WindowMaxAge,
+	}, processorList...)
+
+	strategy, err := processors.NewStrategy(config.Env.Routing.Strategy, registry, processors.CostModel{
+		LatencyPenalty: config.Env.Routing.LatencyPenalty,
+		FailurePenalty: config.Env.Routing.FailurePenalty,
+	})
+	if err != nil {
+		panic(err)
+	}
 
-	var paymentHandler func(ctx context.Context, body []byte)
+	if config.Env.HealthInterval > 0 {
+		workers.StartWorker(ctx, "Health", config.Env.HealthInterval, registry.CheckHealth)
+	}
 
-	if config.Env.UseQueueInPost {
-		log.Print("____queue.Send____")
-		workers.StartWorker(ctx, "Retry", 300*time.Millisecond, func(ctx context.Context) error {
-			if queue.CountFallback() > 0 {
-				queue.RetryFallback()
+	if err := services.ValidateTimestampMode(config.Env.Payment.Timestamp); err != nil {
+		panic(err)
+	}
+
+	tracker := services.NewTracker(config.Env.Payment.TrackingTTL, config.Env.Payment.TrackingStaleTTL)
+	if tracker != nil {
+		workers.StartWorker(ctx, "Tracker", config.Env.Payment.TrackingTTL, tracker.Sweep)
+	}
+
+	broker := events.NewBroker(config.Env.Events.Buffer, config.Env.Events.SubscriberBuffer)
+	relay := services.NewEventRelay(repositories.NewEventRepository(pg), broker, config.Env.Events.Buffer)
+	if relay != nil {
+		workers.StartWorker(ctx, "EventsFlush", config.Env.Events.FlushInterval, relay.Flush)
+		workers.StartWorker(ctx, "EventsListen", time.Second, relay.Listen)
+		workers.StartWorker(ctx, "EventsPrune", time.Minute, func(ctx context.Context) error {
+			return relay.Prune(ctx, config.Env.Events.Retention)
+		})
+	}
+
+	webhooks, err := services.NewWebhookService(repositories.NewWebhookRepository(pg), config.Env.Webhook)
+	if err != nil {
+		panic(err)
+	}
+	if webhooks != nil {
+		workers.StartWorker(ctx, "Webhooks", config.Env.Webhook.Interval, webhooks.Deliver)
+	}
+
+	rates := services.NewRateService(repositories.NewRateRepository(pg), config.Env.Currency.RatesFile, config.Env.Currency.Base)
+	if err := rates.Refresh(ctx); err != nil {
+		log.Printf("Cotações não carregadas, só a moeda base será aceita: %v", err)
+	}
+	if config.Env.Currency.RefreshInterval > 0 {
+		workers.StartWorker(ctx, "Rates", config.Env.Currency.RefreshInterval, rates.Refresh)
+	}
+
+	paymentService := services.NewPaymentService(paymentRepo, repositories.NewDeadLetterRepository(pg), repositories.NewRefundRepository(pg), repositories.NewBatchRepository(pg), repositories.NewScheduleRepository(pg), queue, registry, strategy, tracker, webhooks, relay, rates)
+	if config.Env.Schedule.Interval > 0 {
+		workers.StartWorker(ctx, "Scheduler", config.Env.Schedule.Interval, paymentService.ReleaseDue)
+	}
+	if relay != nil && config.Env.Events.SummaryInterval > 0 {
+		workers.StartWorker(ctx, "Events", config.Env.Events.SummaryInterval, paymentService.PublishSummary)
+	}
+
+	if config.Env.Partition.Interval > 0 {
+		partitionRepo := repositories.NewPartitionRepository(pg)
+		var archiver services.Archiver
+		if config.Env.Partition.ArchiveDir != "" {
+			if archiver, err = services.NewFileArchiver(partitionRepo, config.Env.Partition.ArchiveDir); err != nil {
+				panic(fmt.Errorf("erro ao preparar o arquivo de partições: %w", err))
 			}
-			return nil
+		}
+		partitions, err := services.NewPartitionService(partitionRepo, config.Env.Partition, archiver)
+		if err != nil {
+			panic(err)
+		}
+		if err := partitions.Maintain(ctx); err != nil {
+			log.Printf("Erro na manutenção das partições: %v", err)
+		}
+		workers.StartWorker(ctx, "Partitions", config.Env.Partition.Interval, partitions.Maintain)
+	}
+
+	reconciliationService := services.NewReconciliationService(paymentRepo, repositories.NewReconciliationRepository(pg), registry,
+		config.Env.Reconciliation.Windows, config.Env.Reconciliation.Lag, config.Env.Reconciliation.Sample,
+		config.Env.Reconciliation.Lease)
+	if config.Env.Reconciliation.Interval > 0 {
+		if config.Env.ProcessorToken == "" {
+			log.Print("PROCESSOR_ADMIN_TOKEN não definido: a reconciliação não compara os resumos dos processadores")
+		}
+		workers.StartWorker(ctx, "Reconciliation", config.Env.Reconciliation.Interval, reconciliationService.Run)
+	}
+
+	admission := workers.AdmissionConfig{
+		MaxBacklog:   config.Env.Admission.MaxBacklog,
+		MaxHeapBytes: uint64(config.Env.Admission.MaxMemoryMB) << 20,
+	}
+
+	if config.Env.Admission.SpillDir != "" {
+		admission.Spill, err = workers.NewDiskSpill(config.Env.Admission.SpillDir, int64(config.Env.Admission.SpillSegmentKB)<<10)
+		if err != nil {
+			panic(fmt.Errorf("erro ao iniciar o spill: %w", err))
+		}
+	}
+
+	queue.SetAdmission(admission)
+
+	// Os retries do RunQueue também caem no spill, então ele é lido de volta em
+	// qualquer modo do POST.
+	if admission.Spill != nil {
+		workers.StartWorker(ctx, "Retry", 300*time.Millisecond, func(ctx context.Context) error {
+			_, err := queue.RestoreSpill()
+			return err
 		})
+	}
 
-		paymentHandler = func(ctx context.Context, body []byte) {
-			queue.Send(body)
+	if admission.MaxHeapBytes > 0 {
+		workers.StartWorker(ctx, "Memory", config.Env.Admission.MemoryInterval, queue.SampleMemory)
+	}
+
+	var autoscaler *workers.Autoscaler
+	if config.Env.Queue.Autoscale {
+		autoscaler = workers.NewAutoscaler(queue, config.Env.Queue.MinWorkers, config.Env.Queue.MaxWorkers,
+			config.Env.Queue.TargetLatency, config.Env.Queue.MaxErrorRate)
+		paymentService.SetObserver(autoscaler.Observe)
+		workers.StartWorker(ctx, "Autoscaler", config.Env.Queue.AutoscaleInterval, autoscaler.Adjust)
+	}
+
+	go queue.Consume(ctx, config.Env.Queue.Workers, paymentService.RunQueue)
+
+	var paymentHandler func(ctx context.Context, msg *workers.Envelope) error
+
+	if config.Env.UseQueueInPost {
+		log.Print("____queue.Send____")
+		paymentHandler = func(ctx context.Context, msg *workers.Envelope) error {
+			return queue.Send(msg)
 		}
 	} else {
 		log.Print("____go paymentService.RunQueue____")
-		paymentHandler = func(ctx context.Context, body []byte) {
-			go paymentService.RunQueue(ctx, body)
+		paymentHandler = func(ctx context.Context, msg *workers.Envelope) error {
+			go paymentService.RunQueue(ctx, msg)
+			return nil
+		}
+	}
+
+	var tenants *services.TenantService
+	if config.Env.Auth.Enabled {
+		tenants = services.NewTenantService(repositories.NewAPIKeyRepository(pg))
+		if err := tenants.Refresh(ctx); err != nil {
+			panic(err)
 		}
+		workers.StartWorker(ctx, "Tenants", config.Env.Auth.RefreshInterval, tenants.Refresh)
+	}
+
+	rateLimit := config.Env.RateLimit
+	limiter := ratelimit.NewLimiter(
+		ratelimit.Limit{Rate: rateLimit.GlobalRate, Burst: rateLimit.GlobalBurst},
+		ratelimit.Limit{Rate: rateLimit.IPRate, Burst: rateLimit.IPBurst},
+		ratelimit.Limit{Rate: rateLimit.KeyRate, Burst: rateLimit.KeyBurst},
+		rateLimit.Instances, rateLimit.IdleTTL)
+	if limiter != nil {
+		workers.StartWorker(ctx, "RateLimit", rateLimit.IdleTTL, limiter.Sweep)
+	}
+
+	if config.Env.Admin.Enabled && config.Env.Admin.Token == "" {
+		log.Print("ADMIN_ENABLED sem ADMIN_TOKEN: as rotas /admin/* ficam desligadas")
 	}
 
-	server := servers.NewGNetServer(paymentService, true, paymentHandler)
+	server := servers.NewGNetServer(paymentService, reconciliationService, queue, autoscaler, tenants, limiter, relay, true, paymentHandler)
 
 	log.Printf(`
 	╔════════════════════════════════════════════════════╗
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/Patrignani/patrignani-rinha-backend-go/internal/migrations"
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/storage"
+)
+
+// runMigrate atende "main migrate [up | down [n] | status]"; sem argumentos,
+// aplica as migrações pendentes.
+func runMigrate(ctx context.Context, pg storage.PostgresClient, args []string) error {
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	switch command {
+	case "up":
+		done, err := migrations.Up(ctx, pg)
+		if err != nil {
+			return err
+		}
+		if len(done) == 0 {
+			fmt.Println("Nenhuma migração pendente")
+		}
+		return nil
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n <= 0 {
+				return fmt.Errorf("quantidade de migrações inválida: %q", args[1])
+			}
+			steps = n
+		}
+		_, err := migrations.Down(ctx, pg, steps)
+		return err
+
+	case "status":
+		statuses, err := migrations.List(ctx, pg)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			appliedAt := "pendente"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("uso: migrate [up | down [n] | status]")
+}
@@ -0,0 +1,75 @@
+package buffers
+
+import (
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+const maxPooledSize = 64 << 10
+
+var pool = sync.Pool{
+	New: func() interface{} {
+		return &Buffer{b: make([]byte, 0, 512)}
+	},
+}
+
+// Buffer é uma cópia própria do corpo de uma requisição, compartilhada por
+// contagem de referências entre servidor, fila e serviço. Quem recebe um
+// *Buffer recebe uma referência e precisa chamar Release (ou repassá-la);
+// quem precisa manter o conteúdo além disso chama Retain antes. Strings
+// obtidas com View só são válidas enquanto houver uma referência viva.
+type Buffer struct {
+	b    []byte
+	refs atomic.Int32
+}
+
+// Copy copia src para um buffer do pool com uma referência. É a única cópia
+// do pipeline: a partir daqui o conteúdo não depende mais do buffer do gnet.
+func Copy(src []byte) *Buffer {
+	buf := pool.Get().(*Buffer)
+	buf.b = append(buf.b[:0], src...)
+	buf.refs.Store(1)
+	return buf
+}
+
+func (b *Buffer) Bytes() []byte {
+	return b.b
+}
+
+func (b *Buffer) Len() int {
+	return len(b.b)
+}
+
+func (b *Buffer) Retain() *Buffer {
+	if b.refs.Add(1) <= 1 {
+		panic("buffers: Retain em buffer já liberado")
+	}
+	return b
+}
+
+func (b *Buffer) Release() {
+	refs := b.refs.Add(-1)
+	if refs > 0 {
+		return
+	}
+	if refs < 0 {
+		panic("buffers: Release em buffer já liberado")
+	}
+
+	if cap(b.b) > maxPooledSize {
+		return
+	}
+
+	b.b = b.b[:0]
+	pool.Put(b)
+}
+
+// View converte sub, que deve apontar para dentro de Bytes() ou para memória
+// que não é reutilizada, em string sem cópia.
+func (b *Buffer) View(sub []byte) string {
+	if len(sub) == 0 {
+		return ""
+	}
+	return unsafe.String(unsafe.SliceData(sub), len(sub))
+}
@@ -0,0 +1,111 @@
+package buffers
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+)
+
+func TestCopyIsIndependentOfSource(t *testing.T) {
+	src := []byte(`{"correlationId":"4a7901b8-7d26-4d9d-aa19-4dc1c7cf60b3","amount":19.9}`)
+	buf := Copy(src)
+	defer buf.Release()
+
+	view := buf.View(buf.Bytes()[18:54])
+
+	for i := range src {
+		src[i] = 'x'
+	}
+
+	if view != "4a7901b8-7d26-4d9d-aa19-4dc1c7cf60b3" {
+		t.Fatalf("view corrompida depois de reutilizar a origem: %q", view)
+	}
+}
+
+func TestViewSurvivesPoolReuseWhileRetained(t *testing.T) {
+	buf := Copy([]byte("11111111-1111-1111-1111-111111111111"))
+	view := buf.View(buf.Bytes())
+
+	buf.Retain()
+	buf.Release()
+
+	for i := 0; i < 100; i++ {
+		other := Copy(bytes.Repeat([]byte{'2'}, 36))
+		other.Release()
+	}
+
+	if view != "11111111-1111-1111-1111-111111111111" {
+		t.Fatalf("view corrompida com referência viva: %q", view)
+	}
+	buf.Release()
+}
+
+func TestReleaseTwicePanics(t *testing.T) {
+	buf := Copy([]byte("x"))
+	buf.Release()
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Release duplicado deveria causar panic")
+		}
+	}()
+	buf.Release()
+}
+
+func TestConcurrentRetainRelease(t *testing.T) {
+	const goroutines = 32
+
+	for i := 0; i < 200; i++ {
+		payload := []byte(`{"correlationId":"0b4f4c3e-2d7a-4c1f-9d7e-58c3b1e1f2a0","amount":10}`)
+		buf := Copy(payload)
+		want := string(payload)
+
+		var wg sync.WaitGroup
+		for g := 0; g < goroutines; g++ {
+			buf.Retain()
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer buf.Release()
+				if got := buf.View(buf.Bytes()); got != want {
+					t.Errorf("conteúdo divergente: %q", got)
+				}
+			}()
+		}
+
+		for g := 0; g < goroutines; g++ {
+			other := Copy([]byte("lixo que não pode aparecer em buf"))
+			other.Release()
+		}
+
+		buf.Release()
+		wg.Wait()
+	}
+}
+
+func FuzzCopyView(f *testing.F) {
+	f.Add([]byte(`{"correlationId":"4a7901b8-7d26-4d9d-aa19-4dc1c7cf60b3","amount":19.9}`), 18, 36)
+	f.Add([]byte(""), 0, 0)
+
+	f.Fuzz(func(t *testing.T, src []byte, off, n int) {
+		if off < 0 || n < 0 || off+n > len(src) || off+n < 0 {
+			t.Skip()
+		}
+
+		want := string(src[off : off+n])
+		buf := Copy(src)
+		view := buf.View(buf.Bytes()[off : off+n])
+
+		for i := range src {
+			src[i] ^= 0xff
+		}
+
+		other := Copy(src)
+		other.Release()
+
+		if view != want {
+			t.Fatalf("view %q, esperado %q", view, want)
+		}
+		buf.Release()
+	})
+}
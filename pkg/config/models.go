@@ -3,19 +3,156 @@ package config
 import "time"
 
 type Environment struct {
-	Postgres       Postgres
-	StartPort      string `env:"START_PORT,default=8080"`
-	Queue          Queue
-	DefaultUrl     string        `env:"DEFAULT_URL"`
-	FallbackUrl    string        `env:"FALLBACK_URL"`
-	AttempsRetry   int           `env:"ATTEMPS_RETRY"`
-	TimeAttemps    time.Duration `env:"TIME_ATTEMPS"`
-	UseQueueInPost bool          `env:"USE_QUEUE_IN_POST,default=false"`
+	Postgres         Postgres
+	StartPort        string `env:"START_PORT,default=8080"`
+	Queue            Queue
+	DefaultUrl       string        `env:"DEFAULT_URL"`
+	FallbackUrl      string        `env:"FALLBACK_URL"`
+	DefaultFee       float64       `env:"DEFAULT_FEE,default=0.05"`
+	FallbackFee      float64       `env:"FALLBACK_FEE,default=0.15"`
+	Processors       []string      `env:"PROCESSORS,separator=;"`
+	ProcessorTimeout time.Duration `env:"PROCESSOR_TIMEOUT,default=0s"`
+	ProcessorToken   string        `env:"PROCESSOR_ADMIN_TOKEN"`
+	HealthInterval   time.Duration `env:"HEALTH_CHECK_INTERVAL,default=5s"`
+	AttempsRetry     int           `env:"ATTEMPS_RETRY"`
+	TimeAttemps      time.Duration `env:"TIME_ATTEMPS"`
+	UseQueueInPost   bool          `env:"USE_QUEUE_IN_POST,default=false"`
+	BatchMaxItems    int           `env:"BATCH_MAX_ITEMS,default=1000"`
+	MigrateOnStart   bool          `env:"MIGRATE_ON_START,default=true"`
+	RequestTimeout   time.Duration `env:"REQUEST_TIMEOUT,default=10s"`
+	Routing          Routing
+	Payment          Payment
+	Reconciliation   Reconciliation
+	Admin            Admin
+	Admission        Admission
+	Auth             Auth
+	RateLimit        RateLimit
+	Webhook          Webhook
+	Events           Events
+	Schedule         Schedule
+	Currency         Currency
+	Partition        Partition
 }
 
 type Queue struct {
-	Buffer  int `env:"QUEUE_BUFFER"`
-	Workers int `env:"QUEUE_WORKERS"`
+	Buffer            int           `env:"QUEUE_BUFFER"`
+	RetryBuffer       int           `env:"QUEUE_RETRY_BUFFER,default=10000"`
+	ReplayBuffer      int           `env:"QUEUE_REPLAY_BUFFER,default=1000"`
+	NewWeight         int           `env:"QUEUE_NEW_WEIGHT,default=6"`
+	RetryWeight       int           `env:"QUEUE_RETRY_WEIGHT,default=3"`
+	ReplayWeight      int           `env:"QUEUE_REPLAY_WEIGHT,default=1"`
+	Workers           int           `env:"QUEUE_WORKERS"`
+	Autoscale         bool          `env:"QUEUE_AUTOSCALE,default=false"`
+	MinWorkers        int           `env:"QUEUE_MIN_WORKERS,default=1"`
+	MaxWorkers        int           `env:"QUEUE_MAX_WORKERS,default=64"`
+	TargetLatency     time.Duration `env:"QUEUE_TARGET_LATENCY,default=250ms"`
+	MaxErrorRate      float64       `env:"QUEUE_MAX_ERROR_RATE,default=0.2"`
+	AutoscaleInterval time.Duration `env:"QUEUE_AUTOSCALE_INTERVAL,default=1s"`
+}
+
+type Payment struct {
+	Deadline           time.Duration `env:"PAYMENT_DEADLINE,default=0s"`
+	HedgeAfter         time.Duration `env:"HEDGE_AFTER,default=0s"`
+	LookupAttempts     int           `env:"PAYMENT_LOOKUP_ATTEMPTS,default=3"`
+	LookupInterval     time.Duration `env:"PAYMENT_LOOKUP_INTERVAL,default=200ms"`
+	LookupTimeout      time.Duration `env:"PAYMENT_LOOKUP_TIMEOUT,default=2s"`
+	LookupGrace        time.Duration `env:"PAYMENT_LOOKUP_GRACE,default=400ms"`
+	UnresolvedAttempts int           `env:"PAYMENT_UNRESOLVED_ATTEMPTS,default=10"`
+	RefundAttempts     int           `env:"REFUND_MAX_ATTEMPTS,default=10"`
+	Timestamp          string        `env:"PAYMENT_TIMESTAMP,default=intake"`
+	TTL                time.Duration `env:"PAYMENT_TTL,default=0s"`
+	TrackingTTL        time.Duration `env:"PAYMENT_TRACKING_TTL,default=1m"`
+	TrackingStaleTTL   time.Duration `env:"PAYMENT_TRACKING_STALE_TTL,default=10m"`
+	ExportTimeout      time.Duration `env:"PAYMENT_EXPORT_TIMEOUT,default=30s"`
+}
+
+type Reconciliation struct {
+	Interval time.Duration   `env:"RECONCILIATION_INTERVAL,default=0s"`
+	Windows  []time.Duration `env:"RECONCILIATION_WINDOWS,default=1m|5m|15m,separator=|"`
+	Lag      time.Duration   `env:"RECONCILIATION_LAG,default=10s"`
+	Sample   int             `env:"RECONCILIATION_SAMPLE,default=20"`
+	Lease    time.Duration   `env:"RECONCILIATION_LEASE,default=5m"`
+}
+
+type Routing struct {
+	Strategy       string        `env:"ROUTING_STRATEGY,default=priority"`
+	LatencyPenalty float64       `env:"ROUTING_LATENCY_PENALTY,default=0.5"`
+	FailurePenalty float64       `env:"ROUTING_FAILURE_PENALTY,default=1"`
+	WindowSize     int           `env:"ROUTING_WINDOW_SIZE,default=200"`
+	WindowMaxAge   time.Duration `env:"ROUTING_WINDOW_MAX_AGE,default=10s"`
+}
+
+type Admission struct {
+	MaxBacklog     int           `env:"ADMISSION_MAX_BACKLOG,default=0"`
+	MaxMemoryMB    int           `env:"ADMISSION_MAX_MEMORY_MB,default=0"`
+	MemoryInterval time.Duration `env:"ADMISSION_MEMORY_INTERVAL,default=100ms"`
+	RetryAfter     time.Duration `env:"ADMISSION_RETRY_AFTER,default=1s"`
+	SpillDir       string        `env:"ADMISSION_SPILL_DIR"`
+	SpillSegmentKB int           `env:"ADMISSION_SPILL_SEGMENT_KB,default=1024"`
+}
+
+type Admin struct {
+	Enabled bool   `env:"ADMIN_ENABLED,default=false"`
+	Token   string `env:"ADMIN_TOKEN"`
+}
+
+type Auth struct {
+	Enabled         bool          `env:"AUTH_ENABLED,default=false"`
+	RefreshInterval time.Duration `env:"AUTH_REFRESH_INTERVAL,default=30s"`
+}
+
+type RateLimit struct {
+	Instances   int           `env:"RATE_LIMIT_INSTANCES,default=1"`
+	GlobalRate  float64       `env:"RATE_LIMIT_GLOBAL_RATE,default=0"`
+	GlobalBurst int           `env:"RATE_LIMIT_GLOBAL_BURST,default=0"`
+	IPRate      float64       `env:"RATE_LIMIT_IP_RATE,default=0"`
+	IPBurst     int           `env:"RATE_LIMIT_IP_BURST,default=0"`
+	KeyRate     float64       `env:"RATE_LIMIT_KEY_RATE,default=0"`
+	KeyBurst    int           `env:"RATE_LIMIT_KEY_BURST,default=0"`
+	IdleTTL     time.Duration `env:"RATE_LIMIT_IDLE_TTL,default=5m"`
+	ForwardedIP bool          `env:"RATE_LIMIT_TRUST_FORWARDED,default=false"`
+}
+
+type Webhook struct {
+	Interval     time.Duration `env:"WEBHOOK_INTERVAL,default=0s"`
+	Secret       string        `env:"WEBHOOK_SECRET"`
+	Timeout      time.Duration `env:"WEBHOOK_TIMEOUT,default=5s"`
+	MaxAttempts  int           `env:"WEBHOOK_MAX_ATTEMPTS,default=10"`
+	Backoff      time.Duration `env:"WEBHOOK_BACKOFF,default=1s"`
+	MaxBackoff   time.Duration `env:"WEBHOOK_MAX_BACKOFF,default=10m"`
+	Batch        int           `env:"WEBHOOK_BATCH,default=100"`
+	AllowPrivate bool          `env:"WEBHOOK_ALLOW_PRIVATE,default=false"`
+}
+
+type Events struct {
+	Buffer           int           `env:"EVENTS_BUFFER,default=0"`
+	SubscriberBuffer int           `env:"EVENTS_SUBSCRIBER_BUFFER,default=256"`
+	Heartbeat        time.Duration `env:"EVENTS_HEARTBEAT,default=15s"`
+	SummaryInterval  time.Duration `env:"EVENTS_SUMMARY_INTERVAL,default=5s"`
+	FlushInterval    time.Duration `env:"EVENTS_FLUSH_INTERVAL,default=50ms"`
+	Retention        time.Duration `env:"EVENTS_RETENTION,default=1h"`
+}
+
+type Schedule struct {
+	Interval   time.Duration `env:"SCHEDULER_INTERVAL,default=1s"`
+	Batch      int           `env:"SCHEDULER_BATCH,default=500"`
+	Lease      time.Duration `env:"SCHEDULER_LEASE,default=5m"`
+	MaxHorizon time.Duration `env:"SCHEDULER_MAX_HORIZON,default=720h"`
+}
+
+type Currency struct {
+	Base            string        `env:"CURRENCY_BASE,default=BRL"`
+	RatesFile       string        `env:"CURRENCY_RATES_FILE"`
+	RefreshInterval time.Duration `env:"CURRENCY_REFRESH_INTERVAL,default=1m"`
+}
+
+type Partition struct {
+	Interval   time.Duration `env:"PARTITION_INTERVAL,default=10m"`
+	Period     string        `env:"PARTITION_PERIOD,default=day"`
+	Ahead      int           `env:"PARTITION_AHEAD,default=2"`
+	Retention  time.Duration `env:"PARTITION_RETENTION,default=0s"`
+	Drop       bool          `env:"PARTITION_DROP,default=true"`
+	ArchiveDir string        `env:"PARTITION_ARCHIVE_DIR"`
 }
 
 type Postgres struct {
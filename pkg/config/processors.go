@@ -0,0 +1,126 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Processor struct {
+	Name     string
+	Addr     string
+	Fee      float64
+	Priority int
+	Weight   int
+	Timeout  time.Duration
+	Token    string
+	Lookup   bool
+	Refund   bool
+	// Currency é a única moeda aceita pelo processador; vazia aceita qualquer
+	// uma e recebe o valor sem conversão.
+	Currency string
+}
+
+// ProcessorList devolve os processadores configurados em PROCESSORS, no
+// formato "nome=host:porta?fee=0.05&priority=0&weight=1&timeout=2s&token=123&lookup=true&refund=false&currency=USD"
+// separados por ";".
+// Sem PROCESSORS, usa o par default/fallback de DEFAULT_URL e FALLBACK_URL.
+func (e Environment) ProcessorList() ([]Processor, error) {
+	if len(e.Processors) == 0 {
+		return []Processor{
+			{Name: "default", Addr: e.DefaultUrl, Fee: e.DefaultFee, Priority: 0, Timeout: e.ProcessorTimeout, Token: e.ProcessorToken, Lookup: true},
+			{Name: "fallback", Addr: e.FallbackUrl, Fee: e.FallbackFee, Priority: 1, Timeout: e.ProcessorTimeout, Token: e.ProcessorToken, Lookup: true},
+		}, nil
+	}
+
+	processors := make([]Processor, 0, len(e.Processors))
+	seen := make(map[string]bool, len(e.Processors))
+
+	for i, spec := range e.Processors {
+		p, err := parseProcessor(strings.TrimSpace(spec), e.ProcessorTimeout, e.ProcessorToken)
+		if err != nil {
+			return nil, fmt.Errorf("PROCESSORS[%d] inválido: %w", i, err)
+		}
+		if seen[p.Name] {
+			return nil, fmt.Errorf("processador duplicado: %s", p.Name)
+		}
+		seen[p.Name] = true
+
+		if p.Priority < 0 {
+			p.Priority = i
+		}
+		processors = append(processors, p)
+	}
+
+	return processors, nil
+}
+
+func parseProcessor(spec string, timeout time.Duration, token string) (Processor, error) {
+	name, rest, ok := strings.Cut(spec, "=")
+	if !ok || name == "" {
+		return Processor{}, fmt.Errorf("esperado nome=host:porta em %q", spec)
+	}
+
+	addr, rawQuery, _ := strings.Cut(rest, "?")
+	if addr == "" {
+		return Processor{}, fmt.Errorf("endereço vazio para %s", name)
+	}
+
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return Processor{}, err
+	}
+
+	p := Processor{Name: name, Addr: addr, Priority: -1, Timeout: timeout, Token: token, Lookup: true}
+
+	if v := query.Get("token"); v != "" {
+		p.Token = v
+	}
+
+	if v := query.Get("lookup"); v != "" {
+		if p.Lookup, err = strconv.ParseBool(v); err != nil {
+			return Processor{}, fmt.Errorf("lookup inválido para %s: %w", name, err)
+		}
+	}
+
+	if v := query.Get("refund"); v != "" {
+		if p.Refund, err = strconv.ParseBool(v); err != nil {
+			return Processor{}, fmt.Errorf("refund inválido para %s: %w", name, err)
+		}
+	}
+
+	if v := query.Get("currency"); v != "" {
+		if len(v) != 3 || strings.ToUpper(v) != v {
+			return Processor{}, fmt.Errorf("currency inválida para %s: %q", name, v)
+		}
+		p.Currency = v
+	}
+
+	if v := query.Get("fee"); v != "" {
+		if p.Fee, err = strconv.ParseFloat(v, 64); err != nil {
+			return Processor{}, fmt.Errorf("fee inválida para %s: %w", name, err)
+		}
+	}
+
+	if v := query.Get("priority"); v != "" {
+		if p.Priority, err = strconv.Atoi(v); err != nil {
+			return Processor{}, fmt.Errorf("priority inválida para %s: %w", name, err)
+		}
+	}
+
+	if v := query.Get("weight"); v != "" {
+		if p.Weight, err = strconv.Atoi(v); err != nil {
+			return Processor{}, fmt.Errorf("weight inválido para %s: %w", name, err)
+		}
+	}
+
+	if v := query.Get("timeout"); v != "" {
+		if p.Timeout, err = time.ParseDuration(v); err != nil {
+			return Processor{}, fmt.Errorf("timeout inválido para %s: %w", name, err)
+		}
+	}
+
+	return p, nil
+}
@@ -0,0 +1,52 @@
+//go:generate easyjson -all batch.go
+package models
+
+import "time"
+
+const (
+	BatchItemAccepted = "accepted"
+	BatchItemInvalid  = "invalid"
+	BatchItemValid    = "valid"
+)
+
+// BatchItemResult é o resultado de um item do POST /payments/batch. Como o
+// lote é aceito ou recusado inteiro, itens válidos de um lote recusado saem
+// como "valid".
+type BatchItemResult struct {
+	Index         int    `json:"index"`
+	CorrelationId string `json:"correlationId,omitempty"`
+	Status        string `json:"status"`
+	Error         string `json:"error,omitempty"`
+}
+
+type BatchResponse struct {
+	BatchId  string            `json:"batchId,omitempty"`
+	Accepted int               `json:"accepted"`
+	Rejected int               `json:"rejected"`
+	Items    []BatchItemResult `json:"items"`
+}
+
+type BatchItemStatus struct {
+	CorrelationId string     `json:"correlationId"`
+	Status        string     `json:"status"`
+	Processor     string     `json:"processor,omitempty"`
+	Reason        string     `json:"reason,omitempty"`
+	ProcessedAt   *time.Time `json:"processedAt,omitempty"`
+}
+
+type BatchStatus struct {
+	BatchId   string            `json:"batchId"`
+	TenantId  string            `json:"tenantId,omitempty"`
+	CreatedAt time.Time         `json:"createdAt"`
+	Total     int               `json:"total"`
+	Counts    map[string]int    `json:"counts"`
+	Items     []BatchItemStatus `json:"items"`
+}
+
+//easyjson:skip
+type BatchDb struct {
+	BatchId        string
+	TenantId       string
+	CorrelationIds []string
+	CreatedAt      time.Time
+}
@@ -0,0 +1,501 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package models
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+	time "time"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjson917759c2DecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(in *jlexer.Lexer, out *BatchStatus) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "batchId":
+			out.BatchId = string(in.String())
+		case "tenantId":
+			out.TenantId = string(in.String())
+		case "createdAt":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.CreatedAt).UnmarshalJSON(data))
+			}
+		case "total":
+			out.Total = int(in.Int())
+		case "counts":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				out.Counts = make(map[string]int)
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v1 int
+					v1 = int(in.Int())
+					(out.Counts)[key] = v1
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "items":
+			if in.IsNull() {
+				in.Skip()
+				out.Items = nil
+			} else {
+				in.Delim('[')
+				if out.Items == nil {
+					if !in.IsDelim(']') {
+						out.Items = make([]BatchItemStatus, 0, 0)
+					} else {
+						out.Items = []BatchItemStatus{}
+					}
+				} else {
+					out.Items = (out.Items)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v2 BatchItemStatus
+					(v2).UnmarshalEasyJSON(in)
+					out.Items = append(out.Items, v2)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson917759c2EncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(out *jwriter.Writer, in BatchStatus) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"batchId\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.BatchId))
+	}
+	if in.TenantId != "" {
+		const prefix string = ",\"tenantId\":"
+		out.RawString(prefix)
+		out.String(string(in.TenantId))
+	}
+	{
+		const prefix string = ",\"createdAt\":"
+		out.RawString(prefix)
+		out.Raw((in.CreatedAt).MarshalJSON())
+	}
+	{
+		const prefix string = ",\"total\":"
+		out.RawString(prefix)
+		out.Int(int(in.Total))
+	}
+	{
+		const prefix string = ",\"counts\":"
+		out.RawString(prefix)
+		if in.Counts == nil && (out.Flags&jwriter.NilMapAsEmpty) == 0 {
+			out.RawString(`null`)
+		} else {
+			out.RawByte('{')
+			v3First := true
+			for v3Name, v3Value := range in.Counts {
+				if v3First {
+					v3First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v3Name))
+				out.RawByte(':')
+				out.Int(int(v3Value))
+			}
+			out.RawByte('}')
+		}
+	}
+	{
+		const prefix string = ",\"items\":"
+		out.RawString(prefix)
+		if in.Items == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v4, v5 := range in.Items {
+				if v4 > 0 {
+					out.RawByte(',')
+				}
+				(v5).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v BatchStatus) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson917759c2EncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v BatchStatus) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson917759c2EncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *BatchStatus) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson917759c2DecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *BatchStatus) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson917759c2DecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(l, v)
+}
+func easyjson917759c2DecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels1(in *jlexer.Lexer, out *BatchResponse) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "batchId":
+			out.BatchId = string(in.String())
+		case "accepted":
+			out.Accepted = int(in.Int())
+		case "rejected":
+			out.Rejected = int(in.Int())
+		case "items":
+			if in.IsNull() {
+				in.Skip()
+				out.Items = nil
+			} else {
+				in.Delim('[')
+				if out.Items == nil {
+					if !in.IsDelim(']') {
+						out.Items = make([]BatchItemResult, 0, 1)
+					} else {
+						out.Items = []BatchItemResult{}
+					}
+				} else {
+					out.Items = (out.Items)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v6 BatchItemResult
+					(v6).UnmarshalEasyJSON(in)
+					out.Items = append(out.Items, v6)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson917759c2EncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels1(out *jwriter.Writer, in BatchResponse) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	if in.BatchId != "" {
+		const prefix string = ",\"batchId\":"
+		first = false
+		out.RawString(prefix[1:])
+		out.String(string(in.BatchId))
+	}
+	{
+		const prefix string = ",\"accepted\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int(int(in.Accepted))
+	}
+	{
+		const prefix string = ",\"rejected\":"
+		out.RawString(prefix)
+		out.Int(int(in.Rejected))
+	}
+	{
+		const prefix string = ",\"items\":"
+		out.RawString(prefix)
+		if in.Items == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v7, v8 := range in.Items {
+				if v7 > 0 {
+					out.RawByte(',')
+				}
+				(v8).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v BatchResponse) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson917759c2EncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels1(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v BatchResponse) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson917759c2EncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels1(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *BatchResponse) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson917759c2DecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels1(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *BatchResponse) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson917759c2DecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels1(l, v)
+}
+func easyjson917759c2DecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels2(in *jlexer.Lexer, out *BatchItemStatus) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "correlationId":
+			out.CorrelationId = string(in.String())
+		case "status":
+			out.Status = string(in.String())
+		case "processor":
+			out.Processor = string(in.String())
+		case "reason":
+			out.Reason = string(in.String())
+		case "processedAt":
+			if in.IsNull() {
+				in.Skip()
+				out.ProcessedAt = nil
+			} else {
+				if out.ProcessedAt == nil {
+					out.ProcessedAt = new(time.Time)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.ProcessedAt).UnmarshalJSON(data))
+				}
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson917759c2EncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels2(out *jwriter.Writer, in BatchItemStatus) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"correlationId\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.CorrelationId))
+	}
+	{
+		const prefix string = ",\"status\":"
+		out.RawString(prefix)
+		out.String(string(in.Status))
+	}
+	if in.Processor != "" {
+		const prefix string = ",\"processor\":"
+		out.RawString(prefix)
+		out.String(string(in.Processor))
+	}
+	if in.Reason != "" {
+		const prefix string = ",\"reason\":"
+		out.RawString(prefix)
+		out.String(string(in.Reason))
+	}
+	if in.ProcessedAt != nil {
+		const prefix string = ",\"processedAt\":"
+		out.RawString(prefix)
+		out.Raw((*in.ProcessedAt).MarshalJSON())
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v BatchItemStatus) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson917759c2EncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels2(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v BatchItemStatus) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson917759c2EncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels2(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *BatchItemStatus) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson917759c2DecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels2(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *BatchItemStatus) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson917759c2DecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels2(l, v)
+}
+func easyjson917759c2DecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels3(in *jlexer.Lexer, out *BatchItemResult) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "index":
+			out.Index = int(in.Int())
+		case "correlationId":
+			out.CorrelationId = string(in.String())
+		case "status":
+			out.Status = string(in.String())
+		case "error":
+			out.Error = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson917759c2EncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels3(out *jwriter.Writer, in BatchItemResult) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"index\":"
+		out.RawString(prefix[1:])
+		out.Int(int(in.Index))
+	}
+	if in.CorrelationId != "" {
+		const prefix string = ",\"correlationId\":"
+		out.RawString(prefix)
+		out.String(string(in.CorrelationId))
+	}
+	{
+		const prefix string = ",\"status\":"
+		out.RawString(prefix)
+		out.String(string(in.Status))
+	}
+	if in.Error != "" {
+		const prefix string = ",\"error\":"
+		out.RawString(prefix)
+		out.String(string(in.Error))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v BatchItemResult) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson917759c2EncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels3(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v BatchItemResult) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson917759c2EncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels3(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *BatchItemResult) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson917759c2DecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels3(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *BatchItemResult) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson917759c2DecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels3(l, v)
+}
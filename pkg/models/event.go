@@ -0,0 +1,26 @@
+//go:generate easyjson -all event.go
+package models
+
+import "time"
+
+// EventRecord é uma linha de payment_events; Data é o JSON do campo data.
+type EventRecord struct {
+	Id            uint64 `json:"id"`
+	Type          string `json:"type"`
+	Processor     string `json:"processor"`
+	CorrelationId string `json:"correlationId"`
+	TenantId      string `json:"tenantId"`
+	Data          string `json:"data"`
+}
+
+// PaymentEvent é o campo data dos eventos de pagamento do GET /events.
+type PaymentEvent struct {
+	CorrelationId string    `json:"correlationId"`
+	Amount        float64   `json:"amount"`
+	Currency      string    `json:"currency,omitempty"`
+	Processor     string    `json:"processor,omitempty"`
+	Reason        string    `json:"reason,omitempty"`
+	Attempts      int       `json:"attempts,omitempty"`
+	TenantId      string    `json:"tenantId,omitempty"`
+	At            time.Time `json:"at"`
+}
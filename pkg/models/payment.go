@@ -17,11 +17,109 @@ type PaymentBasic struct {
 type PaymentDb struct {
 	CorrelationId string
 	Amount        float64
-	Fallback      bool
+	Processor     string
+	Strategy      string
+	ExpectedCost  float64
+	TenantId      string
 	CreatedAt     time.Time
+	// Currency é a moeda do pagamento; Rate converte Amount para
+	// ProcessorCurrency, a moeda enviada ao processador (vazia quando o
+	// pagamento foi enviado sem moeda), e BaseRate para a moeda base.
+	Currency          string
+	ProcessorCurrency string
+	Rate              float64
+	BaseRate          float64
 }
 
+type DeadLetter struct {
+	CorrelationId string
+	Amount        float64
+	Processor     string
+	Reason        string
+	Payload       string
+	TenantId      string
+	CreatedAt     time.Time
+}
+
+type ProcessorPayment struct {
+	CorrelationId string
+	Amount        float64
+	RequestedAt   time.Time
+}
+
+// PaymentSummary totaliza os valores na moeda base. Currencies só aparece
+// quando há pagamentos em outra moeda e Converted quando o resumo é pedido
+// com ?currency=.
 type PaymentSummary struct {
+	TotalRequests  int                        `json:"totalRequests"`
+	TotalAmount    float64                    `json:"totalAmount"`
+	RefundedAmount float64                    `json:"refundedAmount"`
+	NetAmount      float64                    `json:"netAmount"`
+	Currencies     map[string]CurrencySummary `json:"currencies,omitempty"`
+	Converted      *ConvertedSummary          `json:"converted,omitempty"`
+	// ProcessorAmount soma os valores enviados, na moeda do processador; é o
+	// que a reconciliação compara com o resumo do processador.
+	ProcessorAmount float64 `json:"-"`
+}
+
+// CurrencySummary totaliza os pagamentos de uma moeda, na própria moeda.
+type CurrencySummary struct {
 	TotalRequests int     `json:"totalRequests"`
 	TotalAmount   float64 `json:"totalAmount"`
 }
+
+type ConvertedSummary struct {
+	Currency       string  `json:"currency"`
+	TotalAmount    float64 `json:"totalAmount"`
+	RefundedAmount float64 `json:"refundedAmount"`
+	NetAmount      float64 `json:"netAmount"`
+}
+
+const (
+	RefundPending  = "pending"
+	RefundRefunded = "refunded"
+	RefundFailed   = "failed"
+)
+
+// RefundDb guarda o valor na moeda do pagamento; Rate e ProcessorCurrency vêm
+// do pagamento e dizem quanto pedir ao processador.
+type RefundDb struct {
+	RefundId          string
+	CorrelationId     string
+	Amount            float64
+	Processor         string
+	Status            string
+	Reason            string
+	TenantId          string
+	CreatedAt         time.Time
+	Rate              float64
+	ProcessorCurrency string
+}
+
+// ScheduledPaymentDb é um pagamento agendado; Payload é o envelope codificado
+// com AppendBinary. Status usa PaymentScheduled, PaymentCancelled e
+// ScheduleReleased (já enviado para a fila) e ScheduleCompleted (com resultado
+// em entry_history ou dead_letter).
+type ScheduledPaymentDb struct {
+	CorrelationId string
+	Amount        float64
+	TenantId      string
+	ScheduledAt   time.Time
+	Payload       []byte
+	Status        string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+const (
+	ScheduleReleased  = "released"
+	ScheduleCompleted = "completed"
+)
+
+// EntryPartition é uma partição de entry_history com o intervalo [From, To)
+// de created_at; From zero é a partição que começa em MINVALUE.
+type EntryPartition struct {
+	Name string
+	From time.Time
+	To   time.Time
+}
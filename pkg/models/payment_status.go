@@ -0,0 +1,74 @@
+//go:generate easyjson -all payment_status.go
+package models
+
+import "time"
+
+const (
+	PaymentQueued       = "queued"
+	PaymentProcessing   = "processing"
+	PaymentProcessed    = "processed"
+	PaymentFailed       = "failed"
+	PaymentDeadLettered = "dead-lettered"
+	PaymentScheduled    = "scheduled"
+	PaymentCancelled    = "cancelled"
+)
+
+type PaymentAttempt struct {
+	Processor  string    `json:"processor"`
+	StartedAt  time.Time `json:"startedAt"`
+	DurationMs int64     `json:"durationMs"`
+	StatusCode int       `json:"statusCode,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+type PaymentStatus struct {
+	CorrelationId string           `json:"correlationId"`
+	Status        string           `json:"status"`
+	Amount        float64          `json:"amount"`
+	Processor     string           `json:"processor,omitempty"`
+	Strategy      string           `json:"routingStrategy,omitempty"`
+	TenantId      string           `json:"tenantId,omitempty"`
+	RequestedAt   *time.Time       `json:"requestedAt,omitempty"`
+	ScheduledAt   *time.Time       `json:"scheduledAt,omitempty"`
+	ProcessedAt   *time.Time       `json:"processedAt,omitempty"`
+	UpdatedAt     time.Time        `json:"updatedAt"`
+	Reason        string           `json:"reason,omitempty"`
+	Attempts      []PaymentAttempt `json:"attempts"`
+}
+
+// PaymentRecord é uma linha da listagem/exportação de pagamentos. Cursor é a
+// posição da linha na paginação: passado em "after", a próxima página começa
+// logo depois dela.
+type PaymentRecord struct {
+	CorrelationId string    `json:"correlationId"`
+	Status        string    `json:"status"`
+	Amount        float64   `json:"amount"`
+	Processor     string    `json:"processor,omitempty"`
+	Strategy      string    `json:"routingStrategy,omitempty"`
+	TenantId      string    `json:"tenantId,omitempty"`
+	Reason        string    `json:"reason,omitempty"`
+	CreatedAt     time.Time `json:"createdAt"`
+	Cursor        string    `json:"cursor"`
+}
+
+//easyjson:skip
+type PaymentCursor struct {
+	CreatedAt     time.Time
+	CorrelationId string
+}
+
+// PaymentFilter filtra a listagem; campos nil ou vazios não filtram e Limit
+// zero não limita.
+//
+//easyjson:skip
+type PaymentFilter struct {
+	Status    string
+	From      *time.Time
+	To        *time.Time
+	Processor string
+	MinAmount *float64
+	MaxAmount *float64
+	TenantId  string
+	After     *PaymentCursor
+	Limit     int
+}
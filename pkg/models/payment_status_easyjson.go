@@ -0,0 +1,447 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package models
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+	time "time"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjson19fa9097DecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(in *jlexer.Lexer, out *PaymentStatus) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "correlationId":
+			out.CorrelationId = string(in.String())
+		case "status":
+			out.Status = string(in.String())
+		case "amount":
+			out.Amount = float64(in.Float64())
+		case "processor":
+			out.Processor = string(in.String())
+		case "routingStrategy":
+			out.Strategy = string(in.String())
+		case "tenantId":
+			out.TenantId = string(in.String())
+		case "requestedAt":
+			if in.IsNull() {
+				in.Skip()
+				out.RequestedAt = nil
+			} else {
+				if out.RequestedAt == nil {
+					out.RequestedAt = new(time.Time)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.RequestedAt).UnmarshalJSON(data))
+				}
+			}
+		case "scheduledAt":
+			if in.IsNull() {
+				in.Skip()
+				out.ScheduledAt = nil
+			} else {
+				if out.ScheduledAt == nil {
+					out.ScheduledAt = new(time.Time)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.ScheduledAt).UnmarshalJSON(data))
+				}
+			}
+		case "processedAt":
+			if in.IsNull() {
+				in.Skip()
+				out.ProcessedAt = nil
+			} else {
+				if out.ProcessedAt == nil {
+					out.ProcessedAt = new(time.Time)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.ProcessedAt).UnmarshalJSON(data))
+				}
+			}
+		case "updatedAt":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.UpdatedAt).UnmarshalJSON(data))
+			}
+		case "reason":
+			out.Reason = string(in.String())
+		case "attempts":
+			if in.IsNull() {
+				in.Skip()
+				out.Attempts = nil
+			} else {
+				in.Delim('[')
+				if out.Attempts == nil {
+					if !in.IsDelim(']') {
+						out.Attempts = make([]PaymentAttempt, 0, 0)
+					} else {
+						out.Attempts = []PaymentAttempt{}
+					}
+				} else {
+					out.Attempts = (out.Attempts)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v1 PaymentAttempt
+					(v1).UnmarshalEasyJSON(in)
+					out.Attempts = append(out.Attempts, v1)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson19fa9097EncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(out *jwriter.Writer, in PaymentStatus) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"correlationId\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.CorrelationId))
+	}
+	{
+		const prefix string = ",\"status\":"
+		out.RawString(prefix)
+		out.String(string(in.Status))
+	}
+	{
+		const prefix string = ",\"amount\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Amount))
+	}
+	if in.Processor != "" {
+		const prefix string = ",\"processor\":"
+		out.RawString(prefix)
+		out.String(string(in.Processor))
+	}
+	if in.Strategy != "" {
+		const prefix string = ",\"routingStrategy\":"
+		out.RawString(prefix)
+		out.String(string(in.Strategy))
+	}
+	if in.TenantId != "" {
+		const prefix string = ",\"tenantId\":"
+		out.RawString(prefix)
+		out.String(string(in.TenantId))
+	}
+	if in.RequestedAt != nil {
+		const prefix string = ",\"requestedAt\":"
+		out.RawString(prefix)
+		out.Raw((*in.RequestedAt).MarshalJSON())
+	}
+	if in.ScheduledAt != nil {
+		const prefix string = ",\"scheduledAt\":"
+		out.RawString(prefix)
+		out.Raw((*in.ScheduledAt).MarshalJSON())
+	}
+	if in.ProcessedAt != nil {
+		const prefix string = ",\"processedAt\":"
+		out.RawString(prefix)
+		out.Raw((*in.ProcessedAt).MarshalJSON())
+	}
+	{
+		const prefix string = ",\"updatedAt\":"
+		out.RawString(prefix)
+		out.Raw((in.UpdatedAt).MarshalJSON())
+	}
+	if in.Reason != "" {
+		const prefix string = ",\"reason\":"
+		out.RawString(prefix)
+		out.String(string(in.Reason))
+	}
+	{
+		const prefix string = ",\"attempts\":"
+		out.RawString(prefix)
+		if in.Attempts == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v2, v3 := range in.Attempts {
+				if v2 > 0 {
+					out.RawByte(',')
+				}
+				(v3).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v PaymentStatus) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson19fa9097EncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v PaymentStatus) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson19fa9097EncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *PaymentStatus) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson19fa9097DecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *PaymentStatus) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson19fa9097DecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(l, v)
+}
+func easyjson19fa9097DecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels1(in *jlexer.Lexer, out *PaymentRecord) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "correlationId":
+			out.CorrelationId = string(in.String())
+		case "status":
+			out.Status = string(in.String())
+		case "amount":
+			out.Amount = float64(in.Float64())
+		case "processor":
+			out.Processor = string(in.String())
+		case "routingStrategy":
+			out.Strategy = string(in.String())
+		case "tenantId":
+			out.TenantId = string(in.String())
+		case "reason":
+			out.Reason = string(in.String())
+		case "createdAt":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.CreatedAt).UnmarshalJSON(data))
+			}
+		case "cursor":
+			out.Cursor = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson19fa9097EncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels1(out *jwriter.Writer, in PaymentRecord) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"correlationId\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.CorrelationId))
+	}
+	{
+		const prefix string = ",\"status\":"
+		out.RawString(prefix)
+		out.String(string(in.Status))
+	}
+	{
+		const prefix string = ",\"amount\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Amount))
+	}
+	if in.Processor != "" {
+		const prefix string = ",\"processor\":"
+		out.RawString(prefix)
+		out.String(string(in.Processor))
+	}
+	if in.Strategy != "" {
+		const prefix string = ",\"routingStrategy\":"
+		out.RawString(prefix)
+		out.String(string(in.Strategy))
+	}
+	if in.TenantId != "" {
+		const prefix string = ",\"tenantId\":"
+		out.RawString(prefix)
+		out.String(string(in.TenantId))
+	}
+	if in.Reason != "" {
+		const prefix string = ",\"reason\":"
+		out.RawString(prefix)
+		out.String(string(in.Reason))
+	}
+	{
+		const prefix string = ",\"createdAt\":"
+		out.RawString(prefix)
+		out.Raw((in.CreatedAt).MarshalJSON())
+	}
+	{
+		const prefix string = ",\"cursor\":"
+		out.RawString(prefix)
+		out.String(string(in.Cursor))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v PaymentRecord) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson19fa9097EncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels1(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v PaymentRecord) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson19fa9097EncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels1(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *PaymentRecord) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson19fa9097DecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels1(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *PaymentRecord) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson19fa9097DecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels1(l, v)
+}
+func easyjson19fa9097DecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels2(in *jlexer.Lexer, out *PaymentAttempt) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "processor":
+			out.Processor = string(in.String())
+		case "startedAt":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.StartedAt).UnmarshalJSON(data))
+			}
+		case "durationMs":
+			out.DurationMs = int64(in.Int64())
+		case "statusCode":
+			out.StatusCode = int(in.Int())
+		case "error":
+			out.Error = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson19fa9097EncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels2(out *jwriter.Writer, in PaymentAttempt) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"processor\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Processor))
+	}
+	{
+		const prefix string = ",\"startedAt\":"
+		out.RawString(prefix)
+		out.Raw((in.StartedAt).MarshalJSON())
+	}
+	{
+		const prefix string = ",\"durationMs\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.DurationMs))
+	}
+	if in.StatusCode != 0 {
+		const prefix string = ",\"statusCode\":"
+		out.RawString(prefix)
+		out.Int(int(in.StatusCode))
+	}
+	if in.Error != "" {
+		const prefix string = ",\"error\":"
+		out.RawString(prefix)
+		out.String(string(in.Error))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v PaymentAttempt) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson19fa9097EncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels2(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v PaymentAttempt) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson19fa9097EncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels2(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *PaymentAttempt) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson19fa9097DecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels2(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *PaymentAttempt) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson19fa9097DecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels2(l, v)
+}
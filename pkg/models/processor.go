@@ -0,0 +1,19 @@
+//go:generate easyjson -all processor.go
+package models
+
+type ProcessorStats struct {
+	Name         string  `json:"name"`
+	Healthy      bool    `json:"healthy"`
+	Fee          float64 `json:"fee"`
+	Priority     int     `json:"priority"`
+	Weight       int     `json:"weight"`
+	AvgLatencyMs float64 `json:"avgLatencyMs"`
+	FailureRate  float64 `json:"failureRate"`
+	Samples      int     `json:"samples"`
+	ExpectedCost float64 `json:"expectedCostPerUnit"`
+}
+
+type RoutingStats struct {
+	Strategy   string           `json:"strategy"`
+	Processors []ProcessorStats `json:"processors"`
+}
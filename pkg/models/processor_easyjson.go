@@ -0,0 +1,246 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package models
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjson84fcdec2DecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(in *jlexer.Lexer, out *RoutingStats) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "strategy":
+			out.Strategy = string(in.String())
+		case "processors":
+			if in.IsNull() {
+				in.Skip()
+				out.Processors = nil
+			} else {
+				in.Delim('[')
+				if out.Processors == nil {
+					if !in.IsDelim(']') {
+						out.Processors = make([]ProcessorStats, 0, 0)
+					} else {
+						out.Processors = []ProcessorStats{}
+					}
+				} else {
+					out.Processors = (out.Processors)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v1 ProcessorStats
+					(v1).UnmarshalEasyJSON(in)
+					out.Processors = append(out.Processors, v1)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson84fcdec2EncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(out *jwriter.Writer, in RoutingStats) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"strategy\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Strategy))
+	}
+	{
+		const prefix string = ",\"processors\":"
+		out.RawString(prefix)
+		if in.Processors == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v2, v3 := range in.Processors {
+				if v2 > 0 {
+					out.RawByte(',')
+				}
+				(v3).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v RoutingStats) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson84fcdec2EncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v RoutingStats) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson84fcdec2EncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *RoutingStats) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson84fcdec2DecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *RoutingStats) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson84fcdec2DecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(l, v)
+}
+func easyjson84fcdec2DecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels1(in *jlexer.Lexer, out *ProcessorStats) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "name":
+			out.Name = string(in.String())
+		case "healthy":
+			out.Healthy = bool(in.Bool())
+		case "fee":
+			out.Fee = float64(in.Float64())
+		case "priority":
+			out.Priority = int(in.Int())
+		case "weight":
+			out.Weight = int(in.Int())
+		case "avgLatencyMs":
+			out.AvgLatencyMs = float64(in.Float64())
+		case "failureRate":
+			out.FailureRate = float64(in.Float64())
+		case "samples":
+			out.Samples = int(in.Int())
+		case "expectedCostPerUnit":
+			out.ExpectedCost = float64(in.Float64())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson84fcdec2EncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels1(out *jwriter.Writer, in ProcessorStats) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"name\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Name))
+	}
+	{
+		const prefix string = ",\"healthy\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.Healthy))
+	}
+	{
+		const prefix string = ",\"fee\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Fee))
+	}
+	{
+		const prefix string = ",\"priority\":"
+		out.RawString(prefix)
+		out.Int(int(in.Priority))
+	}
+	{
+		const prefix string = ",\"weight\":"
+		out.RawString(prefix)
+		out.Int(int(in.Weight))
+	}
+	{
+		const prefix string = ",\"avgLatencyMs\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.AvgLatencyMs))
+	}
+	{
+		const prefix string = ",\"failureRate\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.FailureRate))
+	}
+	{
+		const prefix string = ",\"samples\":"
+		out.RawString(prefix)
+		out.Int(int(in.Samples))
+	}
+	{
+		const prefix string = ",\"expectedCostPerUnit\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.ExpectedCost))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ProcessorStats) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson84fcdec2EncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels1(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ProcessorStats) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson84fcdec2EncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels1(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ProcessorStats) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson84fcdec2DecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels1(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ProcessorStats) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson84fcdec2DecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels1(l, v)
+}
@@ -0,0 +1,56 @@
+//go:generate easyjson -all queue.go
+package models
+
+import "time"
+
+type QueueStats struct {
+	Depth          int         `json:"depth"`
+	Capacity       int         `json:"capacity"`
+	Workers        int         `json:"workers"`
+	InProgress     int64       `json:"inProgress"`
+	Processed      int64       `json:"processed"`
+	Failed         int64       `json:"failed"`
+	OldestAgeMs    int64       `json:"oldestAgeMs"`
+	ThroughputPerS float64     `json:"throughputPerSecond"`
+	Paused         bool        `json:"paused"`
+	Lanes          []LaneStats `json:"lanes"`
+	SpillSize      int64       `json:"spillSize"`
+	Spilled        int64       `json:"spilled"`
+	Rejected       int64       `json:"rejected"`
+	MemoryBytes    uint64      `json:"memoryBytes"`
+}
+
+type LaneStats struct {
+	Name     string `json:"name"`
+	Depth    int    `json:"depth"`
+	Capacity int    `json:"capacity"`
+	Weight   int    `json:"weight"`
+}
+
+type DrainResponse struct {
+	Drained  int      `json:"drained"`
+	Messages []string `json:"messages"`
+}
+
+// ReplayResponse conta o que aconteceu com cada mensagem do replay: Invalid
+// não é um pagamento válido e Rejected não coube na fila.
+type ReplayResponse struct {
+	Replayed int        `json:"replayed"`
+	Invalid  int        `json:"invalid"`
+	Rejected int        `json:"rejected"`
+	Queue    QueueStats `json:"queue"`
+}
+
+type AutoscalerStats struct {
+	Workers         int       `json:"workers"`
+	Limit           float64   `json:"limit"`
+	Min             int       `json:"min"`
+	Max             int       `json:"max"`
+	TargetLatencyMs int64     `json:"targetLatencyMs"`
+	AvgLatencyMs    float64   `json:"avgLatencyMs"`
+	ErrorRate       float64   `json:"errorRate"`
+	LastDecision    string    `json:"lastDecision"`
+	DecidedAt       time.Time `json:"decidedAt"`
+	Increases       int64     `json:"increases"`
+	Decreases       int64     `json:"decreases"`
+}
@@ -0,0 +1,625 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package models
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjson5e1ce037DecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(in *jlexer.Lexer, out *ReplayResponse) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "replayed":
+			out.Replayed = int(in.Int())
+		case "invalid":
+			out.Invalid = int(in.Int())
+		case "rejected":
+			out.Rejected = int(in.Int())
+		case "queue":
+			(out.Queue).UnmarshalEasyJSON(in)
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson5e1ce037EncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(out *jwriter.Writer, in ReplayResponse) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"replayed\":"
+		out.RawString(prefix[1:])
+		out.Int(int(in.Replayed))
+	}
+	{
+		const prefix string = ",\"invalid\":"
+		out.RawString(prefix)
+		out.Int(int(in.Invalid))
+	}
+	{
+		const prefix string = ",\"rejected\":"
+		out.RawString(prefix)
+		out.Int(int(in.Rejected))
+	}
+	{
+		const prefix string = ",\"queue\":"
+		out.RawString(prefix)
+		(in.Queue).MarshalEasyJSON(out)
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ReplayResponse) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson5e1ce037EncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ReplayResponse) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson5e1ce037EncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ReplayResponse) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson5e1ce037DecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ReplayResponse) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson5e1ce037DecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(l, v)
+}
+func easyjson5e1ce037DecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels1(in *jlexer.Lexer, out *QueueStats) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "depth":
+			out.Depth = int(in.Int())
+		case "capacity":
+			out.Capacity = int(in.Int())
+		case "workers":
+			out.Workers = int(in.Int())
+		case "inProgress":
+			out.InProgress = int64(in.Int64())
+		case "processed":
+			out.Processed = int64(in.Int64())
+		case "failed":
+			out.Failed = int64(in.Int64())
+		case "oldestAgeMs":
+			out.OldestAgeMs = int64(in.Int64())
+		case "throughputPerSecond":
+			out.ThroughputPerS = float64(in.Float64())
+		case "paused":
+			out.Paused = bool(in.Bool())
+		case "lanes":
+			if in.IsNull() {
+				in.Skip()
+				out.Lanes = nil
+			} else {
+				in.Delim('[')
+				if out.Lanes == nil {
+					if !in.IsDelim(']') {
+						out.Lanes = make([]LaneStats, 0, 1)
+					} else {
+						out.Lanes = []LaneStats{}
+					}
+				} else {
+					out.Lanes = (out.Lanes)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v1 LaneStats
+					(v1).UnmarshalEasyJSON(in)
+					out.Lanes = append(out.Lanes, v1)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "spillSize":
+			out.SpillSize = int64(in.Int64())
+		case "spilled":
+			out.Spilled = int64(in.Int64())
+		case "rejected":
+			out.Rejected = int64(in.Int64())
+		case "memoryBytes":
+			out.MemoryBytes = uint64(in.Uint64())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson5e1ce037EncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels1(out *jwriter.Writer, in QueueStats) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"depth\":"
+		out.RawString(prefix[1:])
+		out.Int(int(in.Depth))
+	}
+	{
+		const prefix string = ",\"capacity\":"
+		out.RawString(prefix)
+		out.Int(int(in.Capacity))
+	}
+	{
+		const prefix string = ",\"workers\":"
+		out.RawString(prefix)
+		out.Int(int(in.Workers))
+	}
+	{
+		const prefix string = ",\"inProgress\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.InProgress))
+	}
+	{
+		const prefix string = ",\"processed\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Processed))
+	}
+	{
+		const prefix string = ",\"failed\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Failed))
+	}
+	{
+		const prefix string = ",\"oldestAgeMs\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.OldestAgeMs))
+	}
+	{
+		const prefix string = ",\"throughputPerSecond\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.ThroughputPerS))
+	}
+	{
+		const prefix string = ",\"paused\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.Paused))
+	}
+	{
+		const prefix string = ",\"lanes\":"
+		out.RawString(prefix)
+		if in.Lanes == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v2, v3 := range in.Lanes {
+				if v2 > 0 {
+					out.RawByte(',')
+				}
+				(v3).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"spillSize\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.SpillSize))
+	}
+	{
+		const prefix string = ",\"spilled\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Spilled))
+	}
+	{
+		const prefix string = ",\"rejected\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Rejected))
+	}
+	{
+		const prefix string = ",\"memoryBytes\":"
+		out.RawString(prefix)
+		out.Uint64(uint64(in.MemoryBytes))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v QueueStats) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson5e1ce037EncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels1(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v QueueStats) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson5e1ce037EncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels1(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *QueueStats) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson5e1ce037DecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels1(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *QueueStats) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson5e1ce037DecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels1(l, v)
+}
+func easyjson5e1ce037DecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels2(in *jlexer.Lexer, out *LaneStats) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "name":
+			out.Name = string(in.String())
+		case "depth":
+			out.Depth = int(in.Int())
+		case "capacity":
+			out.Capacity = int(in.Int())
+		case "weight":
+			out.Weight = int(in.Int())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson5e1ce037EncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels2(out *jwriter.Writer, in LaneStats) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"name\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Name))
+	}
+	{
+		const prefix string = ",\"depth\":"
+		out.RawString(prefix)
+		out.Int(int(in.Depth))
+	}
+	{
+		const prefix string = ",\"capacity\":"
+		out.RawString(prefix)
+		out.Int(int(in.Capacity))
+	}
+	{
+		const prefix string = ",\"weight\":"
+		out.RawString(prefix)
+		out.Int(int(in.Weight))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LaneStats) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson5e1ce037EncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels2(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LaneStats) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson5e1ce037EncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels2(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LaneStats) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson5e1ce037DecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels2(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LaneStats) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson5e1ce037DecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels2(l, v)
+}
+func easyjson5e1ce037DecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels3(in *jlexer.Lexer, out *DrainResponse) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "drained":
+			out.Drained = int(in.Int())
+		case "messages":
+			if in.IsNull() {
+				in.Skip()
+				out.Messages = nil
+			} else {
+				in.Delim('[')
+				if out.Messages == nil {
+					if !in.IsDelim(']') {
+						out.Messages = make([]string, 0, 4)
+					} else {
+						out.Messages = []string{}
+					}
+				} else {
+					out.Messages = (out.Messages)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v4 string
+					v4 = string(in.String())
+					out.Messages = append(out.Messages, v4)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson5e1ce037EncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels3(out *jwriter.Writer, in DrainResponse) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"drained\":"
+		out.RawString(prefix[1:])
+		out.Int(int(in.Drained))
+	}
+	{
+		const prefix string = ",\"messages\":"
+		out.RawString(prefix)
+		if in.Messages == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v5, v6 := range in.Messages {
+				if v5 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v6))
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v DrainResponse) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson5e1ce037EncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels3(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v DrainResponse) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson5e1ce037EncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels3(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *DrainResponse) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson5e1ce037DecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels3(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *DrainResponse) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson5e1ce037DecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels3(l, v)
+}
+func easyjson5e1ce037DecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels4(in *jlexer.Lexer, out *AutoscalerStats) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "workers":
+			out.Workers = int(in.Int())
+		case "limit":
+			out.Limit = float64(in.Float64())
+		case "min":
+			out.Min = int(in.Int())
+		case "max":
+			out.Max = int(in.Int())
+		case "targetLatencyMs":
+			out.TargetLatencyMs = int64(in.Int64())
+		case "avgLatencyMs":
+			out.AvgLatencyMs = float64(in.Float64())
+		case "errorRate":
+			out.ErrorRate = float64(in.Float64())
+		case "lastDecision":
+			out.LastDecision = string(in.String())
+		case "decidedAt":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.DecidedAt).UnmarshalJSON(data))
+			}
+		case "increases":
+			out.Increases = int64(in.Int64())
+		case "decreases":
+			out.Decreases = int64(in.Int64())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson5e1ce037EncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels4(out *jwriter.Writer, in AutoscalerStats) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"workers\":"
+		out.RawString(prefix[1:])
+		out.Int(int(in.Workers))
+	}
+	{
+		const prefix string = ",\"limit\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Limit))
+	}
+	{
+		const prefix string = ",\"min\":"
+		out.RawString(prefix)
+		out.Int(int(in.Min))
+	}
+	{
+		const prefix string = ",\"max\":"
+		out.RawString(prefix)
+		out.Int(int(in.Max))
+	}
+	{
+		const prefix string = ",\"targetLatencyMs\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.TargetLatencyMs))
+	}
+	{
+		const prefix string = ",\"avgLatencyMs\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.AvgLatencyMs))
+	}
+	{
+		const prefix string = ",\"errorRate\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.ErrorRate))
+	}
+	{
+		const prefix string = ",\"lastDecision\":"
+		out.RawString(prefix)
+		out.String(string(in.LastDecision))
+	}
+	{
+		const prefix string = ",\"decidedAt\":"
+		out.RawString(prefix)
+		out.Raw((in.DecidedAt).MarshalJSON())
+	}
+	{
+		const prefix string = ",\"increases\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Increases))
+	}
+	{
+		const prefix string = ",\"decreases\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Decreases))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v AutoscalerStats) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson5e1ce037EncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels4(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v AutoscalerStats) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson5e1ce037EncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels4(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *AutoscalerStats) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson5e1ce037DecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels4(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *AutoscalerStats) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson5e1ce037DecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels4(l, v)
+}
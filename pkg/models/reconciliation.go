@@ -0,0 +1,27 @@
+//go:generate easyjson -all reconciliation.go
+package models
+
+import "time"
+
+const (
+	ReconciliationSummary = "summary"
+	ReconciliationPayment = "payment"
+)
+
+type ReconciliationEntry struct {
+	Id             int64     `json:"id"`
+	CheckedAt      time.Time `json:"checkedAt"`
+	Processor      string    `json:"processor"`
+	Kind           string    `json:"kind"`
+	WindowFrom     time.Time `json:"windowFrom"`
+	WindowTo       time.Time `json:"windowTo"`
+	CorrelationId  *string   `json:"correlationId,omitempty"`
+	LocalRequests  int       `json:"localRequests"`
+	RemoteRequests int       `json:"remoteRequests"`
+	LocalAmount    float64   `json:"localAmount"`
+	RemoteAmount   float64   `json:"remoteAmount"`
+	Detail         string    `json:"detail"`
+}
+
+//easyjson:json
+type ReconciliationReport []ReconciliationEntry
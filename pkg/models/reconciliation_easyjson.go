@@ -0,0 +1,242 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package models
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjsonEae9a35fDecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(in *jlexer.Lexer, out *ReconciliationReport) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(ReconciliationReport, 0, 0)
+			} else {
+				*out = ReconciliationReport{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v1 ReconciliationEntry
+			(v1).UnmarshalEasyJSON(in)
+			*out = append(*out, v1)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonEae9a35fEncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(out *jwriter.Writer, in ReconciliationReport) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v2, v3 := range in {
+			if v2 > 0 {
+				out.RawByte(',')
+			}
+			(v3).MarshalEasyJSON(out)
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ReconciliationReport) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonEae9a35fEncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ReconciliationReport) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonEae9a35fEncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ReconciliationReport) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonEae9a35fDecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ReconciliationReport) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonEae9a35fDecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(l, v)
+}
+func easyjsonEae9a35fDecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels1(in *jlexer.Lexer, out *ReconciliationEntry) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "id":
+			out.Id = int64(in.Int64())
+		case "checkedAt":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.CheckedAt).UnmarshalJSON(data))
+			}
+		case "processor":
+			out.Processor = string(in.String())
+		case "kind":
+			out.Kind = string(in.String())
+		case "windowFrom":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.WindowFrom).UnmarshalJSON(data))
+			}
+		case "windowTo":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.WindowTo).UnmarshalJSON(data))
+			}
+		case "correlationId":
+			if in.IsNull() {
+				in.Skip()
+				out.CorrelationId = nil
+			} else {
+				if out.CorrelationId == nil {
+					out.CorrelationId = new(string)
+				}
+				*out.CorrelationId = string(in.String())
+			}
+		case "localRequests":
+			out.LocalRequests = int(in.Int())
+		case "remoteRequests":
+			out.RemoteRequests = int(in.Int())
+		case "localAmount":
+			out.LocalAmount = float64(in.Float64())
+		case "remoteAmount":
+			out.RemoteAmount = float64(in.Float64())
+		case "detail":
+			out.Detail = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonEae9a35fEncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels1(out *jwriter.Writer, in ReconciliationEntry) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"id\":"
+		out.RawString(prefix[1:])
+		out.Int64(int64(in.Id))
+	}
+	{
+		const prefix string = ",\"checkedAt\":"
+		out.RawString(prefix)
+		out.Raw((in.CheckedAt).MarshalJSON())
+	}
+	{
+		const prefix string = ",\"processor\":"
+		out.RawString(prefix)
+		out.String(string(in.Processor))
+	}
+	{
+		const prefix string = ",\"kind\":"
+		out.RawString(prefix)
+		out.String(string(in.Kind))
+	}
+	{
+		const prefix string = ",\"windowFrom\":"
+		out.RawString(prefix)
+		out.Raw((in.WindowFrom).MarshalJSON())
+	}
+	{
+		const prefix string = ",\"windowTo\":"
+		out.RawString(prefix)
+		out.Raw((in.WindowTo).MarshalJSON())
+	}
+	if in.CorrelationId != nil {
+		const prefix string = ",\"correlationId\":"
+		out.RawString(prefix)
+		out.String(string(*in.CorrelationId))
+	}
+	{
+		const prefix string = ",\"localRequests\":"
+		out.RawString(prefix)
+		out.Int(int(in.LocalRequests))
+	}
+	{
+		const prefix string = ",\"remoteRequests\":"
+		out.RawString(prefix)
+		out.Int(int(in.RemoteRequests))
+	}
+	{
+		const prefix string = ",\"localAmount\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.LocalAmount))
+	}
+	{
+		const prefix string = ",\"remoteAmount\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.RemoteAmount))
+	}
+	{
+		const prefix string = ",\"detail\":"
+		out.RawString(prefix)
+		out.String(string(in.Detail))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ReconciliationEntry) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonEae9a35fEncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels1(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ReconciliationEntry) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonEae9a35fEncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels1(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ReconciliationEntry) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonEae9a35fDecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels1(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ReconciliationEntry) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonEae9a35fDecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels1(l, v)
+}
@@ -9,5 +9,14 @@ import (
 type PaymentRequest struct {
 	CorrelationId string    `json:"correlationId"`
 	Amount        float64   `json:"amount"`
+	Currency      string    `json:"currency,omitempty"`
+	RequestedAt   time.Time `json:"requestedAt"`
+}
+
+type RefundRequest struct {
+	RefundId      string    `json:"refundId"`
+	CorrelationId string    `json:"correlationId"`
+	Amount        float64   `json:"amount"`
+	Currency      string    `json:"currency,omitempty"`
 	RequestedAt   time.Time `json:"requestedAt"`
 }
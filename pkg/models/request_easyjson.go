@@ -17,7 +17,7 @@ var (
 	_ easyjson.Marshaler
 )
 
-func easyjson3c9d2b01DecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(in *jlexer.Lexer, out *PaymentRequest) {
+func easyjson3c9d2b01DecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(in *jlexer.Lexer, out *RefundRequest) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -36,10 +36,14 @@ func easyjson3c9d2b01DecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(
 			continue
 		}
 		switch key {
+		case "refundId":
+			out.RefundId = string(in.String())
 		case "correlationId":
 			out.CorrelationId = string(in.String())
 		case "amount":
 			out.Amount = float64(in.Float64())
+		case "currency":
+			out.Currency = string(in.String())
 		case "requestedAt":
 			if data := in.Raw(); in.Ok() {
 				in.AddError((out.RequestedAt).UnmarshalJSON(data))
@@ -54,7 +58,101 @@ func easyjson3c9d2b01DecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(
 		in.Consumed()
 	}
 }
-func easyjson3c9d2b01EncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(out *jwriter.Writer, in PaymentRequest) {
+func easyjson3c9d2b01EncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(out *jwriter.Writer, in RefundRequest) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"refundId\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.RefundId))
+	}
+	{
+		const prefix string = ",\"correlationId\":"
+		out.RawString(prefix)
+		out.String(string(in.CorrelationId))
+	}
+	{
+		const prefix string = ",\"amount\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Amount))
+	}
+	if in.Currency != "" {
+		const prefix string = ",\"currency\":"
+		out.RawString(prefix)
+		out.String(string(in.Currency))
+	}
+	{
+		const prefix string = ",\"requestedAt\":"
+		out.RawString(prefix)
+		out.Raw((in.RequestedAt).MarshalJSON())
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v RefundRequest) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson3c9d2b01EncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v RefundRequest) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson3c9d2b01EncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *RefundRequest) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson3c9d2b01DecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *RefundRequest) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson3c9d2b01DecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(l, v)
+}
+func easyjson3c9d2b01DecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels1(in *jlexer.Lexer, out *PaymentRequest) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "correlationId":
+			out.CorrelationId = string(in.String())
+		case "amount":
+			out.Amount = float64(in.Float64())
+		case "currency":
+			out.Currency = string(in.String())
+		case "requestedAt":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.RequestedAt).UnmarshalJSON(data))
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson3c9d2b01EncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels1(out *jwriter.Writer, in PaymentRequest) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -68,6 +166,11 @@ func easyjson3c9d2b01EncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(
 		out.RawString(prefix)
 		out.Float64(float64(in.Amount))
 	}
+	if in.Currency != "" {
+		const prefix string = ",\"currency\":"
+		out.RawString(prefix)
+		out.String(string(in.Currency))
+	}
 	{
 		const prefix string = ",\"requestedAt\":"
 		out.RawString(prefix)
@@ -79,23 +182,23 @@ func easyjson3c9d2b01EncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(
 // MarshalJSON supports json.Marshaler interface
 func (v PaymentRequest) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjson3c9d2b01EncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(&w, v)
+	easyjson3c9d2b01EncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels1(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v PaymentRequest) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjson3c9d2b01EncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(w, v)
+	easyjson3c9d2b01EncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels1(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *PaymentRequest) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjson3c9d2b01DecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(&r, v)
+	easyjson3c9d2b01DecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels1(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *PaymentRequest) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjson3c9d2b01DecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(l, v)
+	easyjson3c9d2b01DecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels1(l, v)
 }
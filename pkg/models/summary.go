@@ -1,7 +1,5 @@
 //go:generate easyjson -all summary.go
 package models
 
-type SummaryResponse struct {
-	Default  PaymentSummary `json:"default"`
-	Fallback PaymentSummary `json:"fallback"`
-}
+//easyjson:json
+type SummaryResponse map[string]PaymentSummary
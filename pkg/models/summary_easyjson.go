@@ -18,6 +18,70 @@ var (
 )
 
 func easyjsonF381ebcaDecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(in *jlexer.Lexer, out *SummaryResponse) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+	} else {
+		in.Delim('{')
+		*out = make(SummaryResponse)
+		for !in.IsDelim('}') {
+			key := string(in.String())
+			in.WantColon()
+			var v1 PaymentSummary
+			easyjsonF381ebcaDecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels1(in, &v1)
+			(*out)[key] = v1
+			in.WantComma()
+		}
+		in.Delim('}')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonF381ebcaEncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(out *jwriter.Writer, in SummaryResponse) {
+	if in == nil && (out.Flags&jwriter.NilMapAsEmpty) == 0 {
+		out.RawString(`null`)
+	} else {
+		out.RawByte('{')
+		v2First := true
+		for v2Name, v2Value := range in {
+			if v2First {
+				v2First = false
+			} else {
+				out.RawByte(',')
+			}
+			out.String(string(v2Name))
+			out.RawByte(':')
+			easyjsonF381ebcaEncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels1(out, v2Value)
+		}
+		out.RawByte('}')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v SummaryResponse) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonF381ebcaEncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v SummaryResponse) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonF381ebcaEncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *SummaryResponse) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonF381ebcaDecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *SummaryResponse) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonF381ebcaDecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(l, v)
+}
+func easyjsonF381ebcaDecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels1(in *jlexer.Lexer, out *PaymentSummary) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -36,10 +100,44 @@ func easyjsonF381ebcaDecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(
 			continue
 		}
 		switch key {
-		case "default":
-			easyjsonF381ebcaDecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels1(in, &out.Default)
-		case "fallback":
-			easyjsonF381ebcaDecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels1(in, &out.Fallback)
+		case "totalRequests":
+			out.TotalRequests = int(in.Int())
+		case "totalAmount":
+			out.TotalAmount = float64(in.Float64())
+		case "refundedAmount":
+			out.RefundedAmount = float64(in.Float64())
+		case "netAmount":
+			out.NetAmount = float64(in.Float64())
+		case "currencies":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				if !in.IsDelim('}') {
+					out.Currencies = make(map[string]CurrencySummary)
+				} else {
+					out.Currencies = nil
+				}
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v3 CurrencySummary
+					easyjsonF381ebcaDecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels2(in, &v3)
+					(out.Currencies)[key] = v3
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "converted":
+			if in.IsNull() {
+				in.Skip()
+				out.Converted = nil
+			} else {
+				if out.Converted == nil {
+					out.Converted = new(ConvertedSummary)
+				}
+				easyjsonF381ebcaDecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels3(in, out.Converted)
+			}
 		default:
 			in.SkipRecursive()
 		}
@@ -50,47 +148,120 @@ func easyjsonF381ebcaDecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(
 		in.Consumed()
 	}
 }
-func easyjsonF381ebcaEncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(out *jwriter.Writer, in SummaryResponse) {
+func easyjsonF381ebcaEncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels1(out *jwriter.Writer, in PaymentSummary) {
 	out.RawByte('{')
 	first := true
 	_ = first
 	{
-		const prefix string = ",\"default\":"
+		const prefix string = ",\"totalRequests\":"
 		out.RawString(prefix[1:])
-		easyjsonF381ebcaEncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels1(out, in.Default)
+		out.Int(int(in.TotalRequests))
 	}
 	{
-		const prefix string = ",\"fallback\":"
+		const prefix string = ",\"totalAmount\":"
 		out.RawString(prefix)
-		easyjsonF381ebcaEncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels1(out, in.Fallback)
+		out.Float64(float64(in.TotalAmount))
+	}
+	{
+		const prefix string = ",\"refundedAmount\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.RefundedAmount))
+	}
+	{
+		const prefix string = ",\"netAmount\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.NetAmount))
+	}
+	if len(in.Currencies) != 0 {
+		const prefix string = ",\"currencies\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('{')
+			v4First := true
+			for v4Name, v4Value := range in.Currencies {
+				if v4First {
+					v4First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v4Name))
+				out.RawByte(':')
+				easyjsonF381ebcaEncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels2(out, v4Value)
+			}
+			out.RawByte('}')
+		}
+	}
+	if in.Converted != nil {
+		const prefix string = ",\"converted\":"
+		out.RawString(prefix)
+		easyjsonF381ebcaEncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels3(out, *in.Converted)
 	}
 	out.RawByte('}')
 }
-
-// MarshalJSON supports json.Marshaler interface
-func (v SummaryResponse) MarshalJSON() ([]byte, error) {
-	w := jwriter.Writer{}
-	easyjsonF381ebcaEncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(&w, v)
-	return w.Buffer.BuildBytes(), w.Error
-}
-
-// MarshalEasyJSON supports easyjson.Marshaler interface
-func (v SummaryResponse) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonF381ebcaEncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(w, v)
-}
-
-// UnmarshalJSON supports json.Unmarshaler interface
-func (v *SummaryResponse) UnmarshalJSON(data []byte) error {
-	r := jlexer.Lexer{Data: data}
-	easyjsonF381ebcaDecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(&r, v)
-	return r.Error()
+func easyjsonF381ebcaDecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels3(in *jlexer.Lexer, out *ConvertedSummary) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "currency":
+			out.Currency = string(in.String())
+		case "totalAmount":
+			out.TotalAmount = float64(in.Float64())
+		case "refundedAmount":
+			out.RefundedAmount = float64(in.Float64())
+		case "netAmount":
+			out.NetAmount = float64(in.Float64())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
 }
-
-// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *SummaryResponse) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonF381ebcaDecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels(l, v)
+func easyjsonF381ebcaEncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels3(out *jwriter.Writer, in ConvertedSummary) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"currency\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Currency))
+	}
+	{
+		const prefix string = ",\"totalAmount\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.TotalAmount))
+	}
+	{
+		const prefix string = ",\"refundedAmount\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.RefundedAmount))
+	}
+	{
+		const prefix string = ",\"netAmount\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.NetAmount))
+	}
+	out.RawByte('}')
 }
-func easyjsonF381ebcaDecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels1(in *jlexer.Lexer, out *PaymentSummary) {
+func easyjsonF381ebcaDecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels2(in *jlexer.Lexer, out *CurrencySummary) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -123,7 +294,7 @@ func easyjsonF381ebcaDecodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels1
 		in.Consumed()
 	}
 }
-func easyjsonF381ebcaEncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels1(out *jwriter.Writer, in PaymentSummary) {
+func easyjsonF381ebcaEncodeGithubComPatrignaniPatrignaniRinhaBackendGoPkgModels2(out *jwriter.Writer, in CurrencySummary) {
 	out.RawByte('{')
 	first := true
 	_ = first
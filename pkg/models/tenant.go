@@ -0,0 +1,9 @@
+package models
+
+type APIKey struct {
+	KeyHash       string
+	TenantId      string
+	RatePerSecond float64
+	Burst         int
+	DailyQuota    int64
+}
@@ -0,0 +1,51 @@
+//go:generate easyjson -all webhook.go
+package models
+
+import "time"
+
+const (
+	WebhookPaymentProcessed    = "payment.processed"
+	WebhookPaymentDeadLettered = "payment.dead-lettered"
+	WebhookRefundRefunded      = "refund.refunded"
+	WebhookRefundFailed        = "refund.failed"
+)
+
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// WebhookEvent é o corpo enviado para o callbackUrl do cliente.
+type WebhookEvent struct {
+	Id            string    `json:"id"`
+	Event         string    `json:"event"`
+	CorrelationId string    `json:"correlationId"`
+	RefundId      string    `json:"refundId,omitempty"`
+	Amount        float64   `json:"amount"`
+	Currency      string    `json:"currency,omitempty"`
+	Processor     string    `json:"processor,omitempty"`
+	Reason        string    `json:"reason,omitempty"`
+	TenantId      string    `json:"tenantId,omitempty"`
+	OccurredAt    time.Time `json:"occurredAt"`
+}
+
+type WebhookDelivery struct {
+	Id             int64      `json:"id"`
+	EventId        string     `json:"eventId"`
+	Event          string     `json:"event"`
+	CorrelationId  string     `json:"correlationId"`
+	Url            string     `json:"url"`
+	Payload        string     `json:"payload"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	LastStatusCode int        `json:"lastStatusCode,omitempty"`
+	LastError      string     `json:"lastError,omitempty"`
+	TenantId       string     `json:"tenantId,omitempty"`
+	NextAttemptAt  time.Time  `json:"nextAttemptAt"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	DeliveredAt    *time.Time `json:"deliveredAt,omitempty"`
+}
+
+//easyjson:json
+type WebhookDeliveries []WebhookDelivery
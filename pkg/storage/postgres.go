@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"fmt"
+	"io"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -13,6 +14,9 @@ type PostgresClient interface {
 	Exec(ctx context.Context, sql string, args ...interface{}) (int64, error)
 	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
 	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	Begin(ctx context.Context) (pgx.Tx, error)
+	CopyTo(ctx context.Context, w io.Writer, sql string) (int64, error)
+	Listen(ctx context.Context, channel string, notify func()) error
 }
 
 type PostgresClientImp struct {
@@ -46,3 +50,44 @@ func (p *PostgresClientImp) QueryRow(ctx context.Context, sql string, args ...in
 func (p *PostgresClientImp) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
 	return p.pool.Query(ctx, sql, args...)
 }
+
+func (p *PostgresClientImp) Begin(ctx context.Context) (pgx.Tx, error) {
+	return p.pool.Begin(ctx)
+}
+
+// CopyTo executa um COPY ... TO STDOUT e escreve o resultado em w.
+func (p *PostgresClientImp) CopyTo(ctx context.Context, w io.Writer, sql string) (int64, error) {
+	conn, err := p.pool.Acquire(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Release()
+
+	tag, err := conn.Conn().PgConn().CopyTo(ctx, w, sql)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// Listen faz LISTEN em channel numa conexão própria e chama notify a cada
+// NOTIFY, até ctx acabar ou a conexão cair. A conexão não volta ao pool.
+func (p *PostgresClientImp) Listen(ctx context.Context, channel string, notify func()) error {
+	pooled, err := p.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	conn := pooled.Hijack()
+	defer conn.Close(context.WithoutCancel(ctx))
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+		return err
+	}
+
+	for {
+		if _, err := conn.WaitForNotification(ctx); err != nil {
+			return err
+		}
+		notify()
+	}
+}
@@ -0,0 +1,229 @@
+package servers
+
+import (
+	"context"
+	"crypto/subtle"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/Patrignani/patrignani-rinha-backend-go/internal/workers"
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/buffers"
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/config"
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/models"
+	"github.com/panjf2000/gnet/v2"
+)
+
+// handleAdmin devolve true quando a resposta sai fora do loop (respondAsync).
+func (s *GNetServer) handleAdmin(c gnet.Conn, method, route string, partsPath [][]byte, headers map[string][]byte, body []byte) bool {
+	// Sem ADMIN_TOKEN as rotas ficam desligadas mesmo com ADMIN_ENABLED.
+	if !config.Env.Admin.Enabled || config.Env.Admin.Token == "" {
+		writeResponse(c, 404, []byte(`{"error":"not found"}`), s.keepAlive)
+		return false
+	}
+
+	if subtle.ConstantTimeCompare(headers["x-admin-token"], []byte(config.Env.Admin.Token)) != 1 {
+		writeResponse(c, 401, []byte(`{"error":"unauthorized"}`), s.keepAlive)
+		return false
+	}
+
+	var queryMap map[string]string
+	if len(partsPath) > 1 {
+		var ok bool
+		queryMap, ok = parseQueryString(partsPath[1])
+		if !ok {
+			writeResponse(c, 400, []byte(`{"error":"invalid query"}`), s.keepAlive)
+			return false
+		}
+	}
+
+	switch {
+	case method == "GET" && route == "/admin/queue":
+		s.writeQueueStats(c)
+
+	case method == "GET" && route == "/admin/processors":
+		stats := s.paymentService.RoutingStats()
+		jsonBytes, err := stats.MarshalJSON()
+		if err != nil {
+			writeResponse(c, 400, errorJSON(err.Error()), s.keepAlive)
+			return false
+		}
+		writeResponse(c, 200, jsonBytes, s.keepAlive)
+
+	case method == "GET" && route == "/admin/reconciliation":
+		limit := 100
+		if v, ok := queryMap["limit"]; ok {
+			var err error
+			if limit, err = strconv.Atoi(v); err != nil || limit <= 0 {
+				writeResponse(c, 400, []byte(`{"error":"invalid 'limit'"}`), s.keepAlive)
+				return false
+			}
+		}
+
+		var since *time.Time
+		if v := queryMap["since"]; v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				writeResponse(c, 400, []byte(`{"error":"invalid 'since' timestamp format"}`), s.keepAlive)
+				return false
+			}
+			since = &t
+		}
+
+		keepAlive := s.keepAlive
+		s.respondAsync(c, func(ctx context.Context) {
+			report, err := s.reconciliation.Report(ctx, since, limit)
+			if err != nil {
+				writeResponse(c, 400, errorJSON(err.Error()), keepAlive)
+				return
+			}
+
+			jsonBytes, err := report.MarshalJSON()
+			if err != nil {
+				writeResponse(c, 400, errorJSON(err.Error()), keepAlive)
+				return
+			}
+			writeResponse(c, 200, jsonBytes, keepAlive)
+		})
+		return true
+
+	case method == "GET" && route == "/admin/webhooks":
+		limit := 100
+		if v, ok := queryMap["limit"]; ok {
+			var err error
+			if limit, err = strconv.Atoi(v); err != nil || limit <= 0 {
+				writeResponse(c, 400, []byte(`{"error":"invalid 'limit'"}`), s.keepAlive)
+				return false
+			}
+		}
+
+		correlationId, status := queryMap["correlationId"], queryMap["status"]
+		keepAlive := s.keepAlive
+		s.respondAsync(c, func(ctx context.Context) {
+			deliveries, err := s.paymentService.WebhookDeliveries(ctx, correlationId, status, limit)
+			if err != nil {
+				writeResponse(c, 400, errorJSON(err.Error()), keepAlive)
+				return
+			}
+
+			jsonBytes, err := deliveries.MarshalJSON()
+			if err != nil {
+				writeResponse(c, 400, errorJSON(err.Error()), keepAlive)
+				return
+			}
+			writeResponse(c, 200, jsonBytes, keepAlive)
+		})
+		return true
+
+	case method == "GET" && route == "/admin/autoscaler":
+		if s.autoscaler == nil {
+			writeResponse(c, 404, []byte(`{"error":"autoscaler disabled"}`), s.keepAlive)
+			return false
+		}
+
+		stats := s.autoscaler.Stats()
+		jsonBytes, err := stats.MarshalJSON()
+		if err != nil {
+			writeResponse(c, 400, errorJSON(err.Error()), s.keepAlive)
+			return false
+		}
+		writeResponse(c, 200, jsonBytes, s.keepAlive)
+
+	case method == "POST" && route == "/admin/queue/pause":
+		s.queue.Pause()
+		s.writeQueueStats(c)
+
+	case method == "POST" && route == "/admin/queue/resume":
+		s.queue.Resume()
+		s.writeQueueStats(c)
+
+	case method == "POST" && route == "/admin/queue/workers":
+		count, err := strconv.Atoi(queryMap["count"])
+		if err != nil {
+			writeResponse(c, 400, []byte(`{"error":"invalid 'count'"}`), s.keepAlive)
+			return false
+		}
+
+		if err := s.queue.SetWorkers(count); err != nil {
+			writeResponse(c, 400, errorJSON(err.Error()), s.keepAlive)
+			return false
+		}
+		s.writeQueueStats(c)
+
+	case method == "POST" && route == "/admin/queue/drain":
+		count, err := strconv.Atoi(queryMap["count"])
+		if err != nil || count <= 0 {
+			writeResponse(c, 400, []byte(`{"error":"invalid 'count'"}`), s.keepAlive)
+			return false
+		}
+
+		keepAlive := s.keepAlive
+		s.respondAsync(c, func(ctx context.Context) {
+			messages, err := s.paymentService.DrainQueue(ctx, count)
+			if err != nil {
+				log.Printf("Erro ao drenar fila: %v", err)
+				if len(messages) == 0 {
+					writeResponse(c, 500, []byte(`{"error":"drain failed"}`), keepAlive)
+					return
+				}
+			}
+
+			resp := models.DrainResponse{Drained: len(messages), Messages: messages}
+			jsonBytes, err := resp.MarshalJSON()
+			if err != nil {
+				writeResponse(c, 400, errorJSON(err.Error()), keepAlive)
+				return
+			}
+			writeResponse(c, 200, jsonBytes, keepAlive)
+		})
+		return true
+
+	case method == "POST" && route == "/admin/queue/replay":
+		var req models.DrainResponse
+		if err := req.UnmarshalJSON(body); err != nil {
+			writeResponse(c, 400, []byte(`{"error":"invalid body"}`), s.keepAlive)
+			return false
+		}
+
+		resp := models.ReplayResponse{}
+		for _, raw := range req.Messages {
+			msg, err := workers.DecodeStoredJSON(buffers.Copy([]byte(raw)), time.Time{})
+			if err != nil {
+				resp.Invalid++
+				continue
+			}
+			if err := s.queue.SendLane(workers.LaneReplay, msg); err != nil {
+				resp.Rejected++
+				msg.Release()
+				continue
+			}
+			resp.Replayed++
+		}
+
+		if resp.Invalid > 0 || resp.Rejected > 0 {
+			log.Printf("Replay: %d mensagens reenfileiradas, %d inválidas e %d recusadas pela fila", resp.Replayed, resp.Invalid, resp.Rejected)
+		}
+
+		resp.Queue = s.queue.Stats()
+		jsonBytes, err := resp.MarshalJSON()
+		if err != nil {
+			writeResponse(c, 400, errorJSON(err.Error()), s.keepAlive)
+			return false
+		}
+		writeResponse(c, 200, jsonBytes, s.keepAlive)
+
+	default:
+		writeResponse(c, 404, []byte(`{"error":"not found"}`), s.keepAlive)
+	}
+	return false
+}
+
+func (s *GNetServer) writeQueueStats(c gnet.Conn) {
+	stats := s.queue.Stats()
+	jsonBytes, err := stats.MarshalJSON()
+	if err != nil {
+		writeResponse(c, 400, errorJSON(err.Error()), s.keepAlive)
+		return
+	}
+	writeResponse(c, 200, jsonBytes, s.keepAlive)
+}
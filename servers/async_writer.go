@@ -0,0 +1,55 @@
+package servers
+
+import (
+	"context"
+
+	"github.com/panjf2000/gnet/v2"
+)
+
+// asyncWriter escreve na conexão a partir de uma goroutine fora do loop do
+// gnet. AsyncWrite não copia os dados, então cada envio precisa ser um slice
+// novo; no máximo cap(pending) envios ficam pendentes no loop, o que segura
+// quem produz quando o cliente é lento. Uma falha cancela ctx e fica em err.
+type asyncWriter struct {
+	c       gnet.Conn
+	ctx     context.Context
+	cancel  context.CancelFunc
+	pending chan struct{}
+	err     error
+}
+
+func newAsyncWriter(c gnet.Conn, ctx context.Context, cancel context.CancelFunc, inFlight int) asyncWriter {
+	return asyncWriter{c: c, ctx: ctx, cancel: cancel, pending: make(chan struct{}, inFlight)}
+}
+
+func (w *asyncWriter) send(data []byte) {
+	if w.err != nil {
+		return
+	}
+
+	select {
+	case w.pending <- struct{}{}:
+	case <-w.ctx.Done():
+		w.err = w.ctx.Err()
+		return
+	}
+
+	err := w.c.AsyncWrite(data, func(c gnet.Conn, err error) error {
+		<-w.pending
+		if err != nil {
+			w.cancel()
+		}
+		return nil
+	})
+	if err != nil {
+		<-w.pending
+		w.err = err
+		w.cancel()
+	}
+}
+
+// close fecha a conexão depois das escritas pendentes, sem devolvê-la ao
+// OnTraffic.
+func (w *asyncWriter) close() {
+	_ = w.c.CloseWithCallback(nil)
+}
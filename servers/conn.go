@@ -0,0 +1,99 @@
+package servers
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/buffers"
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/config"
+	"github.com/panjf2000/gnet/v2"
+)
+
+// connState fica no contexto de cada conexão, criado em OnOpened. Só busy é
+// lido fora do loop do gnet; os demais campos só são tocados nele.
+type connState struct {
+	// busy fica ligado enquanto uma resposta é produzida fora do loop. Nesse
+	// tempo OnTraffic deixa as requisições seguintes no buffer, para que as
+	// respostas de um pipeline saiam na ordem dos pedidos.
+	busy atomic.Bool
+	// cancel interrompe a resposta em andamento quando a conexão fecha.
+	cancel context.CancelFunc
+	// events marca um stream SSE aberto: a conexão não recebe mais
+	// requisições e o que o cliente enviar é descartado.
+	events bool
+	// body guarda a cópia do corpo da requisição atual; é liberado quando a
+	// próxima começa ou a conexão fecha.
+	body *buffers.Buffer
+}
+
+func stateOf(c gnet.Conn) *connState {
+	if state, ok := c.Context().(*connState); ok {
+		return state
+	}
+	state := &connState{}
+	c.SetContext(state)
+	return state
+}
+
+// setBody troca o corpo guardado, devolvendo o anterior ao pool.
+func (state *connState) setBody(body *buffers.Buffer) {
+	if state.body != nil {
+		state.body.Release()
+	}
+	state.body = body
+}
+
+// respondAsync roda handle fora do loop do gnet, com um contexto cancelado
+// quando a conexão fecha ou REQUEST_TIMEOUT passa. handle responde com
+// writeResponse (ou AsyncWrite), que pode ser chamado de qualquer goroutine;
+// a conexão só volta a ser lida depois que a resposta foi escrita.
+func (s *GNetServer) respondAsync(c gnet.Conn, handle func(ctx context.Context)) {
+	keepAlive := s.keepAlive
+	state := stateOf(c)
+	ctx, cancel := context.WithCancel(context.Background())
+	if timeout := config.Env.RequestTimeout; timeout > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), timeout)
+	}
+	state.busy.Store(true)
+	state.cancel = cancel
+
+	go func() {
+		defer cancel()
+		handle(ctx)
+		release(c, state, keepAlive)
+	}()
+}
+
+// release devolve a conexão ao OnTraffic. A escrita vazia entra na fila do
+// loop depois da resposta, então o callback só roda quando ela já saiu, e o
+// Wake processa o que o cliente enviou nesse meio tempo.
+func release(c gnet.Conn, state *connState, keepAlive bool) {
+	if !keepAlive {
+		_ = c.CloseWithCallback(nil)
+		return
+	}
+
+	_ = c.AsyncWrite(nil, func(c gnet.Conn, err error) error {
+		state.busy.Store(false)
+		if err != nil {
+			return nil
+		}
+		return c.Wake(nil)
+	})
+}
+
+func (s *GNetServer) OnClose(c gnet.Conn, err error) gnet.Action {
+	state, ok := c.Context().(*connState)
+	if !ok {
+		return gnet.None
+	}
+	if state.cancel != nil {
+		state.cancel()
+	}
+	// Com uma resposta em andamento o corpo ainda pode estar em uso; fica
+	// para o coletor.
+	if !state.busy.Load() {
+		state.setBody(nil)
+	}
+	return gnet.None
+}
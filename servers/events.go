@@ -0,0 +1,125 @@
+package servers
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Patrignani/patrignani-rinha-backend-go/internal/events"
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/config"
+	"github.com/panjf2000/gnet/v2"
+)
+
+const sseInFlight = 16
+
+// handleEvents atende GET /events?types=&processor=&correlationId= com um
+// stream SSE. Last-Event-ID (header ou query lastEventId) retoma a partir do
+// log em memória ou, se ele não for longe o bastante, de payment_events. O
+// stream ocupa a conexão até o fim, que é fechada depois dele. Devolve true
+// quando o stream foi iniciado.
+func (s *GNetServer) handleEvents(c gnet.Conn, partsPath [][]byte, headers map[string][]byte) bool {
+	if s.events == nil {
+		writeResponse(c, 404, []byte(`{"error":"events disabled"}`), s.keepAlive)
+		return false
+	}
+
+	tenantId, ok := s.authenticate(c, headers)
+	if !ok {
+		return false
+	}
+
+	queryMap := map[string]string{}
+	if len(partsPath) > 1 && len(partsPath[1]) > 0 {
+		if queryMap, ok = parseQueryString(partsPath[1]); !ok {
+			writeResponse(c, 400, []byte(`{"error":"invalid query"}`), s.keepAlive)
+			return false
+		}
+	}
+	for key, value := range queryMap {
+		unescaped, err := url.QueryUnescape(value)
+		if err != nil {
+			writeResponse(c, 400, []byte(`{"error":"invalid query"}`), s.keepAlive)
+			return false
+		}
+		queryMap[key] = unescaped
+	}
+
+	filter := events.Filter{
+		Processor:     queryMap["processor"],
+		CorrelationId: queryMap["correlationId"],
+		TenantId:      tenantId,
+	}
+	if v := queryMap["types"]; v != "" {
+		filter.Types = map[string]bool{}
+		for _, t := range strings.Split(v, ",") {
+			filter.Types[t] = true
+		}
+	}
+
+	lastEventId := string(headers["last-event-id"])
+	if lastEventId == "" {
+		lastEventId = queryMap["lastEventId"]
+	}
+	var lastId uint64
+	resume := lastEventId != ""
+	if resume {
+		var err error
+		if lastId, err = strconv.ParseUint(lastEventId, 10, 64); err != nil {
+			writeResponse(c, 400, []byte(`{"error":"invalid 'Last-Event-ID'"}`), s.keepAlive)
+			return false
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	state := stateOf(c)
+	state.events = true
+	state.cancel = cancel
+
+	go func() {
+		defer cancel()
+
+		sub, backlog, gap := s.events.Subscribe(ctx, filter, lastId, resume)
+		defer s.events.Unsubscribe(sub)
+
+		w := newAsyncWriter(c, ctx, cancel, sseInFlight)
+
+		out := []byte("HTTP/1.1 200 OK\r\nContent-Type: text/event-stream\r\nCache-Control: no-cache\r\nConnection: close\r\n\r\nretry: 3000\n\n")
+		if gap {
+			out = append(out, ": eventos anteriores já saíram do log\n\n"...)
+		}
+		for i := range backlog {
+			out = backlog[i].AppendSSE(out)
+		}
+		w.send(out)
+
+		var heartbeat <-chan time.Time
+		if interval := config.Env.Events.Heartbeat; interval > 0 {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			heartbeat = ticker.C
+		}
+
+		for w.err == nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-heartbeat:
+				w.send([]byte(": ping\n\n"))
+			case e, ok := <-sub.C:
+				if !ok {
+					// Assinante lento descartado pelo broker; o cliente
+					// reconecta com Last-Event-ID.
+					w.close()
+					return
+				}
+				w.send(e.AppendSSE(nil))
+			}
+		}
+
+		w.close()
+	}()
+
+	return true
+}
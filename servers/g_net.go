@@ -3,13 +3,22 @@ package servers
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
+	"net"
+	"net/netip"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/Patrignani/patrignani-rinha-backend-go/internal/ratelimit"
 	"github.com/Patrignani/patrignani-rinha-backend-go/internal/services"
+	"github.com/Patrignani/patrignani-rinha-backend-go/internal/workers"
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/buffers"
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/config"
 	"github.com/panjf2000/gnet/v2"
 )
 
@@ -25,15 +34,31 @@ var (
 type GNetServer struct {
 	*gnet.BuiltinEventEngine
 	paymentService *services.PaymentService
-	paymentHandler func(ctx context.Context, body []byte)
+	reconciliation *services.ReconciliationService
+	queue          *workers.QueueWorker
+	autoscaler     *workers.Autoscaler
+	tenants        *services.TenantService
+	limiter        *ratelimit.Limiter
+	events         *services.EventRelay
+	paymentHandler func(ctx context.Context, msg *workers.Envelope) error
 	keepAlive      bool
 }
 
-func NewGNetServer(paymentService *services.PaymentService, keepAlive bool, paymentHandler func(ctx context.Context, body []byte)) *GNetServer {
-	return &GNetServer{paymentService: paymentService, keepAlive: keepAlive, paymentHandler: paymentHandler}
+func NewGNetServer(paymentService *services.PaymentService, reconciliation *services.ReconciliationService, queue *workers.QueueWorker, autoscaler *workers.Autoscaler, tenants *services.TenantService, limiter *ratelimit.Limiter, relay *services.EventRelay, keepAlive bool, paymentHandler func(ctx context.Context, msg *workers.Envelope) error) *GNetServer {
+	return &GNetServer{paymentService: paymentService, reconciliation: reconciliation, queue: queue, autoscaler: autoscaler, tenants: tenants, limiter: limiter, events: relay, keepAlive: keepAlive, paymentHandler: paymentHandler}
+}
+
+// errorJSON monta {"error": message} com o texto escapado como string JSON.
+func errorJSON(message string) []byte {
+	quoted, _ := json.Marshal(message)
+	return append(append([]byte(`{"error":`), quoted...), '}')
 }
 
 func writeResponse(c gnet.Conn, statusCode int, body []byte, keepAlive bool) {
+	writeResponseWithHeaders(c, statusCode, body, keepAlive, "")
+}
+
+func writeResponseWithHeaders(c gnet.Conn, statusCode int, body []byte, keepAlive bool, extraHeaders string) {
 	statusText := "OK"
 	if statusCode != 200 {
 		statusText = "Error"
@@ -51,12 +76,18 @@ func writeResponse(c gnet.Conn, statusCode int, body []byte, keepAlive bool) {
 	buf.WriteString("Content-Type: application/json\r\n")
 	buf.WriteString(fmt.Sprintf("Content-Length: %d\r\n", len(body)))
 	buf.WriteString(connHdr)
+	buf.WriteString(extraHeaders)
 	buf.WriteString("\r\n")
 	buf.Write(body)
 
-	_ = c.AsyncWrite(buf.Bytes(), nil)
-
-	bufPool.Put(buf)
+	// AsyncWrite não copia: o buffer só volta ao pool depois da escrita.
+	err := c.AsyncWrite(buf.Bytes(), func(c gnet.Conn, err error) error {
+		bufPool.Put(buf)
+		return nil
+	})
+	if err != nil {
+		bufPool.Put(buf)
+	}
 }
 
 func sendWithBlockingWrite(c gnet.Conn, keepAlive bool) gnet.Action {
@@ -70,6 +101,94 @@ func sendWithBlockingWrite(c gnet.Conn, keepAlive bool) gnet.Action {
 	return gnet.None
 }
 
+func writeRejection(c gnet.Conn, err error, keepAlive bool) {
+	retryAfter := retryAfterHeader(config.Env.Admission.RetryAfter)
+
+	var limit *ratelimit.LimitError
+	switch {
+	case errors.As(err, &limit):
+		writeResponseWithHeaders(c, 429, []byte(`{"error":"too many requests"}`), keepAlive, retryAfterHeader(limit.RetryAfter))
+	case errors.Is(err, workers.ErrBacklogFull):
+		writeResponseWithHeaders(c, 429, []byte(`{"error":"too many requests"}`), keepAlive, retryAfter)
+	case errors.Is(err, workers.ErrMemoryLimit):
+		writeResponseWithHeaders(c, 503, []byte(`{"error":"service unavailable"}`), keepAlive, retryAfter)
+	case errors.Is(err, services.ErrCorrelationConflict):
+		writeResponse(c, 409, []byte(`{"error":"correlationId already used"}`), keepAlive)
+	default:
+		writeResponse(c, 500, errorJSON(err.Error()), keepAlive)
+	}
+}
+
+// newEnvelope copia o corpo e registra o instante de chegada, o prazo total
+// (PAYMENT_TTL) e o traceparent recebido. Moedas sem cotação são recusadas
+// aqui, antes de ocupar a fila.
+func (s *GNetServer) newEnvelope(body []byte, headers map[string][]byte) (*workers.Envelope, error) {
+	now := time.Now()
+
+	msg, err := workers.NewEnvelope(buffers.Copy(body), now, headers["traceparent"])
+	if err != nil {
+		return nil, err
+	}
+
+	if msg.CallbackUrl != "" {
+		if err := services.ValidateCallbackURL(msg.CallbackUrl); err != nil {
+			msg.Release()
+			return nil, err
+		}
+	}
+
+	if err := s.paymentService.ValidateCurrency(msg.Currency); err != nil {
+		msg.Release()
+		return nil, err
+	}
+
+	if ttl := config.Env.Payment.TTL; ttl > 0 {
+		msg.Deadline = now.Add(ttl)
+	}
+
+	return msg, nil
+}
+
+// clientIP usa o último endereço do X-Forwarded-For quando o proxy na frente
+// é confiável (RATE_LIMIT_TRUST_FORWARDED): é o que o próprio proxy
+// acrescentou, enquanto os anteriores vêm do cliente e podem ser forjados.
+// Senão, o endereço da conexão.
+func clientIP(c gnet.Conn, headers map[string][]byte) netip.Addr {
+	if config.Env.RateLimit.ForwardedIP {
+		if forwarded := headers["x-forwarded-for"]; len(forwarded) > 0 {
+			last := forwarded
+			if i := bytes.LastIndexByte(forwarded, ','); i >= 0 {
+				last = forwarded[i+1:]
+			}
+			if addr, err := netip.ParseAddr(string(bytes.TrimSpace(last))); err == nil {
+				return addr.Unmap()
+			}
+		}
+	}
+
+	if addr, ok := c.RemoteAddr().(*net.TCPAddr); ok {
+		return addr.AddrPort().Addr().Unmap()
+	}
+	return netip.Addr{}
+}
+
+// allow aplica o Limiter com custo n. O limite por tenant usa a chave
+// autenticada, para que variações do header Authorization não criem buckets
+// novos; sem autenticação, só valem os limites por IP e global.
+func (s *GNetServer) allow(c gnet.Conn, headers map[string][]byte, n int) error {
+	key := ""
+	if s.tenants != nil {
+		if tenant, ok := s.tenants.Authenticate(headers["authorization"]); ok {
+			key = tenant.ID
+		}
+	}
+	return s.limiter.Allow(clientIP(c, headers), key, n, time.Now())
+}
+
+func retryAfterHeader(wait time.Duration) string {
+	return fmt.Sprintf("Retry-After: %d\r\n", max(int(math.Ceil(wait.Seconds())), 1))
+}
+
 func readLine(data []byte) (line, rest []byte, ok bool) {
 	idx := bytes.Index(data, []byte("\r\n"))
 	if idx < 0 {
@@ -113,7 +232,21 @@ func parseQueryString(qs []byte) (map[string]string, bool) {
 }
 
 func (s *GNetServer) OnTraffic(c gnet.Conn) gnet.Action {
+	state := stateOf(c)
+	if state.events {
+		// Num stream SSE o cliente só recebe.
+		_, _ = c.Discard(-1)
+		return gnet.None
+	}
+
 	for {
+		if state.busy.Load() {
+			// A resposta anterior ainda está sendo produzida; o resto do
+			// buffer é lido quando ela terminar.
+			return gnet.None
+		}
+		state.setBody(nil)
+
 		buf, _ := c.Peek(-1)
 		if len(buf) == 0 {
 			return gnet.None
@@ -168,20 +301,70 @@ func (s *GNetServer) OnTraffic(c gnet.Conn) gnet.Action {
 		bodyStart := len(buf) - len(rest)
 		body := buf[bodyStart : bodyStart+cl]
 
+		partsPath := bytes.SplitN(path, []byte("?"), 2)
+		route := string(partsPath[0])
+		admin := strings.HasPrefix(route, "/admin/")
+
+		// O limite é cobrado antes de qualquer parse, para que uma requisição
+		// recusada não seja parseada nem copiada. Um lote custa um token por
+		// item, cobrado por handleBatch depois de contar os itens.
+		var limitErr error
+		if s.limiter != nil && !admin && !(method == "POST" && route == "/payments/batch") {
+			limitErr = s.allow(c, headers, 1)
+		}
+
+		// body aponta para o buffer interno do gnet, que é reutilizado depois
+		// do Discard. O corpo de um pagamento vai para o envelope; os demais são
+		// copiados para state.body, que vale até a próxima requisição da
+		// conexão, inclusive dentro de respondAsync.
+		var msg *workers.Envelope
+		var msgErr error
+		if limitErr == nil {
+			if method == "POST" && bytes.Equal(path, []byte("/payments")) {
+				msg, msgErr = s.newEnvelope(body, headers)
+			} else if cl > 0 {
+				state.setBody(buffers.Copy(body))
+				body = state.body.Bytes()
+			}
+		}
+
 		_, _ = c.Discard(totalConsumed)
 
-		if method == "GET" {
-			partsPath := bytes.SplitN(path, []byte("?"), 2)
-			route := string(partsPath[0])
+		if limitErr != nil {
+			writeRejection(c, limitErr, s.keepAlive)
+			if !s.keepAlive {
+				return gnet.Close
+			}
+			continue
+		}
 
+		if admin {
+			if s.handleAdmin(c, method, route, partsPath, headers, body) {
+				continue
+			}
+			if !s.keepAlive {
+				return gnet.Close
+			}
+			continue
+		}
+
+		if method == "GET" {
 			if route == "/payments-summary" {
 
+				tenantId, ok := s.authenticate(c, headers)
+				if !ok {
+					if !s.keepAlive {
+						return gnet.Close
+					}
+					continue
+				}
+
 				if len(partsPath) < 2 {
 
-					v, err := s.paymentService.GetPaymentSummary(context.TODO(), nil, nil)
+					v, err := s.paymentService.GetPaymentSummary(context.TODO(), tenantId, nil, nil, "")
 
 					if err != nil {
-						writeResponse(c, 400, []byte(fmt.Sprintf(`{"error":"%v"}`, err)), s.keepAlive)
+						writeResponse(c, 400, errorJSON(err.Error()), s.keepAlive)
 						if !s.keepAlive {
 							return gnet.Close
 						}
@@ -190,7 +373,7 @@ func (s *GNetServer) OnTraffic(c gnet.Conn) gnet.Action {
 
 					jsonBytes, err := v.MarshalJSON()
 					if err != nil {
-						writeResponse(c, 400, []byte(fmt.Sprintf(`{"error":"%v"}`, err)), s.keepAlive)
+						writeResponse(c, 400, errorJSON(err.Error()), s.keepAlive)
 						if !s.keepAlive {
 							return gnet.Close
 						}
@@ -243,10 +426,10 @@ func (s *GNetServer) OnTraffic(c gnet.Conn) gnet.Action {
 					toTime = &t
 				}
 
-				v, err := s.paymentService.GetPaymentSummary(context.TODO(), fromTime, toTime)
+				v, err := s.paymentService.GetPaymentSummary(context.TODO(), tenantId, fromTime, toTime, queryMap["currency"])
 
 				if err != nil {
-					writeResponse(c, 400, []byte(fmt.Sprintf(`{"error":"%v"}`, err)), s.keepAlive)
+					writeResponse(c, 400, errorJSON(err.Error()), s.keepAlive)
 					if !s.keepAlive {
 						return gnet.Close
 					}
@@ -255,7 +438,7 @@ func (s *GNetServer) OnTraffic(c gnet.Conn) gnet.Action {
 
 				jsonBytes, err := v.MarshalJSON()
 				if err != nil {
-					writeResponse(c, 400, []byte(fmt.Sprintf(`{"error":"%v"}`, err)), s.keepAlive)
+					writeResponse(c, 400, errorJSON(err.Error()), s.keepAlive)
 					if !s.keepAlive {
 						return gnet.Close
 					}
@@ -266,6 +449,38 @@ func (s *GNetServer) OnTraffic(c gnet.Conn) gnet.Action {
 				if !s.keepAlive {
 					return gnet.Close
 				}
+			} else if route == "/payments" {
+				if s.handlePaymentList(c, partsPath, headers) {
+					continue
+				}
+				if !s.keepAlive {
+					return gnet.Close
+				}
+				continue
+			} else if route == "/events" {
+				if s.handleEvents(c, partsPath, headers) {
+					return gnet.None
+				}
+				if !s.keepAlive {
+					return gnet.Close
+				}
+				continue
+			} else if strings.HasPrefix(route, "/payments/batch/") {
+				if s.handleBatchStatus(c, strings.TrimPrefix(route, "/payments/batch/"), headers) {
+					continue
+				}
+				if !s.keepAlive {
+					return gnet.Close
+				}
+				continue
+			} else if strings.HasPrefix(route, "/payments/") {
+				if s.handlePaymentLookup(c, strings.TrimPrefix(route, "/payments/"), headers) {
+					continue
+				}
+				if !s.keepAlive {
+					return gnet.Close
+				}
+				continue
 			} else {
 				println(fmt.Sprintf("ROTA NÃO EXISTE: %s", route))
 				writeResponse(c, 400, []byte(`{"error":"not found"}`), s.keepAlive)
@@ -275,8 +490,38 @@ func (s *GNetServer) OnTraffic(c gnet.Conn) gnet.Action {
 				continue
 			}
 
+		} else if method == "POST" && route == "/payments/batch" {
+			if s.handleBatch(c, headers, body) {
+				continue
+			}
+			if !s.keepAlive {
+				return gnet.Close
+			}
+
+		} else if method == "POST" && strings.HasPrefix(route, "/payments/") && strings.HasSuffix(route, "/refund") {
+			s.handleRefund(c, strings.TrimSuffix(strings.TrimPrefix(route, "/payments/"), "/refund"), headers, body)
+			if !s.keepAlive {
+				return gnet.Close
+			}
+
 		} else if method == "POST" {
-			if !bytes.Equal(path, []byte("/payments")) {
+			if errors.Is(msgErr, services.ErrUnknownCurrency) {
+				writeResponse(c, 400, errorJSON(msgErr.Error()), s.keepAlive)
+				if !s.keepAlive {
+					return gnet.Close
+				}
+				continue
+			}
+
+			if msgErr != nil {
+				writeResponse(c, 400, []byte(`{"error":"invalid body"}`), s.keepAlive)
+				if !s.keepAlive {
+					return gnet.Close
+				}
+				continue
+			}
+
+			if msg == nil {
 				writeResponse(c, 404, []byte(`{"error":"not found"}`), s.keepAlive)
 				if !s.keepAlive {
 					return gnet.Close
@@ -284,13 +529,77 @@ func (s *GNetServer) OnTraffic(c gnet.Conn) gnet.Action {
 				continue
 			}
 
+			var tenant *services.Tenant
+			if s.tenants != nil {
+				var ok bool
+				if tenant, ok = s.tenants.Authenticate(headers["authorization"]); !ok {
+					msg.Release()
+					writeResponse(c, 401, []byte(`{"error":"unauthorized"}`), s.keepAlive)
+					if !s.keepAlive {
+						return gnet.Close
+					}
+					continue
+				}
+
+				if err := tenant.Admit(msg.RequestedAt); err != nil {
+					msg.Release()
+					writeRejection(c, err, s.keepAlive)
+					if !s.keepAlive {
+						return gnet.Close
+					}
+					continue
+				}
+				msg.TenantId = tenant.ID
+			}
+
+			if msg.ScheduledAt.After(msg.RequestedAt) {
+				s.handleSchedule(c, msg, tenant)
+				continue
+			}
+
+			created, err := s.paymentService.TrackQueued(msg)
+			if err != nil {
+				if tenant != nil {
+					tenant.Refund()
+				}
+				msg.Release()
+				writeRejection(c, err, s.keepAlive)
+				if !s.keepAlive {
+					return gnet.Close
+				}
+				continue
+			}
+			accepted := s.paymentService.AcceptedEvent(msg)
+			if err := s.paymentHandler(context.Background(), msg); err != nil {
+				if tenant != nil {
+					tenant.Refund()
+				}
+				if created {
+					s.paymentService.Untrack(msg.CorrelationId)
+				}
+				msg.Release()
+				writeRejection(c, err, s.keepAlive)
+				if !s.keepAlive {
+					return gnet.Close
+				}
+				continue
+			}
+			s.paymentService.Publish(accepted)
+
 			sendWithBlockingWrite(c, s.keepAlive)
-			s.paymentHandler(context.Background(), body)
 
 			if !s.keepAlive {
 				return gnet.Close
 			}
 
+		} else if method == "DELETE" && strings.HasPrefix(route, "/payments/") {
+			if s.handleCancel(c, strings.TrimPrefix(route, "/payments/"), headers) {
+				continue
+			}
+			if !s.keepAlive {
+				return gnet.Close
+			}
+
 		} else {
 			writeResponse(c, 405, []byte(`{"error":"method not allowed"}`), s.keepAlive)
 			if !s.keepAlive {
@@ -301,6 +610,7 @@ func (s *GNetServer) OnTraffic(c gnet.Conn) gnet.Action {
 }
 
 func (s *GNetServer) OnOpened(c gnet.Conn) (out []byte, action gnet.Action) {
+	c.SetContext(&connState{})
 	_ = c.SetDeadline(time.Now().Add(60 * time.Second))
 	return nil, gnet.None
 }
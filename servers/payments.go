@@ -0,0 +1,455 @@
+package servers
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Patrignani/patrignani-rinha-backend-go/internal/events"
+	"github.com/Patrignani/patrignani-rinha-backend-go/internal/services"
+	"github.com/Patrignani/patrignani-rinha-backend-go/internal/workers"
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/config"
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/models"
+	"github.com/panjf2000/gnet/v2"
+)
+
+// authenticate devolve o tenant da requisição quando AUTH_ENABLED está ligado
+// (vazio quando desligado). Sem chave válida já responde 401.
+func (s *GNetServer) authenticate(c gnet.Conn, headers map[string][]byte) (string, bool) {
+	if s.tenants == nil {
+		return "", true
+	}
+
+	tenant, ok := s.tenants.Authenticate(headers["authorization"])
+	if !ok {
+		writeResponse(c, 401, []byte(`{"error":"unauthorized"}`), s.keepAlive)
+		return "", false
+	}
+	return tenant.ID, true
+}
+
+func (s *GNetServer) handlePaymentLookup(c gnet.Conn, correlationId string, headers map[string][]byte) bool {
+	tenantId, ok := s.authenticate(c, headers)
+	if !ok {
+		return false
+	}
+
+	if correlationId == "" {
+		writeResponse(c, 400, []byte(`{"error":"invalid 'correlationId'"}`), s.keepAlive)
+		return false
+	}
+
+	// Fora do Tracker a consulta vai ao banco, então não roda no loop.
+	keepAlive := s.keepAlive
+	s.respondAsync(c, func(ctx context.Context) {
+		status, found, err := s.paymentService.GetPayment(ctx, correlationId, tenantId)
+		if err != nil {
+			writeResponse(c, 500, errorJSON(err.Error()), keepAlive)
+			return
+		}
+
+		if !found {
+			writeResponse(c, 404, []byte(`{"error":"not found"}`), keepAlive)
+			return
+		}
+
+		jsonBytes, err := status.MarshalJSON()
+		if err != nil {
+			writeResponse(c, 500, errorJSON(err.Error()), keepAlive)
+			return
+		}
+
+		writeResponse(c, 200, jsonBytes, keepAlive)
+	})
+	return true
+}
+
+// handleRefund enfileira um estorno e responde 202 com o refundId; o resultado
+// sai em refund_history e nos totais do /payments-summary.
+func (s *GNetServer) handleRefund(c gnet.Conn, correlationId string, headers map[string][]byte, body []byte) {
+	tenantId, ok := s.authenticate(c, headers)
+	if !ok {
+		return
+	}
+
+	msg, err := services.NewRefund(correlationId, body, time.Now(), headers["traceparent"])
+	if err != nil {
+		writeResponse(c, 400, errorJSON(err.Error()), s.keepAlive)
+		return
+	}
+	msg.TenantId = tenantId
+
+	response := []byte(fmt.Sprintf(`{"refundId":%q,"status":%q}`, msg.RefundId, models.RefundPending))
+	if err := s.paymentHandler(context.Background(), msg); err != nil {
+		msg.Release()
+		writeRejection(c, err, s.keepAlive)
+		return
+	}
+
+	writeResponse(c, 202, response, s.keepAlive)
+}
+
+// handleSchedule agenda um POST /payments com scheduledAt futuro fora do loop
+// (respondAsync) e responde 202. Assume a posse de msg e devolve a cota do
+// tenant quando o pagamento não foi agendado.
+func (s *GNetServer) handleSchedule(c gnet.Conn, msg *workers.Envelope, tenant *services.Tenant) {
+	keepAlive := s.keepAlive
+	s.respondAsync(c, func(ctx context.Context) {
+		defer msg.Release()
+
+		err := s.paymentService.Schedule(ctx, msg)
+		if err != nil && tenant != nil {
+			tenant.Refund()
+		}
+
+		switch {
+		case errors.Is(err, services.ErrInvalidSchedule):
+			writeResponse(c, 400, errorJSON(err.Error()), keepAlive)
+		case errors.Is(err, services.ErrAlreadyScheduled):
+			writeResponse(c, 409, []byte(`{"error":"payment already scheduled"}`), keepAlive)
+		case err != nil:
+			writeResponse(c, 500, errorJSON(err.Error()), keepAlive)
+		default:
+			writeResponse(c, 202, []byte(fmt.Sprintf(`{"correlationId":%q,"status":%q,"scheduledAt":%q}`,
+				msg.CorrelationId, models.PaymentScheduled, msg.ScheduledAt.UTC().Format(time.RFC3339Nano))), keepAlive)
+		}
+	})
+}
+
+// handleCancel atende DELETE /payments/{correlationId}, que cancela um
+// pagamento agendado enquanto ele não foi para a fila. Devolve true quando a
+// resposta sai fora do loop (respondAsync).
+func (s *GNetServer) handleCancel(c gnet.Conn, correlationId string, headers map[string][]byte) bool {
+	tenantId, ok := s.authenticate(c, headers)
+	if !ok {
+		return false
+	}
+
+	keepAlive := s.keepAlive
+	s.respondAsync(c, func(ctx context.Context) {
+		status, found, err := s.paymentService.CancelScheduled(ctx, correlationId, tenantId)
+		switch {
+		case err != nil:
+			writeResponse(c, 500, errorJSON(err.Error()), keepAlive)
+		case !found:
+			writeResponse(c, 404, []byte(`{"error":"not found"}`), keepAlive)
+		case status != models.PaymentCancelled:
+			writeResponse(c, 409, []byte(`{"error":"payment already dispatched"}`), keepAlive)
+		default:
+			writeResponse(c, 200, []byte(fmt.Sprintf(`{"correlationId":%q,"status":%q}`, correlationId, status)), keepAlive)
+		}
+	})
+	return true
+}
+
+// handleBatch atende POST /payments/batch. Todos os itens são validados
+// antes de qualquer um entrar na fila: com um item inválido o lote é recusado
+// com 422 e o resultado de cada item; sem espaço na fila, com 429/503.
+// Devolve true quando a resposta sai fora do loop (respondAsync).
+func (s *GNetServer) handleBatch(c gnet.Conn, headers map[string][]byte, body []byte) bool {
+	var tenant *services.Tenant
+	if s.tenants != nil {
+		var ok bool
+		if tenant, ok = s.tenants.Authenticate(headers["authorization"]); !ok {
+			writeResponse(c, 401, []byte(`{"error":"unauthorized"}`), s.keepAlive)
+			return false
+		}
+	}
+
+	var msgs []*workers.Envelope
+	defer func() {
+		for _, msg := range msgs {
+			msg.Release()
+		}
+	}()
+
+	response := models.BatchResponse{Items: []models.BatchItemResult{}}
+	seen := map[string]bool{}
+	err := services.SplitBatch(body, config.Env.BatchMaxItems, func(item []byte) {
+		result := models.BatchItemResult{Index: len(response.Items), Status: models.BatchItemValid}
+
+		msg, err := s.newEnvelope(item, headers)
+		if err == nil {
+			result.CorrelationId = strings.Clone(msg.CorrelationId)
+			if err = services.ValidateBatchItem(msg); err == nil && seen[result.CorrelationId] {
+				err = errors.New("correlationId repetido no lote")
+			}
+			if err == nil {
+				seen[result.CorrelationId] = true
+				msgs = append(msgs, msg)
+			} else {
+				msg.Release()
+			}
+		}
+
+		if err != nil {
+			result.Status = models.BatchItemInvalid
+			result.Error = err.Error()
+			response.Rejected++
+		}
+		response.Items = append(response.Items, result)
+	})
+
+	// Cada item conta como uma requisição no Limiter, inclusive num lote
+	// recusado.
+	if s.limiter != nil {
+		if err := s.allow(c, headers, max(len(response.Items), 1)); err != nil {
+			writeRejection(c, err, s.keepAlive)
+			return false
+		}
+	}
+
+	switch {
+	case errors.Is(err, services.ErrBatchTooLarge):
+		writeResponse(c, 413, errorJSON(err.Error()), s.keepAlive)
+		return false
+	case err != nil:
+		writeResponse(c, 400, errorJSON(err.Error()), s.keepAlive)
+		return false
+	case len(response.Items) == 0:
+		writeResponse(c, 400, []byte(`{"error":"lote vazio"}`), s.keepAlive)
+		return false
+	}
+
+	if response.Rejected > 0 {
+		response.Rejected = len(response.Items)
+		writeBatchResponse(c, 422, &response, s.keepAlive)
+		return false
+	}
+
+	tenantId := ""
+	if tenant != nil {
+		if err := tenant.AdmitN(time.Now(), len(msgs)); err != nil {
+			writeRejection(c, err, s.keepAlive)
+			return false
+		}
+		tenantId = tenant.ID
+		for _, msg := range msgs {
+			msg.TenantId = tenantId
+		}
+	}
+
+	accepted := make([]events.Event, len(msgs))
+	for i, msg := range msgs {
+		accepted[i] = s.paymentService.AcceptedEvent(msg)
+	}
+
+	// A gravação do lote vai ao banco, então sai do loop; a fila reserva o
+	// lote inteiro de uma vez, então a ordem entre conexões não importa.
+	batch := msgs
+	msgs = nil
+	keepAlive := s.keepAlive
+	s.respondAsync(c, func(ctx context.Context) {
+		batchId, err := s.paymentService.SubmitBatch(ctx, batch, tenantId)
+		if err != nil {
+			for _, msg := range batch {
+				msg.Release()
+			}
+			if tenant != nil {
+				tenant.RefundN(len(batch))
+			}
+			writeRejection(c, err, keepAlive)
+			return
+		}
+		// As referências agora são da fila.
+		for _, event := range accepted {
+			s.paymentService.Publish(event)
+		}
+
+		response.BatchId = batchId
+		response.Accepted = len(response.Items)
+		for i := range response.Items {
+			response.Items[i].Status = models.BatchItemAccepted
+		}
+		writeBatchResponse(c, 202, &response, keepAlive)
+	})
+	return true
+}
+
+func writeBatchResponse(c gnet.Conn, statusCode int, response *models.BatchResponse, keepAlive bool) {
+	jsonBytes, err := response.MarshalJSON()
+	if err != nil {
+		writeResponse(c, 500, errorJSON(err.Error()), keepAlive)
+		return
+	}
+	writeResponse(c, statusCode, jsonBytes, keepAlive)
+}
+
+// handleBatchStatus atende GET /payments/batch/{batchId} fora do loop
+// (respondAsync) e devolve true quando a resposta sai por lá.
+func (s *GNetServer) handleBatchStatus(c gnet.Conn, batchId string, headers map[string][]byte) bool {
+	tenantId, ok := s.authenticate(c, headers)
+	if !ok {
+		return false
+	}
+
+	keepAlive := s.keepAlive
+	s.respondAsync(c, func(ctx context.Context) {
+		status, found, err := s.paymentService.GetBatch(ctx, batchId, tenantId)
+		if err != nil {
+			writeResponse(c, 500, errorJSON(err.Error()), keepAlive)
+			return
+		}
+
+		if !found {
+			writeResponse(c, 404, []byte(`{"error":"not found"}`), keepAlive)
+			return
+		}
+
+		jsonBytes, err := status.MarshalJSON()
+		if err != nil {
+			writeResponse(c, 500, errorJSON(err.Error()), keepAlive)
+			return
+		}
+
+		writeResponse(c, 200, jsonBytes, keepAlive)
+	})
+	return true
+}
+
+// handlePaymentList atende GET /payments?from=&to=&processor=&minAmount=&maxAmount=&status=&after=&limit=&format=
+// com o resultado em NDJSON (padrão) ou CSV, sem montar a resposta inteira em
+// memória. Cada linha traz o seu cursor; para paginar, passe o da última linha
+// em "after". limit padrão 1000; limit=0 exporta tudo. Devolve true quando a
+// resposta passou a ser escrita em segundo plano.
+func (s *GNetServer) handlePaymentList(c gnet.Conn, partsPath [][]byte, headers map[string][]byte) bool {
+	tenantId, ok := s.authenticate(c, headers)
+	if !ok {
+		return false
+	}
+
+	queryMap := map[string]string{}
+	if len(partsPath) > 1 && len(partsPath[1]) > 0 {
+		if queryMap, ok = parseQueryString(partsPath[1]); !ok {
+			writeResponse(c, 400, []byte(`{"error":"invalid query"}`), s.keepAlive)
+			return false
+		}
+	}
+
+	filter, format, err := parsePaymentFilter(queryMap)
+	if err != nil {
+		writeResponse(c, 400, errorJSON(err.Error()), s.keepAlive)
+		return false
+	}
+	filter.TenantId = tenantId
+
+	switch format {
+	case "csv":
+		s.stream(c, "text/csv", s.keepAlive, func(ctx context.Context, w *chunkWriter) error {
+			out := csv.NewWriter(w)
+			_ = out.Write([]string{"correlationId", "status", "amount", "processor", "routingStrategy", "tenantId", "reason", "createdAt", "cursor"})
+
+			err := s.paymentService.ListPayments(ctx, filter, func(record models.PaymentRecord) error {
+				return out.Write([]string{
+					record.CorrelationId,
+					record.Status,
+					strconv.FormatFloat(record.Amount, 'f', 2, 64),
+					record.Processor,
+					record.Strategy,
+					record.TenantId,
+					record.Reason,
+					record.CreatedAt.Format(time.RFC3339Nano),
+					record.Cursor,
+				})
+			})
+
+			out.Flush()
+			if err != nil {
+				return err
+			}
+			return out.Error()
+		})
+
+	default:
+		s.stream(c, "application/x-ndjson", s.keepAlive, func(ctx context.Context, w *chunkWriter) error {
+			return s.paymentService.ListPayments(ctx, filter, func(record models.PaymentRecord) error {
+				jsonBytes, err := record.MarshalJSON()
+				if err != nil {
+					return err
+				}
+				_, err = w.Write(append(jsonBytes, '\n'))
+				return err
+			})
+		})
+	}
+
+	return true
+}
+
+func parsePaymentFilter(queryMap map[string]string) (models.PaymentFilter, string, error) {
+	filter := models.PaymentFilter{Limit: 1000}
+
+	for key, raw := range queryMap {
+		value, err := url.QueryUnescape(raw)
+		if err != nil {
+			return filter, "", fmt.Errorf("invalid '%s'", key)
+		}
+		queryMap[key] = value
+	}
+
+	for _, key := range []string{"from", "to"} {
+		if value := queryMap[key]; value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return filter, "", fmt.Errorf("invalid '%s' timestamp format", key)
+			}
+			if key == "from" {
+				filter.From = &t
+			} else {
+				filter.To = &t
+			}
+		}
+	}
+
+	for _, key := range []string{"minAmount", "maxAmount"} {
+		if value := queryMap[key]; value != "" {
+			amount, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return filter, "", fmt.Errorf("invalid '%s'", key)
+			}
+			if key == "minAmount" {
+				filter.MinAmount = &amount
+			} else {
+				filter.MaxAmount = &amount
+			}
+		}
+	}
+
+	if value := queryMap["limit"]; value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 0 {
+			return filter, "", fmt.Errorf("invalid 'limit'")
+		}
+		filter.Limit = limit
+	}
+
+	if value := queryMap["after"]; value != "" {
+		cursor, err := services.ParseCursor(value)
+		if err != nil {
+			return filter, "", fmt.Errorf("invalid 'after'")
+		}
+		filter.After = &cursor
+	}
+
+	filter.Processor = queryMap["processor"]
+
+	switch filter.Status = queryMap["status"]; filter.Status {
+	case "", models.PaymentProcessed, models.PaymentDeadLettered:
+	default:
+		return filter, "", fmt.Errorf("invalid 'status': only %s and %s are stored", models.PaymentProcessed, models.PaymentDeadLettered)
+	}
+
+	format := queryMap["format"]
+	switch format {
+	case "", "ndjson", "csv":
+	default:
+		return filter, "", fmt.Errorf("invalid 'format'")
+	}
+
+	return filter, format, nil
+}
@@ -0,0 +1,301 @@
+package servers
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Patrignani/patrignani-rinha-backend-go/internal/processors"
+	"github.com/Patrignani/patrignani-rinha-backend-go/internal/repositories"
+	"github.com/Patrignani/patrignani-rinha-backend-go/internal/services"
+	"github.com/Patrignani/patrignani-rinha-backend-go/internal/workers"
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/panjf2000/gnet/v2"
+)
+
+// pipelineCheck guarda o valor esperado de cada correlationId e acusa qualquer
+// leitura que não bata: com um buffer do pool reutilizado antes da hora, o
+// processador ou o INSERT enxergam o conteúdo de outra requisição.
+type pipelineCheck struct {
+	t        *testing.T
+	expected sync.Map
+	paid     atomic.Int64
+	inserted atomic.Int64
+	failures atomic.Int64
+}
+
+// verify confere os valores, espera um pouco e confere de novo, para pegar um
+// buffer liberado e reaproveitado enquanto ainda está em uso.
+func (p *pipelineCheck) verify(stage, correlationId string, amount float64) {
+	for range 2 {
+		want, ok := p.expected.Load(correlationId)
+		if !ok || want.(float64) != amount {
+			if p.failures.Add(1) <= 5 {
+				p.t.Errorf("%s: correlationId %q com amount %v não corresponde a nenhuma requisição", stage, correlationId, amount)
+			}
+			return
+		}
+		time.Sleep(50 * time.Microsecond)
+	}
+}
+
+type pipelineProcessor struct {
+	check *pipelineCheck
+}
+
+func (p *pipelineProcessor) Name() string                      { return "default" }
+func (p *pipelineProcessor) Fee() float64                      { return 0.05 }
+func (p *pipelineProcessor) Priority() int                     { return 0 }
+func (p *pipelineProcessor) Weight() int                       { return 1 }
+func (p *pipelineProcessor) Timeout() time.Duration            { return time.Second }
+func (p *pipelineProcessor) Currency() string                  { return "" }
+func (p *pipelineProcessor) Healthy() bool                     { return true }
+func (p *pipelineProcessor) MinResponseTime() time.Duration    { return 0 }
+func (p *pipelineProcessor) CheckHealth(context.Context) error { return nil }
+
+func (p *pipelineProcessor) Pay(ctx context.Context, payment models.PaymentRequest) (int, error) {
+	p.check.verify("processador", payment.CorrelationId, payment.Amount)
+	p.check.paid.Add(1)
+	return 200, nil
+}
+
+func (p *pipelineProcessor) Summary(context.Context, time.Time, time.Time) (models.PaymentSummary, error) {
+	return models.PaymentSummary{}, nil
+}
+
+func (p *pipelineProcessor) Lookup(context.Context, string) (models.ProcessorPayment, bool, error) {
+	return models.ProcessorPayment{}, false, processors.ErrLookupUnsupported
+}
+
+func (p *pipelineProcessor) CanLookup() bool { return false }
+
+func (p *pipelineProcessor) Refund(context.Context, models.RefundRequest) (int, error) {
+	return 0, processors.ErrRefundUnsupported
+}
+
+type errRow struct{}
+
+func (errRow) Scan(...any) error { return errors.New("sem banco") }
+
+// pipelinePostgres atende só o INSERT de entry_history, que roda em segundo
+// plano depois que a mensagem já saiu da fila.
+type pipelinePostgres struct {
+	check *pipelineCheck
+}
+
+func (p *pipelinePostgres) Close() {}
+
+func (p *pipelinePostgres) Exec(ctx context.Context, sql string, args ...interface{}) (int64, error) {
+	if strings.Contains(sql, "INSERT INTO entry_history") {
+		p.check.verify("insert", args[0].(string), args[1].(float64))
+		p.check.inserted.Add(1)
+	}
+	return 1, nil
+}
+
+func (p *pipelinePostgres) QueryRow(context.Context, string, ...interface{}) pgx.Row {
+	return errRow{}
+}
+
+func (p *pipelinePostgres) Query(context.Context, string, ...interface{}) (pgx.Rows, error) {
+	return nil, errors.New("sem banco")
+}
+
+func (p *pipelinePostgres) Begin(context.Context) (pgx.Tx, error) {
+	return nil, errors.New("sem banco")
+}
+
+func (p *pipelinePostgres) CopyTo(context.Context, io.Writer, string) (int64, error) {
+	return 0, errors.New("sem banco")
+}
+
+func (p *pipelinePostgres) Listen(context.Context, string, func()) error {
+	return errors.New("sem banco")
+}
+
+// TestPipelineOwnership passa requisições reais pelo GNetServer, QueueWorker e
+// RunQueue, com pipelining e corpos de tamanhos variados, e confere que o
+// processador e o INSERT recebem exatamente o que cada cliente enviou. Deve
+// rodar com -race.
+func TestPipelineOwnership(t *testing.T) {
+	const (
+		clients  = 8
+		requests = 300
+	)
+
+	check := &pipelineCheck{t: t}
+	pg := &pipelinePostgres{check: check}
+
+	registry := processors.NewRegistry(processors.WindowConfig{Size: 100, MaxAge: time.Minute}, &pipelineProcessor{check: check})
+	strategy, err := processors.NewStrategy("priority", registry, processors.CostModel{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	queue := workers.NewQueueWorker([workers.LaneCount]workers.LaneConfig{
+		workers.LaneNew:    {Buffer: 64, Weight: 6},
+		workers.LaneRetry:  {Buffer: 64, Weight: 3},
+		workers.LaneReplay: {Buffer: 64, Weight: 1},
+	})
+
+	paymentService := services.NewPaymentService(
+		repositories.NewPaymentRepository(pg),
+		repositories.NewDeadLetterRepository(pg),
+		repositories.NewRefundRepository(pg),
+		repositories.NewBatchRepository(pg),
+		repositories.NewScheduleRepository(pg),
+		queue, registry, strategy,
+		services.NewTracker(time.Minute, 0), nil, nil,
+		services.NewRateService(nil, "", "BRL"),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go queue.Consume(ctx, 4, paymentService.RunQueue)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	server := NewGNetServer(paymentService, nil, queue, nil, nil, nil, nil, true, func(ctx context.Context, msg *workers.Envelope) error {
+		return queue.Send(msg)
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- gnet.Run(server, "tcp://"+addr, gnet.WithMulticore(true), gnet.WithLogger(nil))
+	}()
+	defer func() {
+		_ = gnet.Stop(context.Background(), "tcp://"+addr)
+		<-done
+	}()
+
+	var conn net.Conn
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		if conn, err = net.Dial("tcp", addr); err == nil {
+			conn.Close()
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("servidor não subiu: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	var wg sync.WaitGroup
+	var accepted atomic.Int64
+	for client := range clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			accepted.Add(int64(runPipelineClient(t, check, addr, client, requests)))
+		}()
+	}
+	wg.Wait()
+
+	want := accepted.Load()
+	for deadline := time.Now().Add(10 * time.Second); check.inserted.Load() < want && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if check.paid.Load() != want || check.inserted.Load() != want {
+		t.Fatalf("aceitos %d, pagos %d, gravados %d", want, check.paid.Load(), check.inserted.Load())
+	}
+	if check.failures.Load() > 0 {
+		t.Fatalf("%d leituras de buffers reutilizados", check.failures.Load())
+	}
+}
+
+// runPipelineClient envia as requisições em rajadas de até 8 por escrita, com
+// campos em ordem e espaçamento aleatórios, e devolve quantas foram aceitas.
+func runPipelineClient(t *testing.T, check *pipelineCheck, addr string, client, requests int) int {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Error(err)
+		return 0
+	}
+	defer conn.Close()
+
+	rnd := rand.New(rand.NewSource(int64(client)))
+	reader := bufio.NewReader(conn)
+	accepted := 0
+
+	for sent := 0; sent < requests; {
+		burst := min(1+rnd.Intn(8), requests-sent)
+
+		var out strings.Builder
+		for i := range burst {
+			correlationId := fmt.Sprintf("%08x-0000-4000-8000-%012x", client, sent+i)
+			amount := float64(rnd.Intn(1_000_000)) / 100
+			check.expected.Store(correlationId, amount)
+
+			body := fmt.Sprintf(`{"correlationId":%q,"amount":%v}`, correlationId, amount)
+			if rnd.Intn(2) == 0 {
+				body = fmt.Sprintf(`{ "amount" : %v ,%s"correlationId" : %q }`, amount, strings.Repeat(" ", rnd.Intn(512)), correlationId)
+			}
+			fmt.Fprintf(&out, "POST /payments HTTP/1.1\r\nHost: test\r\nContent-Type: application/json\r\nContent-Length: %d\r\n\r\n%s", len(body), body)
+		}
+
+		if _, err := io.WriteString(conn, out.String()); err != nil {
+			t.Error(err)
+			return accepted
+		}
+
+		for range burst {
+			statusCode, err := readStatus(reader)
+			if err != nil {
+				t.Error(err)
+				return accepted
+			}
+			if statusCode < 300 {
+				accepted++
+			}
+		}
+		sent += burst
+	}
+
+	return accepted
+}
+
+func readStatus(reader *bufio.Reader) (int, error) {
+	status, err := reader.ReadString('\n')
+	if err != nil {
+		return 0, err
+	}
+
+	var statusCode int
+	if _, err := fmt.Sscanf(status, "HTTP/1.1 %d", &statusCode); err != nil {
+		return 0, fmt.Errorf("status inválido %q", status)
+	}
+
+	length := 0
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return 0, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		if name, value, ok := strings.Cut(line, ":"); ok && strings.EqualFold(name, "content-length") {
+			fmt.Sscanf(strings.TrimSpace(value), "%d", &length)
+		}
+	}
+
+	_, err = reader.Discard(length)
+	return statusCode, err
+}
@@ -0,0 +1,99 @@
+package servers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/Patrignani/patrignani-rinha-backend-go/pkg/config"
+	"github.com/panjf2000/gnet/v2"
+)
+
+const (
+	streamChunkSize = 32 << 10
+	streamInFlight  = 4
+)
+
+// chunkWriter escreve o corpo de uma resposta com Transfer-Encoding: chunked,
+// no máximo streamInFlight chunks pendentes, o que segura a leitura do banco
+// quando o cliente é lento.
+type chunkWriter struct {
+	asyncWriter
+	buf []byte
+}
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	w.buf = append(w.buf, p...)
+	if len(w.buf) >= streamChunkSize {
+		w.flush()
+	}
+	return len(p), w.err
+}
+
+func (w *chunkWriter) flush() {
+	if len(w.buf) == 0 || w.err != nil {
+		return
+	}
+
+	chunk := make([]byte, 0, len(w.buf)+16)
+	chunk = strconv.AppendInt(chunk, int64(len(w.buf)), 16)
+	chunk = append(chunk, "\r\n"...)
+	chunk = append(chunk, w.buf...)
+	chunk = append(chunk, "\r\n"...)
+	w.buf = w.buf[:0]
+
+	w.send(chunk)
+}
+
+// stream responde em segundo plano com o corpo produzido por produce. O loop
+// do gnet não fica bloqueado enquanto o banco é lido, e a conexão só volta a
+// ser lida depois do chunk final, então requisições em pipeline atrás desta
+// esperam a vez. PAYMENT_EXPORT_TIMEOUT limita quanto tempo a leitura (e a
+// conexão do pool) fica presa a um cliente lento. Se produce falhar depois do
+// início da resposta, a conexão é fechada sem o chunk final, para que o
+// cliente não tome o corpo truncado como completo.
+func (s *GNetServer) stream(c gnet.Conn, contentType string, keepAlive bool, produce func(ctx context.Context, w *chunkWriter) error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	if timeout := config.Env.Payment.ExportTimeout; timeout > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), timeout)
+	}
+
+	state := stateOf(c)
+	state.busy.Store(true)
+	state.cancel = cancel
+
+	go func() {
+		defer cancel()
+
+		w := &chunkWriter{asyncWriter: newAsyncWriter(c, ctx, cancel, streamInFlight), buf: make([]byte, 0, streamChunkSize)}
+
+		connHdr := "Connection: keep-alive\r\n"
+		if !keepAlive {
+			connHdr = "Connection: close\r\n"
+		}
+		w.send([]byte(fmt.Sprintf("HTTP/1.1 200 OK\r\nContent-Type: %s\r\nTransfer-Encoding: chunked\r\n%s\r\n", contentType, connHdr)))
+
+		err := produce(ctx, w)
+		w.flush()
+		if err == nil {
+			err = w.err
+		}
+
+		if err != nil {
+			// A conexão fecha com busy ligado: nada mais é lido dela.
+			println(fmt.Sprintf("Erro no streaming: %v", err))
+			w.close()
+			return
+		}
+
+		if w.send([]byte("0\r\n\r\n")); w.err != nil {
+			w.close()
+			return
+		}
+		release(c, state, keepAlive)
+	}()
+}